@@ -67,7 +67,16 @@ func (b *BaseApp) Start(ctx context.Context) error {
 	if b.svr == nil {
 		b.Logger().Info("no HTTP server registered, skipping")
 	} else {
-		go b.svr.Start(ctx)
+		// Listen before starting the server, so that bind errors fail the start.
+		l, err := b.svr.Listen()
+		if err != nil {
+			return err
+		}
+		go func() {
+			if svrErr := b.svr.StartWithListener(ctx, l); svrErr != nil {
+				b.Logger().Error("HTTP server errored", "err", svrErr)
+			}
+		}()
 	}
 
 	return nil
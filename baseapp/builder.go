@@ -49,14 +49,15 @@ func (ab *AppBuilder) RegisterHTTPServer(svr *server.Server) {
 	ab.svr = svr
 }
 
-// RegisterHTTPHandler registers a HTTP handler.
-func (ab *AppBuilder) RegisterHTTPHandler(handler *server.Handler) error {
+// RegisterHTTPHandler registers a HTTP handler, wrapped in the given route-specific middlewares.
+func (ab *AppBuilder) RegisterHTTPHandler(
+	handler *server.Handler, mw ...server.Middleware,
+) error {
 	if ab.svr == nil {
 		return errors.New("must enable the HTTP server to register a handler")
 	}
 
-	ab.svr.RegisterHandler(handler)
-	return nil
+	return ab.svr.RegisterHandler(handler, mw...)
 }
 
 // RegisterMiddleware registers a middleware to the HTTP server.
@@ -0,0 +1,77 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+var _ Client = (*CachingClient)(nil)
+
+// CacheConfig configures the caching of a CachingClient.
+type CacheConfig struct {
+	// optional, time to cache the latest header (e.g. for its base fee) for, 0 corresponds to not
+	// caching it; must be well under the block time for EIP-1559 fees to stay accurate
+	LatestHeaderTTL time.Duration
+}
+
+// CachingClient is a Client that serves repeated lookups of chain data locally. The chain ID,
+// which is immutable, is cached once fetched. The latest header, and so the latest base fee, is
+// optionally cached for a short TTL.
+type CachingClient struct {
+	Client
+	cfg CacheConfig
+	now func() time.Time
+
+	chainIDMu sync.Mutex
+	chainID   *big.Int
+
+	headerMu       sync.Mutex
+	latestHeader   *ethcoretypes.Header
+	latestHeaderAt time.Time
+}
+
+// NewCachingClient creates a CachingClient that wraps the given client.
+func NewCachingClient(client Client, cfg CacheConfig) *CachingClient {
+	return &CachingClient{Client: client, cfg: cfg, now: time.Now}
+}
+
+// ChainID returns the chain ID, which is only fetched on the first call (that succeeds).
+func (cc *CachingClient) ChainID(ctx context.Context) (*big.Int, error) {
+	cc.chainIDMu.Lock()
+	defer cc.chainIDMu.Unlock()
+
+	if cc.chainID == nil {
+		chainID, err := cc.Client.ChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cc.chainID = chainID
+	}
+	return new(big.Int).Set(cc.chainID), nil
+}
+
+// HeaderByNumber returns the header of the block with the given number. The latest header (i.e.
+// for a nil number) is served from the cache if it was fetched within the TTL.
+func (cc *CachingClient) HeaderByNumber(
+	ctx context.Context, number *big.Int,
+) (*ethcoretypes.Header, error) {
+	if number != nil || cc.cfg.LatestHeaderTTL <= 0 {
+		return cc.Client.HeaderByNumber(ctx, number)
+	}
+
+	cc.headerMu.Lock()
+	defer cc.headerMu.Unlock()
+
+	if cc.latestHeader == nil || cc.now().Sub(cc.latestHeaderAt) >= cc.cfg.LatestHeaderTTL {
+		header, err := cc.Client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		cc.latestHeader, cc.latestHeaderAt = header, cc.now()
+	}
+	return ethcoretypes.CopyHeader(cc.latestHeader), nil
+}
@@ -0,0 +1,91 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// headerClient is a Client that only implements fetching headers, counting the calls.
+type headerClient struct {
+	Client
+
+	mu      sync.Mutex
+	baseFee int64
+	calls   int
+}
+
+func (c *headerClient) HeaderByNumber(
+	_ context.Context, number *big.Int,
+) (*ethcoretypes.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	return &ethcoretypes.Header{Number: number, BaseFee: big.NewInt(c.baseFee)}, nil
+}
+
+func TestCachingClientChainID(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = &fakeClient{chainID: 80085}
+		cc     = NewCachingClient(client, CacheConfig{})
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chainID, err := cc.ChainID(ctx)
+			require.NoError(t, err)
+			require.EqualValues(t, 80085, chainID.Int64())
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 1, client.numCalls())
+
+	// Failed fetches are not cached.
+	client = &fakeClient{err: revertError{}}
+	cc = NewCachingClient(client, CacheConfig{})
+	for i := 1; i <= 2; i++ {
+		_, err := cc.ChainID(ctx)
+		require.Error(t, err)
+		require.Equal(t, i, client.numCalls())
+	}
+}
+
+func TestCachingClientLatestHeader(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = &headerClient{baseFee: 1}
+		cc     = NewCachingClient(client, CacheConfig{LatestHeaderTTL: time.Second})
+		now    = time.Unix(0, 0)
+	)
+	cc.now = func() time.Time { return now }
+
+	// The latest header is cached within the TTL.
+	for i := 0; i < 2; i++ {
+		header, err := cc.HeaderByNumber(ctx, nil)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, header.BaseFee.Int64())
+	}
+	require.Equal(t, 1, client.calls)
+
+	// Headers of specific blocks are not cached.
+	_, err := cc.HeaderByNumber(ctx, big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, 2, client.calls)
+
+	// The latest header is fetched again once the TTL has elapsed.
+	client.baseFee = 2
+	now = now.Add(time.Second)
+	header, err := cc.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, header.BaseFee.Int64())
+	require.Equal(t, 3, client.calls)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -43,9 +44,17 @@ type Reader interface {
 		ch chan<- ethcoretypes.Log) (ethereum.Subscription, error)
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int,
+		rewardPercentiles []float64) (*ethereum.FeeHistory, error)
 	TransactionByHash(ctx context.Context, hash common.Hash,
 	) (tx *ethcoretypes.Transaction, isPending bool, err error)
 
+	// CreateAccessList returns the (EIP-2930) access list of the call against the pending state
+	// and the gas it uses with the access list. Returns an ExecutionError if the call fails.
+	CreateAccessList(
+		ctx context.Context, msg ethereum.CallMsg,
+	) (*ethcoretypes.AccessList, uint64, error)
+
 	/*
 		TxPoolContentFrom returns the pending and queued transactions of this address.
 		Example response:
@@ -88,8 +97,15 @@ type Reader interface {
 	TxPoolInspect(
 		ctx context.Context,
 	) (map[string]map[common.Address]map[string]string, error)
+
+	// BatchCall sends all of the requests in a single batch request, setting the result or error
+	// of each request. The returned error is only set for failing to send the batch.
+	BatchCall(ctx context.Context, reqs []BatchElem) error
 }
 
+// BatchElem is a request of a batch call.
+type BatchElem = rpc.BatchElem
+
 type Writer interface {
 	SendTransaction(ctx context.Context, tx *ethcoretypes.Transaction) error
 }
@@ -118,17 +134,17 @@ func (c *ExtendedEthClient) DialContext(ctx context.Context, rawurl string) erro
 
 	var err error
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, c.rpcTimeout)
-	c.Client, err = ethclient.DialContext(ctxWithTimeout, rawurl)
+	c.Client, err = dialEthClient(ctxWithTimeout, rawurl, RetryConfig{})
 	cancel()
 	return err
 }
 
 // Close closes the client.
 func (c *ExtendedEthClient) Close() error {
-	if c == nil {
+	if c == nil || c.Client == nil {
 		return ErrClosed
 	}
-	c.Close()
+	c.Client.Close()
 	return nil
 }
 
@@ -204,3 +220,28 @@ func (c *ExtendedEthClient) TxPoolInspect(
 	}
 	return result, nil
 }
+
+// CreateAccessList returns the access list of the call against the pending state.
+func (c *ExtendedEthClient) CreateAccessList(
+	ctx context.Context, msg ethereum.CallMsg,
+) (*ethcoretypes.AccessList, uint64, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, c.rpcTimeout)
+	defer cancel()
+	accessList, gasUsed, vmErr, err := gethclient.New(c.Client.Client()).CreateAccessList(
+		ctxWithTimeout, msg,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	if vmErr != "" {
+		return nil, 0, &ExecutionError{Reason: vmErr}
+	}
+	return accessList, gasUsed, nil
+}
+
+// BatchCall sends all of the requests in a single batch request.
+func (c *ExtendedEthClient) BatchCall(ctx context.Context, reqs []BatchElem) error {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, c.rpcTimeout)
+	defer cancel()
+	return c.Client.Client().BatchCallContext(ctxWithTimeout, reqs)
+}
@@ -243,6 +243,19 @@ func (c *ChainProviderImpl) SuggestGasTipCap(ctx context.Context) (*big.Int, err
 	return nil, ErrClientNotFound
 }
 
+// FeeHistory returns the fee history of the blockCount blocks up to lastBlock, with the given
+// percentiles of the effective tips in each block.
+func (c *ChainProviderImpl) FeeHistory(
+	ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64,
+) (*ethereum.FeeHistory, error) {
+	if client, ok := c.GetHTTP(); ok {
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, c.rpcTimeout)
+		defer cancel()
+		return client.FeeHistory(ctxWithTimeout, blockCount, lastBlock, rewardPercentiles)
+	}
+	return nil, ErrClientNotFound
+}
+
 // TransactionByHash returns the transaction with the given hash.
 func (c *ChainProviderImpl) TransactionByHash(
 	ctx context.Context, hash common.Hash,
@@ -311,6 +324,28 @@ func (c *ChainProviderImpl) TxPoolInspect(ctx context.Context) (
 	return nil, ErrClientNotFound
 }
 
+// CreateAccessList returns the access list of the call against the pending state.
+func (c *ChainProviderImpl) CreateAccessList(
+	ctx context.Context, msg ethereum.CallMsg,
+) (*types.AccessList, uint64, error) {
+	if client, ok := c.GetHTTP(); ok {
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, c.rpcTimeout)
+		defer cancel()
+		return client.CreateAccessList(ctxWithTimeout, msg)
+	}
+	return nil, 0, ErrClientNotFound
+}
+
+// BatchCall sends all of the requests in a single batch request.
+func (c *ChainProviderImpl) BatchCall(ctx context.Context, reqs []BatchElem) error {
+	if client, ok := c.GetHTTP(); ok {
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, c.rpcTimeout)
+		defer cancel()
+		return client.BatchCall(ctxWithTimeout, reqs)
+	}
+	return ErrClientNotFound
+}
+
 func (c *ChainProviderImpl) Health() bool {
 	httpOk, wsOk := false, false
 	if client, ok := c.GetHTTP(); ok {
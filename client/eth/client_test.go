@@ -0,0 +1,115 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchCall(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests [][]json.RawMessage
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var batch []struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.Unmarshal(body, &batch))
+
+		mu.Lock()
+		requests = append(requests, make([]json.RawMessage, len(batch)))
+		mu.Unlock()
+
+		resp := make([]map[string]any, len(batch))
+		for i, req := range batch {
+			resp[i] = map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": hexutil.Uint64(i)}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	defer srv.Close()
+
+	ethClient, err := ethclient.Dial(srv.URL)
+	require.NoError(t, err)
+	client := NewExtendedEthClient(ethClient, time.Second)
+	defer ethClient.Close()
+
+	account := common.HexToAddress("0x1")
+	var nonce, gasPrice hexutil.Uint64
+	reqs := []BatchElem{
+		{Method: "eth_getTransactionCount", Args: []any{account, "pending"}, Result: &nonce},
+		{Method: "eth_gasPrice", Result: &gasPrice},
+	}
+	require.NoError(t, client.BatchCall(context.Background(), reqs))
+
+	// A single request carried both elements.
+	require.Len(t, requests, 1)
+	require.Len(t, requests[0], 2)
+	require.NoError(t, reqs[0].Error)
+	require.NoError(t, reqs[1].Error)
+	require.EqualValues(t, 0, nonce)
+	require.EqualValues(t, 1, gasPrice)
+}
+
+func TestCreateAccessList(t *testing.T) {
+	var vmErr string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "eth_createAccessList", req.Method)
+
+		result := map[string]any{
+			"accessList": []map[string]any{
+				{"address": common.HexToAddress("0x2"), "storageKeys": []string{}},
+			},
+			"gasUsed": "0x5208",
+		}
+		if vmErr != "" {
+			result["error"] = vmErr
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(
+			map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result},
+		))
+	}))
+	defer srv.Close()
+
+	ethClient, err := ethclient.Dial(srv.URL)
+	require.NoError(t, err)
+	client := NewExtendedEthClient(ethClient, time.Second)
+	defer ethClient.Close()
+
+	to := common.HexToAddress("0x1")
+	accessList, gasUsed, err := client.CreateAccessList(
+		context.Background(), ethereum.CallMsg{To: &to},
+	)
+	require.NoError(t, err)
+	require.Len(t, *accessList, 1)
+	require.Equal(t, common.HexToAddress("0x2"), (*accessList)[0].Address)
+	require.Equal(t, uint64(21000), gasUsed)
+
+	// A failing call is returned as an ExecutionError.
+	vmErr = "execution reverted"
+	_, _, err = client.CreateAccessList(context.Background(), ethereum.CallMsg{To: &to})
+	var execErr *ExecutionError
+	require.ErrorAs(t, err, &execErr)
+	require.Equal(t, vmErr, execErr.Reason)
+}
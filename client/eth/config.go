@@ -12,6 +12,9 @@ type ConnectionPoolConfig struct {
 	EthWSURLs           []string
 	DefaultTimeout      time.Duration
 	HealthCheckInterval time.Duration
+	// optional, retries of the requests to the HTTP URLs rejected with a 429 or 503 response (see
+	// RetryTransport), the zero value retries with the defaults of RetryConfig
+	HTTPRetry RetryConfig
 }
 
 func DefaultConnectPoolConfig() *ConnectionPoolConfig {
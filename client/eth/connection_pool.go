@@ -82,6 +82,7 @@ func (c *ConnectionPoolImpl) Dial(string) error {
 func (c *ConnectionPoolImpl) DialContext(ctx context.Context, _ string) error {
 	for _, url := range c.config.EthHTTPURLs {
 		client := NewHealthCheckedClient(c.config.HealthCheckInterval, c.logger)
+		client.httpRetry = c.config.HTTPRetry
 		if err := client.DialContext(ctx, url, c.config.DefaultTimeout); err != nil {
 			return err
 		}
@@ -1,8 +1,58 @@
 package eth
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
 
 var (
 	ErrAlreadyDial = errors.New("client is already dialed, please Close() before dialing again")
 	ErrClosed      = errors.New("client is already closed, please Dial() before closing again")
 )
+
+// ExecutionError is returned when a call executed by the node (e.g. to create an access list)
+// fails, such as by reverting.
+type ExecutionError struct {
+	Reason string
+}
+
+func (e *ExecutionError) Error() string {
+	return "execution failed: " + e.Reason
+}
+
+// IsConnectivityError returns true if the error is due to failing to reach the endpoint (e.g. a
+// refused connection, a timeout or an HTTP 5xx/429 response), rather than an error returned by
+// the node (e.g. an execution revert), so the call may succeed if retried or sent elsewhere.
+func IsConnectivityError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// Errors returned by the node over JSON-RPC are deterministic.
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError ||
+			httpErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, ErrClientNotFound) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, rpc.ErrClientQuit)
+}
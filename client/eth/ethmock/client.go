@@ -0,0 +1,664 @@
+// Package ethmock provides an in-memory eth.Client with programmable responses, for testing code
+// that uses an eth.Client without a live node.
+package ethmock
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/berachain/offchain-sdk/client/eth"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Names of the methods of the Client, used to queue errors and count calls.
+const (
+	MethodBlockByNumber       = "BlockByNumber"
+	MethodBlockReceipts       = "BlockReceipts"
+	MethodTransactionReceipt  = "TransactionReceipt"
+	MethodSubscribeNewHead    = "SubscribeNewHead"
+	MethodBlockNumber         = "BlockNumber"
+	MethodChainID             = "ChainID"
+	MethodBalanceAt           = "BalanceAt"
+	MethodCodeAt              = "CodeAt"
+	MethodCallContract        = "CallContract"
+	MethodEstimateGas         = "EstimateGas"
+	MethodFilterLogs          = "FilterLogs"
+	MethodHeaderByNumber      = "HeaderByNumber"
+	MethodPendingCodeAt       = "PendingCodeAt"
+	MethodPendingNonceAt      = "PendingNonceAt"
+	MethodNonceAt             = "NonceAt"
+	MethodSubscribeFilterLogs = "SubscribeFilterLogs"
+	MethodSuggestGasPrice     = "SuggestGasPrice"
+	MethodSuggestGasTipCap    = "SuggestGasTipCap"
+	MethodFeeHistory          = "FeeHistory"
+	MethodTransactionByHash   = "TransactionByHash"
+	MethodCreateAccessList    = "CreateAccessList"
+	MethodTxPoolContentFrom   = "TxPoolContentFrom"
+	MethodTxPoolInspect       = "TxPoolInspect"
+	MethodBatchCall           = "BatchCall"
+	MethodSendTransaction     = "SendTransaction"
+)
+
+// ErrBatchCallUnsupported is set as the error of each request of a batch call other than for
+// receipts, since the Client doesn't serve raw JSON-RPC requests.
+var ErrBatchCallUnsupported = errors.New("batch calls are not supported by the mock client")
+
+var _ eth.Client = (*Client)(nil)
+
+// Client is an in-memory eth.Client with programmable responses. Chain state (e.g. nonces,
+// receipts and gas prices) is set with the setters, errors are queued per method with QueueError,
+// and the calls made are recorded for assertions. It is safe for concurrent use.
+type Client struct {
+	mu sync.Mutex
+
+	// programmable responses
+	chainID     *big.Int
+	blockNumber uint64
+	baseFee     *big.Int
+	gasPrice    *big.Int
+	gasTipCap   *big.Int
+	gasEstimate uint64
+	feeHistory  *ethereum.FeeHistory
+	callResult  []byte
+	accessList  types.AccessList
+	healthy     bool
+	nonces      map[common.Address]uint64
+	balances    map[common.Address]*big.Int
+	codes       map[common.Address][]byte
+	receipts    map[common.Hash]*types.Receipt
+	dropped     map[common.Hash]struct{}
+	logs        []types.Log
+	errs        map[string][]error
+
+	// recorded calls
+	calls map[string]int
+	sent  []*types.Transaction
+
+	headFeed event.Feed
+	logFeed  event.Feed
+}
+
+// New creates a new Client on chain ID 1 at block 0, with a base fee and gas price of 1 gwei, a
+// gas tip of 1 gwei, and a gas estimate of 21000.
+func New() *Client {
+	return &Client{
+		chainID:     big.NewInt(1),
+		baseFee:     big.NewInt(params.GWei),
+		gasPrice:    big.NewInt(params.GWei),
+		gasTipCap:   big.NewInt(params.GWei),
+		gasEstimate: params.TxGas,
+		feeHistory:  &ethereum.FeeHistory{},
+		healthy:     true,
+		nonces:      make(map[common.Address]uint64),
+		balances:    make(map[common.Address]*big.Int),
+		codes:       make(map[common.Address][]byte),
+		receipts:    make(map[common.Hash]*types.Receipt),
+		dropped:     make(map[common.Hash]struct{}),
+		errs:        make(map[string][]error),
+		calls:       make(map[string]int),
+	}
+}
+
+// ==================================================================
+// Programming Responses
+// ==================================================================
+
+// QueueError queues errors to be returned, in order, by the next calls to the given method (one
+// of the Method constants). Once the queued errors are used up, the method responds normally.
+func (c *Client) QueueError(method string, errs ...error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs[method] = append(c.errs[method], errs...)
+}
+
+// SetChainID sets the chain ID.
+func (c *Client) SetChainID(chainID *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.chainID = chainID
+}
+
+// SetBlockNumber sets the latest block number.
+func (c *Client) SetBlockNumber(number uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blockNumber = number
+}
+
+// SetBaseFee sets the base fee of the latest header.
+func (c *Client) SetBaseFee(baseFee *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.baseFee = baseFee
+}
+
+// SetGasPrice sets the suggested gas price.
+func (c *Client) SetGasPrice(gasPrice *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gasPrice = gasPrice
+}
+
+// SetGasTipCap sets the suggested gas tip cap.
+func (c *Client) SetGasTipCap(gasTipCap *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gasTipCap = gasTipCap
+}
+
+// SetGasEstimate sets the gas estimate of all calls.
+func (c *Client) SetGasEstimate(gas uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gasEstimate = gas
+}
+
+// SetAccessList sets the access list created for all calls.
+func (c *Client) SetAccessList(accessList types.AccessList) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessList = accessList
+}
+
+// SetFeeHistory sets the fee history.
+func (c *Client) SetFeeHistory(feeHistory *ethereum.FeeHistory) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.feeHistory = feeHistory
+}
+
+// SetCallResult sets the result of all contract calls.
+func (c *Client) SetCallResult(result []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callResult = result
+}
+
+// SetHealthy sets whether the client reports itself as healthy.
+func (c *Client) SetHealthy(healthy bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.healthy = healthy
+}
+
+// SetNonce sets the nonce of the account, both pending and as of any block. Sending a tx from the
+// account increments its nonce past the tx's nonce.
+func (c *Client) SetNonce(account common.Address, nonce uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nonces[account] = nonce
+}
+
+// SetBalance sets the balance of the account.
+func (c *Client) SetBalance(account common.Address, balance *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.balances[account] = balance
+}
+
+// SetCode sets the code of the account.
+func (c *Client) SetCode(account common.Address, code []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.codes[account] = code
+}
+
+// SetReceipt sets the receipt of the tx with the receipt's TxHash, which marks the tx as mined.
+func (c *Client) SetReceipt(receipt *types.Receipt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.receipts[receipt.TxHash] = receipt
+}
+
+// RemoveReceipt removes the receipt of the tx, as if its block was reorged out. The tx is pending
+// again, unless it's dropped (see DropTransaction).
+func (c *Client) RemoveReceipt(txHash common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.receipts, txHash)
+}
+
+// DropTransaction drops the sent tx from the (mock) mempool, so it's no longer found by hash
+// unless it's mined.
+func (c *Client) DropTransaction(hash common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dropped[hash] = struct{}{}
+}
+
+// SetLogs sets the logs returned by FilterLogs.
+func (c *Client) SetLogs(logs []types.Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs = logs
+}
+
+// PublishHeader sends the header to all new head subscribers, blocking until they all receive it.
+func (c *Client) PublishHeader(header *types.Header) {
+	c.headFeed.Send(header)
+}
+
+// PublishLog sends the log to all log subscribers (regardless of their filter query), blocking
+// until they all receive it.
+func (c *Client) PublishLog(log types.Log) {
+	c.logFeed.Send(log)
+}
+
+// ==================================================================
+// Assertions
+// ==================================================================
+
+// Calls returns the number of calls made to the given method (one of the Method constants),
+// including calls that returned a queued error.
+func (c *Client) Calls(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+// SentTransactions returns the txs that were sent successfully, in order.
+func (c *Client) SentTransactions() []*types.Transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*types.Transaction(nil), c.sent...)
+}
+
+// call records a call to the method and returns the next error queued for it, if any. Requires
+// c.mu to be held.
+func (c *Client) call(method string) error {
+	c.calls[method]++
+	if errs := c.errs[method]; len(errs) > 0 {
+		c.errs[method] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+// ==================================================================
+// Client Lifecycle
+// ==================================================================
+
+// DialContext is a no-op.
+func (c *Client) DialContext(context.Context, string) error {
+	return nil
+}
+
+// Close is a no-op.
+func (c *Client) Close() error {
+	return nil
+}
+
+// Health returns whether the client is set as healthy (by default, true).
+func (c *Client) Health() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.healthy
+}
+
+// ==================================================================
+// Reader
+// ==================================================================
+
+// BlockByNumber returns an empty block with the header at the given number (or latest if nil).
+func (c *Client) BlockByNumber(_ context.Context, number *big.Int) (*types.Block, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodBlockByNumber); err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(c.header(number)), nil
+}
+
+// BlockReceipts returns the receipts set for the block with the given number.
+func (c *Client) BlockReceipts(
+	_ context.Context, blockNrOrHash rpc.BlockNumberOrHash,
+) ([]*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodBlockReceipts); err != nil {
+		return nil, err
+	}
+
+	number, ok := blockNrOrHash.Number()
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	var receipts []*types.Receipt
+	for _, receipt := range c.receipts {
+		if receipt.BlockNumber != nil && receipt.BlockNumber.Int64() == number.Int64() {
+			receipts = append(receipts, receipt)
+		}
+	}
+	return receipts, nil
+}
+
+// TransactionReceipt returns the receipt set for the tx, or ethereum.NotFound if there is none.
+func (c *Client) TransactionReceipt(
+	_ context.Context, txHash common.Hash,
+) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodTransactionReceipt); err != nil {
+		return nil, err
+	}
+	if receipt, ok := c.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+// SubscribeNewHead subscribes to the headers sent with PublishHeader.
+func (c *Client) SubscribeNewHead(
+	context.Context,
+) (chan *types.Header, ethereum.Subscription, error) {
+	c.mu.Lock()
+	err := c.call(MethodSubscribeNewHead)
+	c.mu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan *types.Header)
+	return ch, c.headFeed.Subscribe(ch), nil
+}
+
+// BlockNumber returns the latest block number.
+func (c *Client) BlockNumber(context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodBlockNumber); err != nil {
+		return 0, err
+	}
+	return c.blockNumber, nil
+}
+
+// ChainID returns the chain ID.
+func (c *Client) ChainID(context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodChainID); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(c.chainID), nil
+}
+
+// BalanceAt returns the balance set for the account (or 0 if none), regardless of the block.
+func (c *Client) BalanceAt(
+	_ context.Context, account common.Address, _ *big.Int,
+) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodBalanceAt); err != nil {
+		return nil, err
+	}
+	if balance, ok := c.balances[account]; ok {
+		return new(big.Int).Set(balance), nil
+	}
+	return new(big.Int), nil
+}
+
+// CodeAt returns the code set for the account.
+func (c *Client) CodeAt(_ context.Context, account common.Address, _ *big.Int) ([]byte, error) {
+	return c.codeAt(MethodCodeAt, account)
+}
+
+// CallContract returns the call result set for all calls.
+func (c *Client) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodCallContract); err != nil {
+		return nil, err
+	}
+	return c.callResult, nil
+}
+
+// EstimateGas returns the gas estimate set for all calls.
+func (c *Client) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodEstimateGas); err != nil {
+		return 0, err
+	}
+	return c.gasEstimate, nil
+}
+
+// FilterLogs returns the logs set with SetLogs, filtered by the query's addresses (if any).
+func (c *Client) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodFilterLogs); err != nil {
+		return nil, err
+	}
+
+	var logs []types.Log
+	for _, log := range c.logs {
+		if matchesAddresses(log.Address, q.Addresses) {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+// HeaderByNumber returns the header at the given number (or latest if nil), with the base fee.
+func (c *Client) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodHeaderByNumber); err != nil {
+		return nil, err
+	}
+	return c.header(number), nil
+}
+
+// PendingCodeAt returns the code set for the account.
+func (c *Client) PendingCodeAt(_ context.Context, account common.Address) ([]byte, error) {
+	return c.codeAt(MethodPendingCodeAt, account)
+}
+
+// PendingNonceAt returns the nonce of the account.
+func (c *Client) PendingNonceAt(_ context.Context, account common.Address) (uint64, error) {
+	return c.nonceAt(MethodPendingNonceAt, account)
+}
+
+// NonceAt returns the nonce of the account, regardless of the block number.
+func (c *Client) NonceAt(_ context.Context, account common.Address, _ *big.Int) (uint64, error) {
+	return c.nonceAt(MethodNonceAt, account)
+}
+
+// SubscribeFilterLogs subscribes to the logs sent with PublishLog. The query is not applied.
+func (c *Client) SubscribeFilterLogs(
+	_ context.Context, _ ethereum.FilterQuery, ch chan<- types.Log,
+) (ethereum.Subscription, error) {
+	c.mu.Lock()
+	err := c.call(MethodSubscribeFilterLogs)
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return c.logFeed.Subscribe(ch), nil
+}
+
+// SuggestGasPrice returns the gas price set.
+func (c *Client) SuggestGasPrice(context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodSuggestGasPrice); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(c.gasPrice), nil
+}
+
+// SuggestGasTipCap returns the gas tip cap set.
+func (c *Client) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodSuggestGasTipCap); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(c.gasTipCap), nil
+}
+
+// FeeHistory returns the fee history set, regardless of the arguments.
+func (c *Client) FeeHistory(
+	context.Context, uint64, *big.Int, []float64,
+) (*ethereum.FeeHistory, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodFeeHistory); err != nil {
+		return nil, err
+	}
+	return c.feeHistory, nil
+}
+
+// CreateAccessList returns the access list set with SetAccessList (empty by default), using the
+// gas estimate.
+func (c *Client) CreateAccessList(
+	context.Context, ethereum.CallMsg,
+) (*types.AccessList, uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodCreateAccessList); err != nil {
+		return nil, 0, err
+	}
+	accessList := append(types.AccessList{}, c.accessList...)
+	return &accessList, c.gasEstimate, nil
+}
+
+// TransactionByHash returns the sent tx with the given hash, which is pending until its receipt is
+// set, or ethereum.NotFound if it wasn't sent (or was dropped and isn't mined).
+func (c *Client) TransactionByHash(
+	_ context.Context, hash common.Hash,
+) (*types.Transaction, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodTransactionByHash); err != nil {
+		return nil, false, err
+	}
+	for _, tx := range c.sent {
+		if tx.Hash() == hash {
+			_, mined := c.receipts[hash]
+			if _, dropped := c.dropped[hash]; dropped && !mined {
+				break
+			}
+			return tx, !mined, nil
+		}
+	}
+	return nil, false, ethereum.NotFound
+}
+
+// TxPoolContentFrom returns an empty txpool.
+func (c *Client) TxPoolContentFrom(
+	context.Context, common.Address,
+) (map[string]map[string]*types.Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodTxPoolContentFrom); err != nil {
+		return nil, err
+	}
+	return map[string]map[string]*types.Transaction{"pending": {}, "queued": {}}, nil
+}
+
+// TxPoolInspect returns an empty txpool.
+func (c *Client) TxPoolInspect(
+	context.Context,
+) (map[string]map[common.Address]map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodTxPoolInspect); err != nil {
+		return nil, err
+	}
+	return map[string]map[common.Address]map[string]string{"pending": {}, "queued": {}}, nil
+}
+
+// BatchCall serves the requests of receipts (eth_getTransactionReceipt) with the receipts set,
+// and sets ErrBatchCallUnsupported as the error of any other request.
+func (c *Client) BatchCall(_ context.Context, reqs []eth.BatchElem) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodBatchCall); err != nil {
+		return err
+	}
+	for i := range reqs {
+		result, ok := reqs[i].Result.(**types.Receipt)
+		if reqs[i].Method != "eth_getTransactionReceipt" || !ok || len(reqs[i].Args) != 1 {
+			reqs[i].Error = ErrBatchCallUnsupported
+			continue
+		}
+		if hash, isHash := reqs[i].Args[0].(common.Hash); isHash {
+			*result = c.receipts[hash] // nil if not mined
+		}
+	}
+	return nil
+}
+
+// ==================================================================
+// Writer
+// ==================================================================
+
+// SendTransaction records the tx as sent. If the tx is signed, the nonce of its sender is
+// incremented past the tx's nonce.
+func (c *Client) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(MethodSendTransaction); err != nil {
+		return err
+	}
+
+	c.sent = append(c.sent, tx)
+	if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		c.nonces[from] = max(c.nonces[from], tx.Nonce()+1)
+	}
+	return nil
+}
+
+// ==================================================================
+// Helpers
+// ==================================================================
+
+// header returns the header at the given number (or latest if nil). Requires c.mu to be held.
+func (c *Client) header(number *big.Int) *types.Header {
+	if number == nil || number.Sign() < 0 {
+		number = new(big.Int).SetUint64(c.blockNumber)
+	}
+	return &types.Header{Number: new(big.Int).Set(number), BaseFee: new(big.Int).Set(c.baseFee)}
+}
+
+// codeAt returns the code set for the account for a call to the given method.
+func (c *Client) codeAt(method string, account common.Address) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(method); err != nil {
+		return nil, err
+	}
+	return c.codes[account], nil
+}
+
+// nonceAt returns the nonce of the account for a call to the given method.
+func (c *Client) nonceAt(method string, account common.Address) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call(method); err != nil {
+		return 0, err
+	}
+	return c.nonces[account], nil
+}
+
+// matchesAddresses returns true if the addresses are empty or include the address.
+func matchesAddresses(address common.Address, addresses []common.Address) bool {
+	if len(addresses) == 0 {
+		return true
+	}
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package ethmock_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/client/eth"
+	"github.com/berachain/offchain-sdk/client/eth/ethmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TestRetryDeterministically demonstrates testing retry behavior with queued errors.
+func TestRetryDeterministically(t *testing.T) {
+	client := ethmock.New()
+	client.SetBlockNumber(42)
+	client.QueueError(ethmock.MethodBlockNumber, syscall.ECONNREFUSED, syscall.ECONNRESET)
+
+	// The retry client retries both connectivity errors, then gets the block number.
+	rc := eth.NewRetryClient(client, eth.RetryConfig{Backoff: time.Millisecond})
+	number, err := rc.BlockNumber(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), number)
+	require.Equal(t, 3, client.Calls(ethmock.MethodBlockNumber))
+
+	// Errors that the node returned are not retried.
+	errReverted := errors.New("execution reverted")
+	client.QueueError(ethmock.MethodCallContract, errReverted)
+	_, err = rc.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	require.ErrorIs(t, err, errReverted)
+	require.Equal(t, 1, client.Calls(ethmock.MethodCallContract))
+}
+
+func TestSendTransaction(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = ethmock.New()
+		to     = common.HexToAddress("0x1")
+	)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	client.SetNonce(from, 5)
+
+	// Build and sign a tx with the programmed nonce and gas values.
+	nonce, err := client.PendingNonceAt(ctx, from)
+	require.NoError(t, err)
+	tip, err := client.SuggestGasTipCap(ctx)
+	require.NoError(t, err)
+	gas, err := client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to})
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)),
+		&types.DynamicFeeTx{
+			ChainID: big.NewInt(1), Nonce: nonce, GasTipCap: tip, GasFeeCap: tip, Gas: gas, To: &to,
+		},
+	)
+	require.NoError(t, err)
+
+	// A queued send error fails the send, without recording the tx.
+	errUnderpriced := errors.New("replacement transaction underpriced")
+	client.QueueError(ethmock.MethodSendTransaction, errUnderpriced)
+	require.ErrorIs(t, client.SendTransaction(ctx, tx), errUnderpriced)
+	require.Empty(t, client.SentTransactions())
+
+	// A successful send records the tx as pending and increments the sender's nonce.
+	require.NoError(t, client.SendTransaction(ctx, tx))
+	require.Equal(t, []*types.Transaction{tx}, client.SentTransactions())
+	require.Equal(t, 2, client.Calls(ethmock.MethodSendTransaction))
+	nonce, err = client.PendingNonceAt(ctx, from)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), nonce)
+	_, isPending, err := client.TransactionByHash(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.True(t, isPending)
+	_, err = client.TransactionReceipt(ctx, tx.Hash())
+	require.ErrorIs(t, err, ethereum.NotFound)
+
+	// A canned receipt mines the tx.
+	client.SetReceipt(&types.Receipt{
+		TxHash: tx.Hash(), Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(7),
+	})
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	_, isPending, err = client.TransactionByHash(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.False(t, isPending)
+
+	// Reorging out the tx's block and dropping it from the mempool loses the tx.
+	client.RemoveReceipt(tx.Hash())
+	client.DropTransaction(tx.Hash())
+	_, err = client.TransactionReceipt(ctx, tx.Hash())
+	require.ErrorIs(t, err, ethereum.NotFound)
+	_, _, err = client.TransactionByHash(ctx, tx.Hash())
+	require.ErrorIs(t, err, ethereum.NotFound)
+}
+
+func TestSubscribeNewHead(t *testing.T) {
+	client := ethmock.New()
+	ch, sub, err := client.SubscribeNewHead(context.Background())
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	go client.PublishHeader(&types.Header{Number: big.NewInt(1)})
+	require.Equal(t, big.NewInt(1), (<-ch).Number)
+}
@@ -0,0 +1,532 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var _ Client = (*FailoverClient)(nil)
+
+// FailoverClient is a Client over an ordered list of endpoints, which sends each call to the first
+// healthy endpoint and transparently fails over to the next one when a call fails to reach the
+// endpoint (see IsConnectivityError). Errors returned by a reachable endpoint (e.g. execution
+// reverts) are returned as is.
+//
+// An endpoint is considered down once a call fails to reach it, and is skipped until the probe
+// interval has elapsed, after which it is probed with its next call. Once dialed, the client also
+// probes the endpoints that are down in the background every probe interval, until it's closed.
+// Since endpoints are always tried in order, calls go back to the primary endpoint as soon as it
+// recovers.
+type FailoverClient struct {
+	endpoints     []*failoverEndpoint
+	probeInterval time.Duration
+	logger        log.Logger
+	now           func() time.Time
+
+	proberOnce sync.Once
+	stopProber chan struct{}
+	closeOnce  sync.Once
+}
+
+// failoverEndpoint is an endpoint of the FailoverClient.
+type failoverEndpoint struct {
+	Client
+	url string // dialed by the FailoverClient if set, else the Client is dialed by the caller
+
+	mu        sync.Mutex
+	dialed    bool
+	down      bool
+	downSince time.Time
+}
+
+// NewFailoverClient creates a FailoverClient over the given (already dialed) clients, in order of
+// preference. If probeInterval is 0, down endpoints are probed every 5s.
+func NewFailoverClient(
+	logger log.Logger, probeInterval time.Duration, clients ...Client,
+) *FailoverClient {
+	endpoints := make([]*failoverEndpoint, len(clients))
+	for i, client := range clients {
+		endpoints[i] = &failoverEndpoint{Client: client, dialed: true}
+	}
+	return newFailoverClient(logger, probeInterval, endpoints)
+}
+
+// NewFailoverClientFromURLs creates a FailoverClient over the endpoints at the given URLs, in
+// order of preference, with the given timeout for each RPC. The endpoints are dialed by
+// DialContext. If probeInterval is 0, down endpoints are probed every 5s.
+func NewFailoverClientFromURLs(
+	logger log.Logger, probeInterval, rpcTimeout time.Duration, urls ...string,
+) *FailoverClient {
+	endpoints := make([]*failoverEndpoint, len(urls))
+	for i, url := range urls {
+		endpoints[i] = &failoverEndpoint{Client: NewExtendedEthClient(nil, rpcTimeout), url: url}
+	}
+	return newFailoverClient(logger, probeInterval, endpoints)
+}
+
+func newFailoverClient(
+	logger log.Logger, probeInterval time.Duration, endpoints []*failoverEndpoint,
+) *FailoverClient {
+	if probeInterval == 0 {
+		probeInterval = defaultHealthCheckInterval
+	}
+	return &FailoverClient{
+		endpoints: endpoints, probeInterval: probeInterval, logger: logger, now: time.Now,
+		stopProber: make(chan struct{}),
+	}
+}
+
+// ==================================================================
+// Failover
+// ==================================================================
+
+// do runs the call on the endpoints in order, skipping the endpoints that are down (unless they
+// are due to be probed), until an endpoint is reached. If all endpoints are down, they are all
+// tried anyway.
+func (fc *FailoverClient) do(ctx context.Context, call func(Client) error) error {
+	candidates := fc.candidates()
+	if len(candidates) == 0 {
+		return ErrClientNotFound
+	}
+
+	var err error
+	for i, endpoint := range candidates {
+		if err = call(endpoint.Client); !IsConnectivityError(err) {
+			fc.markUp(endpoint)
+			return err
+		}
+		if ctx.Err() != nil {
+			return err // the caller gave up, rather than the endpoint
+		}
+
+		fc.markDown(endpoint)
+		if i < len(candidates)-1 {
+			fc.logger.Warn("eth endpoint unreachable, failing over", "err", err)
+		}
+	}
+	return err
+}
+
+// candidates returns the (dialed) endpoints to try, in order.
+func (fc *FailoverClient) candidates() []*failoverEndpoint {
+	var (
+		now        = fc.now()
+		dialed     = make([]*failoverEndpoint, 0, len(fc.endpoints))
+		candidates = make([]*failoverEndpoint, 0, len(fc.endpoints))
+	)
+	for _, endpoint := range fc.endpoints {
+		endpoint.mu.Lock()
+		isDialed := endpoint.dialed
+		skip := endpoint.down && now.Sub(endpoint.downSince) < fc.probeInterval
+		endpoint.mu.Unlock()
+		if !isDialed {
+			continue
+		}
+		dialed = append(dialed, endpoint)
+		if !skip {
+			candidates = append(candidates, endpoint)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return dialed
+	}
+	return candidates
+}
+
+// markUp records that the endpoint was reached.
+func (fc *FailoverClient) markUp(endpoint *failoverEndpoint) {
+	endpoint.mu.Lock()
+	defer endpoint.mu.Unlock()
+
+	if endpoint.down {
+		fc.logger.Info("eth endpoint recovered")
+	}
+	endpoint.down = false
+}
+
+// markDown records that the endpoint couldn't be reached, restarting its probe interval.
+func (fc *FailoverClient) markDown(endpoint *failoverEndpoint) {
+	endpoint.mu.Lock()
+	defer endpoint.mu.Unlock()
+
+	endpoint.down, endpoint.downSince = true, fc.now()
+}
+
+// failoverCall runs the call with failover, returning its result.
+func failoverCall[T any](
+	ctx context.Context, fc *FailoverClient, call func(Client) (T, error),
+) (T, error) {
+	var result T
+	err := fc.do(ctx, func(c Client) error {
+		var err error
+		result, err = call(c)
+		return err
+	})
+	return result, err
+}
+
+// ==================================================================
+// Client Lifecycle
+// ==================================================================
+
+// DialContext dials each endpoint given by URL with its own URL (the given URL is ignored), and
+// starts probing the endpoints that are down in the background. Returns an error only if none of
+// the endpoints could be dialed; the others are dialed again when probed.
+func (fc *FailoverClient) DialContext(ctx context.Context, _ string) error {
+	var errs []error
+	for _, endpoint := range fc.endpoints {
+		if err := fc.dial(ctx, endpoint); err != nil {
+			fc.markDown(endpoint)
+			errs = append(errs, err)
+		}
+	}
+	fc.proberOnce.Do(func() { go fc.probeLoop() })
+
+	if len(errs) == len(fc.endpoints) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// dial dials the endpoint with its URL, unless it's already dialed.
+func (fc *FailoverClient) dial(ctx context.Context, endpoint *failoverEndpoint) error {
+	endpoint.mu.Lock()
+	defer endpoint.mu.Unlock()
+
+	if endpoint.dialed {
+		return nil
+	}
+	if err := endpoint.Client.DialContext(ctx, endpoint.url); err != nil {
+		return err
+	}
+	endpoint.dialed = true
+	return nil
+}
+
+// probeLoop probes the endpoints that are down every probe interval, until the client is closed.
+func (fc *FailoverClient) probeLoop() {
+	ticker := time.NewTicker(fc.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-fc.stopProber:
+			return
+		case <-ticker.C:
+			fc.probe()
+		}
+	}
+}
+
+// probe dials (if necessary) and calls each endpoint that is down, marking it up if reached.
+func (fc *FailoverClient) probe() {
+	for _, endpoint := range fc.endpoints {
+		if !endpoint.isDown() {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), fc.probeInterval)
+		reached := fc.dial(ctx, endpoint) == nil
+		if reached {
+			_, err := endpoint.ChainID(ctx)
+			reached = !IsConnectivityError(err)
+		}
+		cancel()
+		if reached {
+			fc.markUp(endpoint)
+		} else {
+			fc.markDown(endpoint)
+		}
+	}
+}
+
+// isDown returns true if the endpoint is down.
+func (e *failoverEndpoint) isDown() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.down
+}
+
+// isDialed returns true if the endpoint is dialed.
+func (e *failoverEndpoint) isDialed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dialed
+}
+
+// Close stops probing the endpoints and closes all the dialed endpoints.
+func (fc *FailoverClient) Close() error {
+	fc.closeOnce.Do(func() { close(fc.stopProber) })
+
+	var errs []error
+	for _, endpoint := range fc.endpoints {
+		if endpoint.isDialed() {
+			errs = append(errs, endpoint.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Health returns true if any (dialed) endpoint is healthy.
+func (fc *FailoverClient) Health() bool {
+	for _, endpoint := range fc.endpoints {
+		if endpoint.isDialed() && endpoint.Health() {
+			return true
+		}
+	}
+	return false
+}
+
+// ==================================================================
+// Implementations of Reader and Writer
+// ==================================================================
+
+// BlockByNumber returns the block for the given number.
+func (fc *FailoverClient) BlockByNumber(
+	ctx context.Context, number *big.Int,
+) (*ethcoretypes.Block, error) {
+	return failoverCall(ctx, fc, func(c Client) (*ethcoretypes.Block, error) {
+		return c.BlockByNumber(ctx, number)
+	})
+}
+
+// BlockReceipts returns the receipts for the given block number or hash.
+func (fc *FailoverClient) BlockReceipts(
+	ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash,
+) ([]*ethcoretypes.Receipt, error) {
+	return failoverCall(ctx, fc, func(c Client) ([]*ethcoretypes.Receipt, error) {
+		return c.BlockReceipts(ctx, blockNrOrHash)
+	})
+}
+
+// TransactionReceipt returns the receipt for the given transaction hash.
+func (fc *FailoverClient) TransactionReceipt(
+	ctx context.Context, txHash common.Hash,
+) (*ethcoretypes.Receipt, error) {
+	return failoverCall(ctx, fc, func(c Client) (*ethcoretypes.Receipt, error) {
+		return c.TransactionReceipt(ctx, txHash)
+	})
+}
+
+// SubscribeNewHead subscribes to new head events.
+func (fc *FailoverClient) SubscribeNewHead(
+	ctx context.Context,
+) (chan *ethcoretypes.Header, ethereum.Subscription, error) {
+	var ch chan *ethcoretypes.Header
+	sub, err := failoverCall(ctx, fc, func(c Client) (ethereum.Subscription, error) {
+		var (
+			sub ethereum.Subscription
+			err error
+		)
+		ch, sub, err = c.SubscribeNewHead(ctx)
+		return sub, err
+	})
+	return ch, sub, err
+}
+
+// BlockNumber returns the current block number.
+func (fc *FailoverClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return failoverCall(ctx, fc, func(c Client) (uint64, error) {
+		return c.BlockNumber(ctx)
+	})
+}
+
+// ChainID returns the current chain ID.
+func (fc *FailoverClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return failoverCall(ctx, fc, func(c Client) (*big.Int, error) {
+		return c.ChainID(ctx)
+	})
+}
+
+// BalanceAt returns the balance of the given address at the given block number.
+func (fc *FailoverClient) BalanceAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int,
+) (*big.Int, error) {
+	return failoverCall(ctx, fc, func(c Client) (*big.Int, error) {
+		return c.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+// CodeAt returns the code of the given account at the given block number.
+func (fc *FailoverClient) CodeAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int,
+) ([]byte, error) {
+	return failoverCall(ctx, fc, func(c Client) ([]byte, error) {
+		return c.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+// CallContract calls a contract with the given message at the given block number.
+func (fc *FailoverClient) CallContract(
+	ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int,
+) ([]byte, error) {
+	return failoverCall(ctx, fc, func(c Client) ([]byte, error) {
+		return c.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+// EstimateGas estimates the gas needed to execute a specific transaction.
+func (fc *FailoverClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return failoverCall(ctx, fc, func(c Client) (uint64, error) {
+		return c.EstimateGas(ctx, msg)
+	})
+}
+
+// FilterLogs returns the logs that satisfy the given filter query.
+func (fc *FailoverClient) FilterLogs(
+	ctx context.Context, q ethereum.FilterQuery,
+) ([]ethcoretypes.Log, error) {
+	return failoverCall(ctx, fc, func(c Client) ([]ethcoretypes.Log, error) {
+		return c.FilterLogs(ctx, q)
+	})
+}
+
+// HeaderByNumber returns the header of the block with the given number.
+func (fc *FailoverClient) HeaderByNumber(
+	ctx context.Context, number *big.Int,
+) (*ethcoretypes.Header, error) {
+	return failoverCall(ctx, fc, func(c Client) (*ethcoretypes.Header, error) {
+		return c.HeaderByNumber(ctx, number)
+	})
+}
+
+// PendingCodeAt returns the code of the given account in the pending state.
+func (fc *FailoverClient) PendingCodeAt(
+	ctx context.Context, account common.Address,
+) ([]byte, error) {
+	return failoverCall(ctx, fc, func(c Client) ([]byte, error) {
+		return c.PendingCodeAt(ctx, account)
+	})
+}
+
+// PendingNonceAt returns the nonce of the given account in the pending state.
+func (fc *FailoverClient) PendingNonceAt(
+	ctx context.Context, account common.Address,
+) (uint64, error) {
+	return failoverCall(ctx, fc, func(c Client) (uint64, error) {
+		return c.PendingNonceAt(ctx, account)
+	})
+}
+
+// NonceAt returns the nonce of the given account at the given block number.
+func (fc *FailoverClient) NonceAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int,
+) (uint64, error) {
+	return failoverCall(ctx, fc, func(c Client) (uint64, error) {
+		return c.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+// SubscribeFilterLogs subscribes to new log events that satisfy the given filter query.
+func (fc *FailoverClient) SubscribeFilterLogs(
+	ctx context.Context, q ethereum.FilterQuery, ch chan<- ethcoretypes.Log,
+) (ethereum.Subscription, error) {
+	return failoverCall(ctx, fc, func(c Client) (ethereum.Subscription, error) {
+		return c.SubscribeFilterLogs(ctx, q, ch)
+	})
+}
+
+// SuggestGasPrice suggests a gas price.
+func (fc *FailoverClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return failoverCall(ctx, fc, func(c Client) (*big.Int, error) {
+		return c.SuggestGasPrice(ctx)
+	})
+}
+
+// SuggestGasTipCap suggests a gas tip cap.
+func (fc *FailoverClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return failoverCall(ctx, fc, func(c Client) (*big.Int, error) {
+		return c.SuggestGasTipCap(ctx)
+	})
+}
+
+// FeeHistory returns the fee history of the blockCount blocks up to lastBlock, with the given
+// percentiles of the effective tips in each block.
+func (fc *FailoverClient) FeeHistory(
+	ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64,
+) (*ethereum.FeeHistory, error) {
+	return failoverCall(ctx, fc, func(c Client) (*ethereum.FeeHistory, error) {
+		return c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (fc *FailoverClient) TransactionByHash(
+	ctx context.Context, hash common.Hash,
+) (*ethcoretypes.Transaction, bool, error) {
+	var isPending bool
+	tx, err := failoverCall(ctx, fc, func(c Client) (*ethcoretypes.Transaction, error) {
+		var (
+			tx  *ethcoretypes.Transaction
+			err error
+		)
+		tx, isPending, err = c.TransactionByHash(ctx, hash)
+		return tx, err
+	})
+	return tx, isPending, err
+}
+
+// CreateAccessList returns the access list of the call against the pending state.
+func (fc *FailoverClient) CreateAccessList(
+	ctx context.Context, msg ethereum.CallMsg,
+) (*ethcoretypes.AccessList, uint64, error) {
+	var gasUsed uint64
+	accessList, err := failoverCall(ctx, fc, func(c Client) (*ethcoretypes.AccessList, error) {
+		var (
+			accessList *ethcoretypes.AccessList
+			err        error
+		)
+		accessList, gasUsed, err = c.CreateAccessList(ctx, msg)
+		return accessList, err
+	})
+	return accessList, gasUsed, err
+}
+
+// TxPoolContentFrom returns the pending and queued transactions of this address.
+func (fc *FailoverClient) TxPoolContentFrom(
+	ctx context.Context, address common.Address,
+) (map[string]map[string]*ethcoretypes.Transaction, error) {
+	return failoverCall(ctx, fc, func(c Client) (
+		map[string]map[string]*ethcoretypes.Transaction, error,
+	) {
+		return c.TxPoolContentFrom(ctx, address)
+	})
+}
+
+// TxPoolInspect returns the textual summary of all pending and queued transactions.
+func (fc *FailoverClient) TxPoolInspect(
+	ctx context.Context,
+) (map[string]map[common.Address]map[string]string, error) {
+	return failoverCall(ctx, fc, func(c Client) (
+		map[string]map[common.Address]map[string]string, error,
+	) {
+		return c.TxPoolInspect(ctx)
+	})
+}
+
+// BatchCall sends all of the requests in a single batch request.
+func (fc *FailoverClient) BatchCall(ctx context.Context, reqs []BatchElem) error {
+	return fc.do(ctx, func(c Client) error {
+		return c.BatchCall(ctx, reqs)
+	})
+}
+
+// SendTransaction sends the given transaction. Since a transaction is identified by its hash,
+// sending it to another endpoint after failing to reach one is safe.
+func (fc *FailoverClient) SendTransaction(
+	ctx context.Context, tx *ethcoretypes.Transaction,
+) error {
+	return fc.do(ctx, func(c Client) error {
+		return c.SendTransaction(ctx, tx)
+	})
+}
@@ -0,0 +1,202 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// revertError is a JSON-RPC error returned by a node, e.g. for an execution revert.
+type revertError struct{}
+
+func (revertError) Error() string  { return "execution reverted" }
+func (revertError) ErrorCode() int { return 3 }
+
+// fakeClient is a Client that only implements fetching the chain ID, counting the calls.
+type fakeClient struct {
+	Client
+
+	mu      sync.Mutex
+	chainID int64
+	err     error
+	calls   int
+}
+
+func (c *fakeClient) ChainID(context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return big.NewInt(c.chainID), nil
+}
+
+func (*fakeClient) Close() error { return nil }
+
+func (c *fakeClient) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *fakeClient) numCalls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func TestFailoverClient(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		primary = &fakeClient{chainID: 1, err: syscall.ECONNREFUSED}
+		backup  = &fakeClient{chainID: 2}
+		fc      = NewFailoverClient(log.NewBlankLogger(io.Discard), time.Minute, primary, backup)
+		now     = time.Unix(0, 0)
+	)
+	fc.now = func() time.Time { return now }
+
+	// The primary is down, so calls route to the backup.
+	chainID, err := fc.ChainID(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, chainID.Int64())
+	require.Equal(t, 1, primary.numCalls())
+
+	// The primary is skipped until it is due to be probed.
+	chainID, err = fc.ChainID(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, chainID.Int64())
+	require.Equal(t, 1, primary.numCalls())
+
+	// Once the primary recovers and is probed, calls route to it again.
+	primary.setErr(nil)
+	now = now.Add(time.Minute)
+	chainID, err = fc.ChainID(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, chainID.Int64())
+	require.Equal(t, 2, backup.numCalls())
+}
+
+func TestFailoverClientErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// Errors returned by the node are not failed over.
+	primary, backup := &fakeClient{err: revertError{}}, &fakeClient{chainID: 2}
+	fc := NewFailoverClient(log.NewBlankLogger(io.Discard), time.Minute, primary, backup)
+	_, err := fc.ChainID(ctx)
+	require.ErrorIs(t, err, revertError{})
+	require.Zero(t, backup.numCalls())
+
+	// If all endpoints are down, the last error is returned, and they are all tried again.
+	primary.setErr(syscall.ECONNREFUSED)
+	backup.setErr(syscall.ECONNRESET)
+	for i := 1; i <= 2; i++ {
+		_, err = fc.ChainID(ctx)
+		require.ErrorIs(t, err, syscall.ECONNRESET)
+		require.Equal(t, 1+i, primary.numCalls())
+		require.Equal(t, i, backup.numCalls())
+	}
+}
+
+// chainIDServer is a JSON-RPC server answering eth_chainId with the given chain ID.
+func chainIDServer(t *testing.T, chainID int64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0", "id": req.ID, "result": fmt.Sprintf("%#x", chainID),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFailoverClientFromURLs(t *testing.T) {
+	ctx := context.Background()
+	primary, backup := chainIDServer(t, 1), chainIDServer(t, 2)
+	fc := NewFailoverClientFromURLs(
+		log.NewBlankLogger(io.Discard), time.Minute, time.Second, primary.URL, backup.URL,
+	)
+	defer fc.Close()
+
+	// Each endpoint is dialed with its own URL.
+	require.NoError(t, fc.DialContext(ctx, "http://unused"))
+	chainID, err := fc.ChainID(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, chainID.Int64())
+
+	primary.Close()
+	chainID, err = fc.ChainID(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, chainID.Int64())
+}
+
+func TestFailoverClientProber(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		primary = &fakeClient{chainID: 1, err: syscall.ECONNREFUSED}
+		backup  = &fakeClient{chainID: 2}
+		fc      = NewFailoverClient(
+			log.NewBlankLogger(io.Discard), 10*time.Millisecond, primary, backup,
+		)
+	)
+	now := time.Unix(0, 0)
+	fc.now = func() time.Time { return now } // calls never probe the primary
+
+	// The primary is down, so calls route to the backup.
+	require.NoError(t, fc.DialContext(ctx, ""))
+	chainID, err := fc.ChainID(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, chainID.Int64())
+
+	// The primary is probed in the background until it recovers, after which calls route to it.
+	require.Eventually(t, func() bool { return primary.numCalls() > 2 }, time.Second,
+		time.Millisecond)
+	primary.setErr(nil)
+	require.Eventually(t, func() bool { return !fc.endpoints[0].isDown() }, time.Second,
+		time.Millisecond)
+	chainID, err = fc.ChainID(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, chainID.Int64())
+
+	// Once closed, the endpoints are no longer probed.
+	primary.setErr(syscall.ECONNREFUSED)
+	_, err = fc.ChainID(ctx)
+	require.NoError(t, err)
+	require.NoError(t, fc.Close())
+	calls := primary.numCalls()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, calls, primary.numCalls())
+}
+
+func TestIsConnectivityError(t *testing.T) {
+	require.False(t, IsConnectivityError(nil))
+	require.False(t, IsConnectivityError(revertError{}))
+	require.False(t, IsConnectivityError(errors.New("nonce too low")))
+	require.True(t, IsConnectivityError(syscall.ECONNREFUSED))
+	require.True(t, IsConnectivityError(context.DeadlineExceeded))
+	require.True(t, IsConnectivityError(ErrClientNotFound))
+	require.True(t, IsConnectivityError(rpc.HTTPError{StatusCode: http.StatusBadGateway}))
+	require.False(t, IsConnectivityError(rpc.HTTPError{StatusCode: http.StatusBadRequest}))
+}
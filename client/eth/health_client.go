@@ -6,8 +6,6 @@ import (
 	"time"
 
 	"github.com/berachain/offchain-sdk/log"
-
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type HealthCheckedClient struct {
@@ -16,6 +14,7 @@ type HealthCheckedClient struct {
 	logger              log.Logger
 	healthy             bool
 	healthCheckInterval time.Duration
+	httpRetry           RetryConfig // of the RetryTransport HTTP(S) URLs are dialed with
 	mu                  sync.Mutex
 }
 
@@ -33,7 +32,7 @@ func (c *HealthCheckedClient) DialContext(
 ) error {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, c.healthCheckInterval)
 	defer cancel()
-	ethClient, err := ethclient.DialContext(ctxWithTimeout, rawurl)
+	ethClient, err := dialEthClient(ctxWithTimeout, rawurl, c.httpRetry)
 	if err != nil {
 		return err
 	}
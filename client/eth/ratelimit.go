@@ -0,0 +1,349 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrRateLimited is returned by a fail fast RateLimitedClient when the rate limit is exceeded.
+var ErrRateLimited = errors.New("eth client rate limit exceeded")
+
+var _ Client = (*RateLimitedClient)(nil)
+
+// RateLimitConfig configures the rate limit of a RateLimitedClient.
+type RateLimitConfig struct {
+	// average number of calls per second
+	RPS float64
+	// optional, max number of calls in a burst, 0 corresponds to 1
+	Burst int
+	// optional, fails calls over the rate limit with ErrRateLimited instead of waiting
+	FailFast bool
+}
+
+// RateLimitedClient is a Client that paces all calls to the node with a token bucket, e.g. to
+// stay within the quota of an RPC provider. Calls over the rate limit wait for their turn (or
+// until their context is done), or fail with ErrRateLimited if FailFast is set.
+type RateLimitedClient struct {
+	Client
+	failFast bool
+
+	mu     sync.Mutex
+	rps    float64
+	burst  float64
+	tokens float64 // negative if calls are waiting for tokens
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewRateLimitedClient creates a RateLimitedClient that wraps the given client.
+func NewRateLimitedClient(client Client, cfg RateLimitConfig) *RateLimitedClient {
+	burst := float64(max(cfg.Burst, 1))
+	return &RateLimitedClient{
+		Client:   client,
+		failFast: cfg.FailFast,
+		rps:      cfg.RPS,
+		burst:    burst,
+		tokens:   burst,
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// wait waits until the call may be made under the rate limit, or the context is done.
+func (rl *RateLimitedClient) wait(ctx context.Context) error {
+	delay, err := rl.reserve()
+	if err != nil || delay == 0 {
+		return err
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		rl.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token from the bucket, returning the time until the token is available. If
+// failing fast, returns ErrRateLimited instead of taking a token that isn't available yet.
+func (rl *RateLimitedClient) reserve() (time.Duration, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// Refill the bucket for the time elapsed since it was last used.
+	now := rl.now()
+	rl.tokens = min(rl.burst, rl.tokens+now.Sub(rl.last).Seconds()*rl.rps)
+	rl.last = now
+
+	if rl.tokens >= 1 {
+		rl.tokens--
+		return 0, nil
+	}
+	if rl.failFast || rl.rps <= 0 {
+		return 0, ErrRateLimited
+	}
+	rl.tokens--
+	return time.Duration(-rl.tokens / rl.rps * float64(time.Second)), nil
+}
+
+// cancel returns a token reserved by a call that gave up waiting.
+func (rl *RateLimitedClient) cancel() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.tokens = min(rl.burst, rl.tokens+1)
+}
+
+// rateLimitedCall runs the call once it may be made under the rate limit.
+func rateLimitedCall[T any](
+	ctx context.Context, rl *RateLimitedClient, call func() (T, error),
+) (T, error) {
+	if err := rl.wait(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	return call()
+}
+
+// ==================================================================
+// Implementations of Reader and Writer
+// ==================================================================
+
+// BlockByNumber returns the block for the given number.
+func (rl *RateLimitedClient) BlockByNumber(
+	ctx context.Context, number *big.Int,
+) (*ethcoretypes.Block, error) {
+	return rateLimitedCall(ctx, rl, func() (*ethcoretypes.Block, error) {
+		return rl.Client.BlockByNumber(ctx, number)
+	})
+}
+
+// BlockReceipts returns the receipts for the given block number or hash.
+func (rl *RateLimitedClient) BlockReceipts(
+	ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash,
+) ([]*ethcoretypes.Receipt, error) {
+	return rateLimitedCall(ctx, rl, func() ([]*ethcoretypes.Receipt, error) {
+		return rl.Client.BlockReceipts(ctx, blockNrOrHash)
+	})
+}
+
+// TransactionReceipt returns the receipt for the given transaction hash.
+func (rl *RateLimitedClient) TransactionReceipt(
+	ctx context.Context, txHash common.Hash,
+) (*ethcoretypes.Receipt, error) {
+	return rateLimitedCall(ctx, rl, func() (*ethcoretypes.Receipt, error) {
+		return rl.Client.TransactionReceipt(ctx, txHash)
+	})
+}
+
+// SubscribeNewHead subscribes to new head events.
+func (rl *RateLimitedClient) SubscribeNewHead(
+	ctx context.Context,
+) (chan *ethcoretypes.Header, ethereum.Subscription, error) {
+	if err := rl.wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	return rl.Client.SubscribeNewHead(ctx)
+}
+
+// BlockNumber returns the current block number.
+func (rl *RateLimitedClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return rateLimitedCall(ctx, rl, func() (uint64, error) {
+		return rl.Client.BlockNumber(ctx)
+	})
+}
+
+// ChainID returns the current chain ID.
+func (rl *RateLimitedClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return rateLimitedCall(ctx, rl, func() (*big.Int, error) {
+		return rl.Client.ChainID(ctx)
+	})
+}
+
+// BalanceAt returns the balance of the given address at the given block number.
+func (rl *RateLimitedClient) BalanceAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int,
+) (*big.Int, error) {
+	return rateLimitedCall(ctx, rl, func() (*big.Int, error) {
+		return rl.Client.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+// CodeAt returns the code of the given account at the given block number.
+func (rl *RateLimitedClient) CodeAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int,
+) ([]byte, error) {
+	return rateLimitedCall(ctx, rl, func() ([]byte, error) {
+		return rl.Client.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+// CallContract calls a contract with the given message at the given block number.
+func (rl *RateLimitedClient) CallContract(
+	ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int,
+) ([]byte, error) {
+	return rateLimitedCall(ctx, rl, func() ([]byte, error) {
+		return rl.Client.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+// EstimateGas estimates the gas needed to execute a specific transaction.
+func (rl *RateLimitedClient) EstimateGas(
+	ctx context.Context, msg ethereum.CallMsg,
+) (uint64, error) {
+	return rateLimitedCall(ctx, rl, func() (uint64, error) {
+		return rl.Client.EstimateGas(ctx, msg)
+	})
+}
+
+// FilterLogs returns the logs that satisfy the given filter query.
+func (rl *RateLimitedClient) FilterLogs(
+	ctx context.Context, q ethereum.FilterQuery,
+) ([]ethcoretypes.Log, error) {
+	return rateLimitedCall(ctx, rl, func() ([]ethcoretypes.Log, error) {
+		return rl.Client.FilterLogs(ctx, q)
+	})
+}
+
+// HeaderByNumber returns the header of the block with the given number.
+func (rl *RateLimitedClient) HeaderByNumber(
+	ctx context.Context, number *big.Int,
+) (*ethcoretypes.Header, error) {
+	return rateLimitedCall(ctx, rl, func() (*ethcoretypes.Header, error) {
+		return rl.Client.HeaderByNumber(ctx, number)
+	})
+}
+
+// PendingCodeAt returns the code of the given account in the pending state.
+func (rl *RateLimitedClient) PendingCodeAt(
+	ctx context.Context, account common.Address,
+) ([]byte, error) {
+	return rateLimitedCall(ctx, rl, func() ([]byte, error) {
+		return rl.Client.PendingCodeAt(ctx, account)
+	})
+}
+
+// PendingNonceAt returns the nonce of the given account in the pending state.
+func (rl *RateLimitedClient) PendingNonceAt(
+	ctx context.Context, account common.Address,
+) (uint64, error) {
+	return rateLimitedCall(ctx, rl, func() (uint64, error) {
+		return rl.Client.PendingNonceAt(ctx, account)
+	})
+}
+
+// NonceAt returns the nonce of the given account at the given block number.
+func (rl *RateLimitedClient) NonceAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int,
+) (uint64, error) {
+	return rateLimitedCall(ctx, rl, func() (uint64, error) {
+		return rl.Client.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+// SubscribeFilterLogs subscribes to new log events that satisfy the given filter query.
+func (rl *RateLimitedClient) SubscribeFilterLogs(
+	ctx context.Context, q ethereum.FilterQuery, ch chan<- ethcoretypes.Log,
+) (ethereum.Subscription, error) {
+	return rateLimitedCall(ctx, rl, func() (ethereum.Subscription, error) {
+		return rl.Client.SubscribeFilterLogs(ctx, q, ch)
+	})
+}
+
+// SuggestGasPrice suggests a gas price.
+func (rl *RateLimitedClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return rateLimitedCall(ctx, rl, func() (*big.Int, error) {
+		return rl.Client.SuggestGasPrice(ctx)
+	})
+}
+
+// SuggestGasTipCap suggests a gas tip cap.
+func (rl *RateLimitedClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return rateLimitedCall(ctx, rl, func() (*big.Int, error) {
+		return rl.Client.SuggestGasTipCap(ctx)
+	})
+}
+
+// FeeHistory returns the fee history of the blockCount blocks up to lastBlock, with the given
+// percentiles of the effective tips in each block.
+func (rl *RateLimitedClient) FeeHistory(
+	ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64,
+) (*ethereum.FeeHistory, error) {
+	return rateLimitedCall(ctx, rl, func() (*ethereum.FeeHistory, error) {
+		return rl.Client.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (rl *RateLimitedClient) TransactionByHash(
+	ctx context.Context, hash common.Hash,
+) (*ethcoretypes.Transaction, bool, error) {
+	if err := rl.wait(ctx); err != nil {
+		return nil, false, err
+	}
+	return rl.Client.TransactionByHash(ctx, hash)
+}
+
+// CreateAccessList returns the access list of the call against the pending state.
+func (rl *RateLimitedClient) CreateAccessList(
+	ctx context.Context, msg ethereum.CallMsg,
+) (*ethcoretypes.AccessList, uint64, error) {
+	if err := rl.wait(ctx); err != nil {
+		return nil, 0, err
+	}
+	return rl.Client.CreateAccessList(ctx, msg)
+}
+
+// TxPoolContentFrom returns the pending and queued transactions of this address.
+func (rl *RateLimitedClient) TxPoolContentFrom(
+	ctx context.Context, address common.Address,
+) (map[string]map[string]*ethcoretypes.Transaction, error) {
+	return rateLimitedCall(ctx, rl, func() (
+		map[string]map[string]*ethcoretypes.Transaction, error,
+	) {
+		return rl.Client.TxPoolContentFrom(ctx, address)
+	})
+}
+
+// TxPoolInspect returns the textual summary of all pending and queued transactions.
+func (rl *RateLimitedClient) TxPoolInspect(
+	ctx context.Context,
+) (map[string]map[common.Address]map[string]string, error) {
+	return rateLimitedCall(ctx, rl, func() (
+		map[string]map[common.Address]map[string]string, error,
+	) {
+		return rl.Client.TxPoolInspect(ctx)
+	})
+}
+
+// BatchCall sends all of the requests in a single batch request, which counts as a call per
+// request towards the rate limit.
+func (rl *RateLimitedClient) BatchCall(ctx context.Context, reqs []BatchElem) error {
+	for range reqs {
+		if err := rl.wait(ctx); err != nil {
+			return err
+		}
+	}
+	return rl.Client.BatchCall(ctx, reqs)
+}
+
+// SendTransaction sends the given transaction.
+func (rl *RateLimitedClient) SendTransaction(
+	ctx context.Context, tx *ethcoretypes.Transaction,
+) error {
+	if err := rl.wait(ctx); err != nil {
+		return err
+	}
+	return rl.Client.SendTransaction(ctx, tx)
+}
@@ -0,0 +1,66 @@
+package eth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitedClientPacing(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = &fakeClient{chainID: 1}
+		rl     = NewRateLimitedClient(client, RateLimitConfig{RPS: 50, Burst: 2})
+	)
+
+	// The burst is immediate, then calls are paced at the rate.
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		_, err := rl.ChainID(ctx)
+		require.NoError(t, err)
+	}
+	elapsed := time.Since(start)
+	require.GreaterOrEqual(t, elapsed, 90*time.Millisecond) // 5 calls at 50 rps
+	require.Less(t, elapsed, time.Second)
+	require.Equal(t, 7, client.numCalls())
+}
+
+func TestRateLimitedClientFailFast(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = &fakeClient{chainID: 1}
+		rl     = NewRateLimitedClient(client, RateLimitConfig{RPS: 1, Burst: 1, FailFast: true})
+		now    = time.Unix(0, 0)
+	)
+	rl.now, rl.last = func() time.Time { return now }, now
+
+	_, err := rl.ChainID(ctx)
+	require.NoError(t, err)
+	_, err = rl.ChainID(ctx)
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.Equal(t, 1, client.numCalls())
+
+	// A token is available once the bucket refills.
+	now = now.Add(time.Second)
+	_, err = rl.ChainID(ctx)
+	require.NoError(t, err)
+}
+
+func TestRateLimitedClientContextDone(t *testing.T) {
+	var (
+		client = &fakeClient{chainID: 1}
+		rl     = NewRateLimitedClient(client, RateLimitConfig{RPS: 0.001, Burst: 1})
+	)
+	_, err := rl.ChainID(context.Background())
+	require.NoError(t, err)
+
+	// Waiting for a token is abandoned once the context is done, returning the token.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = rl.ChainID(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, client.numCalls())
+	require.InDelta(t, 0, rl.tokens, 0.01)
+}
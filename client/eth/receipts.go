@@ -0,0 +1,36 @@
+package eth
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// BatchReceipts returns the receipts of the txs with the given hashes, fetched in a single batch
+// request, aligned by index with the hashes. The receipts of txs that are not mined (yet) are nil.
+// Returns an error if the batch can't be sent, or if fetching any receipt fails.
+func BatchReceipts(
+	ctx context.Context, client Reader, hashes []common.Hash,
+) ([]*ethcoretypes.Receipt, error) {
+	receipts := make([]*ethcoretypes.Receipt, len(hashes))
+	if len(hashes) == 0 {
+		return receipts, nil
+	}
+
+	reqs := make([]BatchElem, len(hashes))
+	for i, hash := range hashes {
+		reqs[i] = BatchElem{
+			Method: "eth_getTransactionReceipt", Args: []any{hash}, Result: &receipts[i],
+		}
+	}
+	if err := client.BatchCall(ctx, reqs); err != nil {
+		return nil, err
+	}
+	for _, req := range reqs {
+		if req.Error != nil {
+			return nil, req.Error
+		}
+	}
+	return receipts, nil
+}
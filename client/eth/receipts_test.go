@@ -0,0 +1,82 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// receiptsServer is a JSON-RPC server answering batches of eth_getTransactionReceipt with the
+// given receipts, or null for the other hashes, counting the HTTP requests it gets.
+func receiptsServer(
+	t *testing.T, requests *atomic.Int32, receipts map[common.Hash]*ethcoretypes.Receipt,
+) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var batch []struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []common.Hash   `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resps := make([]map[string]any, len(batch))
+		for i, req := range batch {
+			require.Equal(t, "eth_getTransactionReceipt", req.Method)
+			resps[i] = map[string]any{
+				"jsonrpc": "2.0", "id": req.ID, "result": receipts[req.Params[0]],
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resps)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestBatchReceipts(t *testing.T) {
+	ctx := context.Background()
+	mined := common.HexToHash("0x1")
+	receipt := &ethcoretypes.Receipt{
+		TxHash: mined, Status: ethcoretypes.ReceiptStatusSuccessful, Logs: []*ethcoretypes.Log{},
+	}
+	var requests atomic.Int32
+	srv := receiptsServer(t, &requests, map[common.Hash]*ethcoretypes.Receipt{mined: receipt})
+
+	rpcClient, err := rpc.DialContext(ctx, srv.URL)
+	require.NoError(t, err)
+	defer rpcClient.Close()
+	client := NewExtendedEthClient(ethclient.NewClient(rpcClient), time.Second)
+
+	// One batch request covers all the hashes, with nil receipts for the unmined txs.
+	hashes := []common.Hash{common.HexToHash("0x2"), mined, common.HexToHash("0x3")}
+	receipts, err := BatchReceipts(ctx, client, hashes)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), requests.Load())
+	require.Len(t, receipts, len(hashes))
+	require.Nil(t, receipts[0])
+	require.Equal(t, mined, receipts[1].TxHash)
+	require.Equal(t, ethcoretypes.ReceiptStatusSuccessful, receipts[1].Status)
+	require.Nil(t, receipts[2])
+
+	// No request is sent without hashes.
+	receipts, err = BatchReceipts(ctx, client, nil)
+	require.NoError(t, err)
+	require.Empty(t, receipts)
+	require.Equal(t, int32(1), requests.Load())
+}
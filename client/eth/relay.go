@@ -0,0 +1,53 @@
+package eth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// sendPrivateTransactionMethod is the JSON-RPC method of private tx relays (e.g. Flashbots
+// Protect) for sending a tx without it entering the public mempool.
+const sendPrivateTransactionMethod = "eth_sendPrivateTransaction"
+
+// PrivateRelay is a client of a private tx relay, which forwards txs directly to block builders
+// rather than broadcasting them to the public mempool, protecting them from front-running.
+type PrivateRelay struct {
+	client *rpc.Client
+}
+
+// privateTxArgs are the arguments of eth_sendPrivateTransaction.
+type privateTxArgs struct {
+	Tx hexutil.Bytes `json:"tx"`
+}
+
+// DialPrivateRelay connects to the private tx relay at the given URL, sending the given headers
+// (e.g. for auth) with each request.
+func DialPrivateRelay(
+	ctx context.Context, url string, headers http.Header,
+) (*PrivateRelay, error) {
+	client, err := rpc.DialOptions(ctx, url, rpc.WithHeaders(headers))
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateRelay{client: client}, nil
+}
+
+// SendPrivateTransaction sends the signed tx to the relay.
+func (r *PrivateRelay) SendPrivateTransaction(
+	ctx context.Context, tx *ethcoretypes.Transaction,
+) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return r.client.CallContext(ctx, nil, sendPrivateTransactionMethod, privateTxArgs{Tx: raw})
+}
+
+// Close closes the connection to the relay.
+func (r *PrivateRelay) Close() {
+	r.client.Close()
+}
@@ -0,0 +1,59 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateRelay(t *testing.T) {
+	var (
+		method string
+		args   []privateTxArgs
+		auth   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		method, auth = req.Method, r.Header.Get("X-Auth")
+		require.NoError(t, json.Unmarshal(req.Params, &args))
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0", "id": req.ID, "result": common.Hash{1},
+		}))
+	}))
+	defer srv.Close()
+
+	relay, err := DialPrivateRelay(
+		context.Background(), srv.URL, http.Header{"X-Auth": []string{"secret"}},
+	)
+	require.NoError(t, err)
+	defer relay.Close()
+
+	to := common.HexToAddress("0x1")
+	tx := ethcoretypes.NewTx(&ethcoretypes.DynamicFeeTx{
+		ChainID: big.NewInt(1), Nonce: 3, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2),
+		Gas: 21000, To: &to,
+	})
+	require.NoError(t, relay.SendPrivateTransaction(context.Background(), tx))
+
+	// The relay received the raw tx through its private endpoint.
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, sendPrivateTransactionMethod, method)
+	require.Equal(t, []privateTxArgs{{Tx: hexutil.Bytes(raw)}}, args)
+	require.Equal(t, "secret", auth)
+}
@@ -0,0 +1,304 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	defaultRetryMaxRetries = 3
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 2 * time.Second
+	defaultMaxRetryAfter   = 10 * time.Second
+)
+
+var _ Client = (*RetryClient)(nil)
+
+// RetryConfig configures the retries of a RetryClient.
+type RetryConfig struct {
+	// optional, max number of retries of a call, 0 corresponds to 3 and a negative value to none
+	MaxRetries int
+	// optional, backoff before the first retry, which doubles with each retry; 0 corresponds to
+	// 100ms
+	Backoff time.Duration
+	// optional, max backoff between retries, 0 corresponds to 2s
+	MaxBackoff time.Duration
+	// optional, max delay of a Retry-After header that is waited for by a RetryTransport (longer
+	// delays fail the request instead), 0 corresponds to 10s
+	MaxRetryAfter time.Duration
+}
+
+// withDefaults returns the config with the defaults of the unset fields.
+func (cfg RetryConfig) withDefaults() RetryConfig {
+	switch {
+	case cfg.MaxRetries == 0:
+		cfg.MaxRetries = defaultRetryMaxRetries
+	case cfg.MaxRetries < 0:
+		cfg.MaxRetries = 0
+	}
+	if cfg.Backoff == 0 {
+		cfg.Backoff = defaultRetryBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if cfg.MaxRetryAfter == 0 {
+		cfg.MaxRetryAfter = defaultMaxRetryAfter
+	}
+	return cfg
+}
+
+// RetryClient is a Client that retries read calls that fail to reach the node (see
+// IsConnectivityError), with exponential backoff. Errors returned by the node (e.g. execution
+// reverts) are deterministic, so they are not retried. Sending transactions, subscriptions and
+// batch calls (which may send transactions) are not retried by the RetryClient itself. Calls
+// rejected at the HTTP level with a 429 or 503 response (including sending transactions, which
+// the node didn't process) are retried, respecting the Retry-After header, by the RetryTransport
+// that HTTP(S) URLs are dialed with by this package (e.g. by DialRetryClient, the
+// ConnectionPool and ExtendedEthClient.DialContext).
+type RetryClient struct {
+	Client
+	cfg RetryConfig
+}
+
+// NewRetryClient creates a RetryClient that wraps the given client.
+func NewRetryClient(client Client, cfg RetryConfig) *RetryClient {
+	return &RetryClient{Client: client, cfg: cfg.withDefaults()}
+}
+
+// DialRetryClient dials the node at the URL and returns a RetryClient over it, with the given
+// timeout for each RPC. HTTP(S) URLs are dialed with a RetryTransport, both with the given config.
+func DialRetryClient(
+	ctx context.Context, rawurl string, cfg RetryConfig, rpcTimeout time.Duration,
+) (*RetryClient, error) {
+	ethClient, err := dialEthClient(ctx, rawurl, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewRetryClient(NewExtendedEthClient(ethClient, rpcTimeout), cfg), nil
+}
+
+// retryCall runs the call, retrying it with backoff while it fails to reach the node. Returns the
+// last error if the retries are exhausted or the context is done.
+func retryCall[T any](ctx context.Context, rc *RetryClient, call func() (T, error)) (T, error) {
+	backoff := rc.cfg.Backoff
+	for retry := 0; ; retry++ {
+		result, err := call()
+		if !IsConnectivityError(err) || retry == rc.cfg.MaxRetries {
+			return result, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, rc.cfg.MaxBackoff) //nolint:gomnd // doubles.
+	}
+}
+
+// ==================================================================
+// Implementations of Reader
+// ==================================================================
+
+// BlockByNumber returns the block for the given number.
+func (rc *RetryClient) BlockByNumber(
+	ctx context.Context, number *big.Int,
+) (*ethcoretypes.Block, error) {
+	return retryCall(ctx, rc, func() (*ethcoretypes.Block, error) {
+		return rc.Client.BlockByNumber(ctx, number)
+	})
+}
+
+// BlockReceipts returns the receipts for the given block number or hash.
+func (rc *RetryClient) BlockReceipts(
+	ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash,
+) ([]*ethcoretypes.Receipt, error) {
+	return retryCall(ctx, rc, func() ([]*ethcoretypes.Receipt, error) {
+		return rc.Client.BlockReceipts(ctx, blockNrOrHash)
+	})
+}
+
+// TransactionReceipt returns the receipt for the given transaction hash.
+func (rc *RetryClient) TransactionReceipt(
+	ctx context.Context, txHash common.Hash,
+) (*ethcoretypes.Receipt, error) {
+	return retryCall(ctx, rc, func() (*ethcoretypes.Receipt, error) {
+		return rc.Client.TransactionReceipt(ctx, txHash)
+	})
+}
+
+// BlockNumber returns the current block number.
+func (rc *RetryClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return retryCall(ctx, rc, func() (uint64, error) {
+		return rc.Client.BlockNumber(ctx)
+	})
+}
+
+// ChainID returns the current chain ID.
+func (rc *RetryClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return retryCall(ctx, rc, func() (*big.Int, error) {
+		return rc.Client.ChainID(ctx)
+	})
+}
+
+// BalanceAt returns the balance of the given address at the given block number.
+func (rc *RetryClient) BalanceAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int,
+) (*big.Int, error) {
+	return retryCall(ctx, rc, func() (*big.Int, error) {
+		return rc.Client.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+// CodeAt returns the code of the given account at the given block number.
+func (rc *RetryClient) CodeAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int,
+) ([]byte, error) {
+	return retryCall(ctx, rc, func() ([]byte, error) {
+		return rc.Client.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+// CallContract calls a contract with the given message at the given block number.
+func (rc *RetryClient) CallContract(
+	ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int,
+) ([]byte, error) {
+	return retryCall(ctx, rc, func() ([]byte, error) {
+		return rc.Client.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+// EstimateGas estimates the gas needed to execute a specific transaction.
+func (rc *RetryClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return retryCall(ctx, rc, func() (uint64, error) {
+		return rc.Client.EstimateGas(ctx, msg)
+	})
+}
+
+// FilterLogs returns the logs that satisfy the given filter query.
+func (rc *RetryClient) FilterLogs(
+	ctx context.Context, q ethereum.FilterQuery,
+) ([]ethcoretypes.Log, error) {
+	return retryCall(ctx, rc, func() ([]ethcoretypes.Log, error) {
+		return rc.Client.FilterLogs(ctx, q)
+	})
+}
+
+// HeaderByNumber returns the header of the block with the given number.
+func (rc *RetryClient) HeaderByNumber(
+	ctx context.Context, number *big.Int,
+) (*ethcoretypes.Header, error) {
+	return retryCall(ctx, rc, func() (*ethcoretypes.Header, error) {
+		return rc.Client.HeaderByNumber(ctx, number)
+	})
+}
+
+// PendingCodeAt returns the code of the given account in the pending state.
+func (rc *RetryClient) PendingCodeAt(
+	ctx context.Context, account common.Address,
+) ([]byte, error) {
+	return retryCall(ctx, rc, func() ([]byte, error) {
+		return rc.Client.PendingCodeAt(ctx, account)
+	})
+}
+
+// PendingNonceAt returns the nonce of the given account in the pending state.
+func (rc *RetryClient) PendingNonceAt(
+	ctx context.Context, account common.Address,
+) (uint64, error) {
+	return retryCall(ctx, rc, func() (uint64, error) {
+		return rc.Client.PendingNonceAt(ctx, account)
+	})
+}
+
+// NonceAt returns the nonce of the given account at the given block number.
+func (rc *RetryClient) NonceAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int,
+) (uint64, error) {
+	return retryCall(ctx, rc, func() (uint64, error) {
+		return rc.Client.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+// SuggestGasPrice suggests a gas price.
+func (rc *RetryClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return retryCall(ctx, rc, func() (*big.Int, error) {
+		return rc.Client.SuggestGasPrice(ctx)
+	})
+}
+
+// SuggestGasTipCap suggests a gas tip cap.
+func (rc *RetryClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return retryCall(ctx, rc, func() (*big.Int, error) {
+		return rc.Client.SuggestGasTipCap(ctx)
+	})
+}
+
+// FeeHistory returns the fee history of the blockCount blocks up to lastBlock, with the given
+// percentiles of the effective tips in each block.
+func (rc *RetryClient) FeeHistory(
+	ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64,
+) (*ethereum.FeeHistory, error) {
+	return retryCall(ctx, rc, func() (*ethereum.FeeHistory, error) {
+		return rc.Client.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (rc *RetryClient) TransactionByHash(
+	ctx context.Context, hash common.Hash,
+) (*ethcoretypes.Transaction, bool, error) {
+	var isPending bool
+	tx, err := retryCall(ctx, rc, func() (*ethcoretypes.Transaction, error) {
+		var (
+			tx  *ethcoretypes.Transaction
+			err error
+		)
+		tx, isPending, err = rc.Client.TransactionByHash(ctx, hash)
+		return tx, err
+	})
+	return tx, isPending, err
+}
+
+// CreateAccessList returns the access list of the call against the pending state.
+func (rc *RetryClient) CreateAccessList(
+	ctx context.Context, msg ethereum.CallMsg,
+) (*ethcoretypes.AccessList, uint64, error) {
+	var gasUsed uint64
+	accessList, err := retryCall(ctx, rc, func() (*ethcoretypes.AccessList, error) {
+		var (
+			accessList *ethcoretypes.AccessList
+			err        error
+		)
+		accessList, gasUsed, err = rc.Client.CreateAccessList(ctx, msg)
+		return accessList, err
+	})
+	return accessList, gasUsed, err
+}
+
+// TxPoolContentFrom returns the pending and queued transactions of this address.
+func (rc *RetryClient) TxPoolContentFrom(
+	ctx context.Context, address common.Address,
+) (map[string]map[string]*ethcoretypes.Transaction, error) {
+	return retryCall(ctx, rc, func() (map[string]map[string]*ethcoretypes.Transaction, error) {
+		return rc.Client.TxPoolContentFrom(ctx, address)
+	})
+}
+
+// TxPoolInspect returns the textual summary of all pending and queued transactions.
+func (rc *RetryClient) TxPoolInspect(
+	ctx context.Context,
+) (map[string]map[common.Address]map[string]string, error) {
+	return retryCall(ctx, rc, func() (map[string]map[common.Address]map[string]string, error) {
+		return rc.Client.TxPoolInspect(ctx)
+	})
+}
@@ -0,0 +1,92 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyClient is a Client whose calls to estimate gas fail with the given errors before
+// succeeding.
+type flakyClient struct {
+	Client
+
+	mu    sync.Mutex
+	errs  []error
+	calls int
+}
+
+func (c *flakyClient) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return 0, err
+	}
+	return 21000, nil
+}
+
+func (c *flakyClient) SendTransaction(context.Context, *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	return syscall.ECONNRESET
+}
+
+func TestRetryClient(t *testing.T) {
+	ctx := context.Background()
+	cfg := RetryConfig{MaxRetries: 2, Backoff: time.Millisecond}
+
+	t.Run("retryable network error", func(t *testing.T) {
+		client := &flakyClient{errs: []error{syscall.ECONNREFUSED, syscall.ECONNRESET}}
+		gas, err := NewRetryClient(client, cfg).EstimateGas(ctx, ethereum.CallMsg{})
+		require.NoError(t, err)
+		require.EqualValues(t, 21000, gas)
+		require.Equal(t, 3, client.calls)
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		client := &flakyClient{errs: []error{
+			syscall.ECONNREFUSED, syscall.ECONNREFUSED, syscall.ECONNRESET,
+		}}
+		_, err := NewRetryClient(client, cfg).EstimateGas(ctx, ethereum.CallMsg{})
+		require.ErrorIs(t, err, syscall.ECONNRESET)
+		require.Equal(t, 3, client.calls)
+	})
+
+	t.Run("non-retryable execution revert", func(t *testing.T) {
+		client := &flakyClient{errs: []error{revertError{}}}
+		_, err := NewRetryClient(client, cfg).EstimateGas(ctx, ethereum.CallMsg{})
+		require.ErrorIs(t, err, revertError{})
+		require.Equal(t, 1, client.calls)
+	})
+
+	t.Run("sends are not retried", func(t *testing.T) {
+		client := &flakyClient{}
+		tx := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(1)})
+		err := NewRetryClient(client, cfg).SendTransaction(ctx, tx)
+		require.ErrorIs(t, err, syscall.ECONNRESET)
+		require.Equal(t, 1, client.calls)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		client := &flakyClient{errs: []error{syscall.ECONNREFUSED, syscall.ECONNREFUSED}}
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := NewRetryClient(client, RetryConfig{Backoff: time.Hour}).
+			EstimateGas(cancelled, ethereum.CallMsg{})
+		require.ErrorIs(t, err, syscall.ECONNREFUSED)
+		require.Equal(t, 1, client.calls)
+	})
+}
@@ -0,0 +1,137 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RetryTransport is a http.RoundTripper that retries requests rejected at the HTTP level for being
+// rate limited (429) or for the endpoint being temporarily unavailable (503), e.g. by hosted RPC
+// providers, since they were not processed by the node. Retries wait for the delay of the
+// response's Retry-After header if any, or with exponential backoff otherwise. Errors returned by
+// the node over JSON-RPC (in 200 responses) are left to the callers (e.g. the transactor's
+// Sender's retry policies), so requests sending transactions are retried safely.
+type RetryTransport struct {
+	base http.RoundTripper
+	cfg  RetryConfig
+}
+
+// NewRetryTransport creates a RetryTransport that sends requests with the given transport
+// (http.DefaultTransport if nil).
+func NewRetryTransport(base http.RoundTripper, cfg RetryConfig) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{base: base, cfg: cfg.withDefaults()}
+}
+
+// DialHTTPWithRetries dials the node at the HTTP(S) URL with a RetryTransport.
+func DialHTTPWithRetries(
+	ctx context.Context, rawurl string, cfg RetryConfig,
+) (*ethclient.Client, error) {
+	client := &http.Client{Transport: NewRetryTransport(nil, cfg)}
+	rpcClient, err := rpc.DialOptions(ctx, rawurl, rpc.WithHTTPClient(client))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rpcClient), nil
+}
+
+// dialEthClient dials the node at the URL, with a RetryTransport with the given config if it's an
+// HTTP(S) URL. This is how the clients of this package dial nodes.
+func dialEthClient(
+	ctx context.Context, rawurl string, cfg RetryConfig,
+) (*ethclient.Client, error) {
+	if u, err := url.Parse(rawurl); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return DialHTTPWithRetries(ctx, rawurl, cfg)
+	}
+	return ethclient.DialContext(ctx, rawurl)
+}
+
+// RoundTrip sends the request, retrying it while it's rejected with a 429 or 503 response. Returns
+// the last response once the retries are exhausted, its Retry-After delay exceeds the configured
+// max, or the request's context is done.
+func (rt *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The body is sent again on each retry, so buffer it unless it can be recreated, in which
+	// case each attempt sends a new copy and the original body is closed unread.
+	getBody := req.GetBody
+	if req.Body != nil && req.Body != http.NoBody && getBody != nil {
+		_ = req.Body.Close()
+	} else if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		getBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	backoff := rt.cfg.Backoff
+	for retry := 0; ; retry++ {
+		attempt := req
+		if getBody != nil {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt = req.Clone(req.Context())
+			attempt.Body = body
+		}
+
+		resp, err := rt.base.RoundTrip(attempt)
+		if err != nil || !isRetryableStatus(resp.StatusCode) || retry == rt.cfg.MaxRetries {
+			return resp, err
+		}
+		delay, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			delay = backoff
+			backoff = min(2*backoff, rt.cfg.MaxBackoff) //nolint:gomnd // doubles.
+		}
+		if delay > rt.cfg.MaxRetryAfter {
+			return resp, nil
+		}
+
+		// Drain the body so the connection can be reused.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryableStatus returns whether a response with the status code was rejected before being
+// processed, and may succeed if retried.
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// retryAfter returns the delay of the Retry-After header value, which is either a number of
+// seconds or an HTTP date, as of now. Returns false if the value is empty or invalid.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,165 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// statusServer is a JSON-RPC server answering eth_chainId, which first rejects requests with the
+// given statuses and Retry-After headers.
+func statusServer(
+	t *testing.T, calls *atomic.Int32, statuses []int, retryAfter string,
+) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		call := int(calls.Add(1))
+		if call <= len(statuses) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			http.Error(w, http.StatusText(statuses[call-1]), statuses[call-1])
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x50"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRetryTransport(t *testing.T) {
+	ctx := context.Background()
+	cfg := RetryConfig{MaxRetries: 2, Backoff: time.Millisecond}
+
+	t.Run("rate limited then success", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := statusServer(t, &calls, []int{http.StatusTooManyRequests}, "0")
+		client, err := DialHTTPWithRetries(ctx, srv.URL, cfg)
+		require.NoError(t, err)
+		chainID, err := client.ChainID(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 80, chainID.Int64())
+		require.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("unavailable until retries exhausted", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := statusServer(t, &calls, []int{503, 503, 503}, "")
+		client, err := DialHTTPWithRetries(ctx, srv.URL, cfg)
+		require.NoError(t, err)
+		_, err = client.ChainID(ctx)
+		var httpErr rpc.HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		require.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+		require.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("other statuses are not retried", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := statusServer(t, &calls, []int{http.StatusInternalServerError}, "")
+		client, err := DialHTTPWithRetries(ctx, srv.URL, cfg)
+		require.NoError(t, err)
+		_, err = client.ChainID(ctx)
+		require.Error(t, err)
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("Retry-After over the max", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := statusServer(t, &calls, []int{http.StatusTooManyRequests}, "60")
+		client, err := DialHTTPWithRetries(ctx, srv.URL, cfg)
+		require.NoError(t, err)
+		_, err = client.ChainID(ctx)
+		require.Error(t, err)
+		require.EqualValues(t, 1, calls.Load())
+	})
+}
+
+func TestRetryTransportDialPaths(t *testing.T) {
+	ctx := context.Background()
+	for name, dial := range map[string]func(url string) (Client, error){
+		"DialRetryClient": func(url string) (Client, error) {
+			return DialRetryClient(ctx, url, RetryConfig{Backoff: time.Millisecond}, time.Second)
+		},
+		"ExtendedEthClient": func(url string) (Client, error) {
+			client := NewExtendedEthClient(nil, time.Second)
+			return client, client.DialContext(ctx, url)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// HTTP URLs are dialed with a RetryTransport.
+			var calls atomic.Int32
+			srv := statusServer(t, &calls, []int{http.StatusTooManyRequests}, "0")
+			client, err := dial(srv.URL)
+			require.NoError(t, err)
+			defer client.Close()
+			chainID, err := client.ChainID(ctx)
+			require.NoError(t, err)
+			require.EqualValues(t, 80, chainID.Int64())
+			require.EqualValues(t, 2, calls.Load())
+		})
+	}
+}
+
+// closeTracker is a request body that records whether it was closed.
+type closeTracker struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+// roundTripperFunc is a http.RoundTripper that calls the func.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransportClosesBody(t *testing.T) {
+	rt := NewRetryTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		_, _ = io.Copy(io.Discard, req.Body)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, req.Body.Close()
+	}), RetryConfig{})
+
+	// The body is replayed with GetBody, so the original body is closed unread.
+	body := &closeTracker{Reader: strings.NewReader("{}")}
+	req := httptest.NewRequest(http.MethodPost, "http://node", body)
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("{}")), nil
+	}
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.True(t, body.closed.Load())
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for value, expected := range map[string]time.Duration{
+		"3":                             3 * time.Second,
+		"Mon, 01 Jan 2024 00:00:05 GMT": 5 * time.Second,
+		"Sun, 31 Dec 2023 23:59:00 GMT": 0,
+	} {
+		delay, ok := retryAfter(value, now)
+		require.True(t, ok, value)
+		require.Equal(t, expected, delay, value)
+	}
+	for _, value := range []string{"", "-1", "soon"} {
+		_, ok := retryAfter(value, now)
+		require.False(t, ok, value)
+	}
+}
@@ -0,0 +1,243 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	defaultResubscribeBackoff    = time.Second
+	defaultResubscribeMaxBackoff = 30 * time.Second
+)
+
+// SubscriptionConfig configures a resilient subscription.
+type SubscriptionConfig struct {
+	// optional, backoff before the first resubscription attempt, which doubles with each failed
+	// attempt; 0 corresponds to 1s
+	Backoff time.Duration
+	// optional, max backoff between resubscription attempts, 0 corresponds to 30s
+	MaxBackoff time.Duration
+	// optional, max number of consecutive failed subscription attempts before failing, 0
+	// corresponds to retrying forever
+	MaxRetries int
+	// optional, queries the events missed while resubscribing once resubscribed
+	Backfill bool
+}
+
+// SubscribeNewHeadResilient subscribes to new heads, transparently resubscribing with backoff
+// whenever the subscription fails (e.g. the WebSocket connection drops). If Backfill is set, the
+// heads missed while resubscribing are queried and delivered once resubscribed, in order. Heads
+// are delivered at most once, in increasing order of number.
+//
+// The heads channel is closed once the context is done or the subscription fails fatally (i.e.
+// after MaxRetries consecutive failed attempts), in which case the error is sent on the errors
+// channel first.
+func SubscribeNewHeadResilient(
+	ctx context.Context, client Client, logger log.Logger, cfg SubscriptionConfig,
+) (<-chan *ethcoretypes.Header, <-chan error) {
+	var (
+		heads = make(chan *ethcoretypes.Header)
+		last  *big.Int // number of the last delivered head
+	)
+	send := func(head *ethcoretypes.Header) bool {
+		if last != nil && head.Number.Cmp(last) <= 0 {
+			return true // already delivered
+		}
+		select {
+		case heads <- head:
+			last = head.Number
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	backfill := func(ctx context.Context) bool {
+		if last == nil {
+			return true
+		}
+		latest, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			logger.Warn("failed to backfill missed heads", "err", err)
+			return true
+		}
+		next := new(big.Int).Add(last, common.Big1)
+		for n := next; n.Cmp(latest.Number) < 0; n = new(big.Int).Add(n, common.Big1) {
+			head, err := client.HeaderByNumber(ctx, n)
+			if err != nil {
+				logger.Warn("failed to backfill missed heads", "err", err)
+				return true
+			}
+			if !send(head) {
+				return false
+			}
+		}
+		return send(latest)
+	}
+
+	subscribe := func(
+		ctx context.Context,
+	) (<-chan *ethcoretypes.Header, ethereum.Subscription, error) {
+		return client.SubscribeNewHead(ctx)
+	}
+	return heads, runResilientSubscription(ctx, logger, cfg, subscribe, send, backfill, heads)
+}
+
+// SubscribeFilterLogsResilient subscribes to logs that satisfy the filter query, transparently
+// resubscribing with backoff whenever the subscription fails (e.g. the WebSocket connection
+// drops). If Backfill is set, the logs of the blocks after the block of the last delivered log
+// are queried and delivered once resubscribed, so logs of that block emitted while resubscribing
+// may be missed.
+//
+// The logs channel is closed once the context is done or the subscription fails fatally (i.e.
+// after MaxRetries consecutive failed attempts), in which case the error is sent on the errors
+// channel first.
+func SubscribeFilterLogsResilient(
+	ctx context.Context, client Client, q ethereum.FilterQuery, logger log.Logger,
+	cfg SubscriptionConfig,
+) (<-chan ethcoretypes.Log, <-chan error) {
+	var (
+		logs         = make(chan ethcoretypes.Log)
+		lastBlock    uint64 // block of the last delivered log
+		backfilledTo uint64 // block up to which logs were backfilled
+	)
+	send := func(l ethcoretypes.Log) bool {
+		if l.BlockNumber <= backfilledTo {
+			return true // already delivered by the backfill
+		}
+		select {
+		case logs <- l:
+			lastBlock = max(lastBlock, l.BlockNumber)
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	backfill := func(ctx context.Context) bool {
+		if lastBlock == 0 {
+			return true
+		}
+		latest, err := client.BlockNumber(ctx)
+		if err != nil || latest <= lastBlock {
+			return true
+		}
+
+		backfillQuery := q
+		backfillQuery.BlockHash = nil
+		backfillQuery.FromBlock = new(big.Int).SetUint64(lastBlock + 1)
+		backfillQuery.ToBlock = new(big.Int).SetUint64(latest)
+		missed, err := client.FilterLogs(ctx, backfillQuery)
+		if err != nil {
+			logger.Warn("failed to backfill missed logs", "err", err)
+			return true
+		}
+		for _, l := range missed {
+			if !send(l) {
+				return false
+			}
+		}
+		backfilledTo = latest
+		return true
+	}
+
+	subscribe := func(ctx context.Context) (<-chan ethcoretypes.Log, ethereum.Subscription, error) {
+		ch := make(chan ethcoretypes.Log)
+		sub, err := client.SubscribeFilterLogs(ctx, q, ch)
+		return ch, sub, err
+	}
+	return logs, runResilientSubscription(ctx, logger, cfg, subscribe, send, backfill, logs)
+}
+
+// runResilientSubscription runs the subscription in a go-routine, delivering its events with
+// send and resubscribing whenever it fails. Once resubscribed, backfill is called if configured.
+// Returns the channel on which a fatal error is sent. The events channel is closed once done.
+func runResilientSubscription[T any](
+	ctx context.Context, logger log.Logger, cfg SubscriptionConfig,
+	subscribe func(context.Context) (<-chan T, ethereum.Subscription, error),
+	send func(T) bool, backfill func(context.Context) bool, events chan T,
+) <-chan error {
+	if cfg.Backoff == 0 {
+		cfg.Backoff = defaultResubscribeBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = defaultResubscribeMaxBackoff
+	}
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		backoff, failures, subscribed := cfg.Backoff, 0, false
+		for {
+			ch, sub, err := subscribe(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if failures++; cfg.MaxRetries > 0 && failures > cfg.MaxRetries {
+					errs <- err
+					return
+				}
+				logger.Warn("failed to subscribe, retrying", "err", err, "backoff", backoff)
+				if !sleepCtx(ctx, backoff) {
+					return
+				}
+				backoff = min(2*backoff, cfg.MaxBackoff) //nolint:gomnd // doubles.
+				continue
+			}
+
+			if subscribed && cfg.Backfill && !backfill(ctx) {
+				sub.Unsubscribe()
+				return
+			}
+			backoff, failures, subscribed = cfg.Backoff, 0, true
+
+			if !forwardEvents(ctx, logger, ch, sub, send) {
+				return
+			}
+		}
+	}()
+
+	return errs
+}
+
+// forwardEvents delivers the events of the subscription until it fails, returning false if the
+// context is done instead.
+func forwardEvents[T any](
+	ctx context.Context, logger log.Logger, ch <-chan T, sub ethereum.Subscription,
+	send func(T) bool,
+) bool {
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case event := <-ch:
+			if !send(event) {
+				return false
+			}
+		case err := <-sub.Err():
+			logger.Warn("subscription failed, resubscribing", "err", err)
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// sleepCtx sleeps for the duration, returning false if the context is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,142 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/ethereum/go-ethereum"
+	ethcoretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscription is a subscription that can be killed.
+type fakeSubscription struct {
+	err  chan error
+	once sync.Once
+}
+
+func newFakeSubscription() *fakeSubscription {
+	return &fakeSubscription{err: make(chan error, 1)}
+}
+
+func (s *fakeSubscription) Err() <-chan error { return s.err }
+
+func (s *fakeSubscription) Unsubscribe() { s.once.Do(func() { close(s.err) }) }
+
+func (s *fakeSubscription) kill() { s.err <- errors.New("connection dropped") }
+
+// headSubscriptionClient is a Client whose head subscriptions are driven by the test.
+type headSubscriptionClient struct {
+	Client
+
+	mu         sync.Mutex
+	subErrs    []error // errors of the next subscription attempts
+	heads      chan *ethcoretypes.Header
+	sub        *fakeSubscription
+	subscribed chan struct{}
+	latest     int64 // number of the latest head, for backfilling
+}
+
+func (c *headSubscriptionClient) SubscribeNewHead(
+	context.Context,
+) (chan *ethcoretypes.Header, ethereum.Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.subErrs) > 0 {
+		err := c.subErrs[0]
+		c.subErrs = c.subErrs[1:]
+		return nil, nil, err
+	}
+	c.heads, c.sub = make(chan *ethcoretypes.Header), newFakeSubscription()
+	c.subscribed <- struct{}{}
+	return c.heads, c.sub, nil
+}
+
+func (c *headSubscriptionClient) HeaderByNumber(
+	_ context.Context, number *big.Int,
+) (*ethcoretypes.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if number == nil {
+		number = big.NewInt(c.latest)
+	}
+	return &ethcoretypes.Header{Number: number}, nil
+}
+
+// current returns the channel and subscription of the current subscription.
+func (c *headSubscriptionClient) current() (chan *ethcoretypes.Header, *fakeSubscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.heads, c.sub
+}
+
+func TestSubscribeNewHeadResilient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &headSubscriptionClient{
+		subscribed: make(chan struct{}, 1),
+		subErrs:    []error{errors.New("dial failed")},
+	}
+	heads, errs := SubscribeNewHeadResilient(
+		ctx, client, log.NewBlankLogger(io.Discard),
+		SubscriptionConfig{Backoff: time.Millisecond, Backfill: true},
+	)
+	next := func() int64 {
+		select {
+		case head := <-heads:
+			return head.Number.Int64()
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a head")
+			return 0
+		}
+	}
+
+	// The failed first attempt is retried.
+	<-client.subscribed
+	ch, sub := client.current()
+	ch <- &ethcoretypes.Header{Number: big.NewInt(1)}
+	require.EqualValues(t, 1, next())
+
+	// Kill the subscription while heads 2 and 3 are produced.
+	client.mu.Lock()
+	client.latest = 3
+	client.mu.Unlock()
+	sub.kill()
+
+	// Once resubscribed, the missed heads are backfilled and events resume without duplicates.
+	<-client.subscribed
+	require.EqualValues(t, 2, next())
+	require.EqualValues(t, 3, next())
+	ch, _ = client.current()
+	ch <- &ethcoretypes.Header{Number: big.NewInt(3)}
+	ch <- &ethcoretypes.Header{Number: big.NewInt(4)}
+	require.EqualValues(t, 4, next())
+
+	// The heads channel is closed once the context is done.
+	cancel()
+	for range heads { //nolint:revive // drain.
+	}
+	require.Empty(t, errs)
+}
+
+func TestSubscribeNewHeadResilientFatal(t *testing.T) {
+	dialErr := errors.New("dial failed")
+	client := &headSubscriptionClient{subErrs: []error{dialErr, dialErr, dialErr}}
+	heads, errs := SubscribeNewHeadResilient(
+		context.Background(), client, log.NewBlankLogger(io.Discard),
+		SubscriptionConfig{Backoff: time.Millisecond, MaxRetries: 2},
+	)
+
+	// The subscription fails after the retries are exhausted.
+	require.ErrorIs(t, <-errs, dialErr)
+	_, ok := <-heads
+	require.False(t, ok)
+}
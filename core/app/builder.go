@@ -15,7 +15,7 @@ type Builder interface {
 	BuildApp(log.Logger) *baseapp.BaseApp
 	RegisterJob(job.Basic)
 	RegisterDB(db ethdb.KeyValueStore)
-	RegisterHTTPHandler(handler *server.Handler) error
+	RegisterHTTPHandler(handler *server.Handler, mw ...server.Middleware) error
 	RegisterMiddleware(m server.Middleware) error
 	RegisterPrometheusTelemetry() error
 }
@@ -3,6 +3,8 @@ package transactor
 import (
 	"time"
 
+	"github.com/berachain/offchain-sdk/core/transactor/factory"
+	"github.com/berachain/offchain-sdk/core/transactor/sender"
 	"github.com/berachain/offchain-sdk/types/queue/sqs"
 )
 
@@ -19,8 +21,25 @@ type Config struct {
 
 	// Maximum duration allowed for the tx to be signed (increase this if using a remote signer)
 	SignTxTimeout time.Duration
+	// Whether txs are simulated (with an eth_call against the pending state) as they are built,
+	// so that txs that would revert are failed rather than sent.
+	SimulateBeforeSend bool
+
+	// How the gas tip of txs is estimated (from eth_maxPriorityFeePerGas, or the fee history if
+	// unsupported), which replacements bump from.
+	GasTip factory.GasTipConfig
+	// Retry and replacement policies used when sending txs.
+	Sender sender.Config
+	// (Optional) URL of a private tx relay (e.g. Flashbots Protect) that txs are sent through
+	// with eth_sendPrivateTransaction, instead of being broadcast to the public mempool.
+	PrivateRelayURL string
+	// Whether txs are broadcast to the public mempool if the private relay can't be reached.
+	PrivateRelayFallback bool
 
 	// How long to wait for the pending nonce (ideally 1 block time).
+	//
+	// Deprecated: unused, since nonces are handed out by the nonce manager, which reads the
+	// pending nonce when reconciling with the chain.
 	PendingNonceInterval time.Duration
 	// How long to wait for a tx to hit the mempool (ideally 1-2 block time).
 	InMempoolTimeout time.Duration
@@ -18,6 +18,7 @@ import (
 
 const (
 	tryAggregate      = `tryAggregate`
+	aggregate3Value   = `aggregate3Value`
 	executionReverted = `execution reverted: `
 )
 
@@ -28,9 +29,11 @@ var _ factory.Batcher = (*Multicall3)(nil)
 type Multicall3 struct {
 	contractAddress common.Address
 	packer          *types.Packer
+	atomic          bool // whether the batch reverts if any of its calls fail
 }
 
-// NewMulticall3 creates a new Multicall3 instance.
+// NewMulticall3 creates a new Multicall3 instance. The batched calls are executed independently
+// with `tryAggregate`, so the batch succeeds even if some of its calls fail.
 func NewMulticall3(address common.Address) *Multicall3 {
 	return &Multicall3{
 		contractAddress: address,
@@ -38,10 +41,18 @@ func NewMulticall3(address common.Address) *Multicall3 {
 	}
 }
 
+// NewAtomicMulticall3 creates a new Multicall3 instance that executes the batched calls
+// atomically with `aggregate3Value`: the batch reverts if any of its calls fail, and the value of
+// each call is forwarded to its target.
+func NewAtomicMulticall3(address common.Address) *Multicall3 {
+	mc := NewMulticall3(address)
+	mc.atomic = true
+	return mc
+}
+
 // BatchRequests creates a batched transaction request for the given call requests.
 func (mc *Multicall3) BatchRequests(callReqs ...*ethereum.CallMsg) *types.Request {
 	var (
-		calls       = make([]bindings.Multicall3Call, len(callReqs))
 		totalValue  = big.NewInt(0)
 		gasLimit    = uint64(0)
 		gasTipCap   *big.Int
@@ -49,7 +60,7 @@ func (mc *Multicall3) BatchRequests(callReqs ...*ethereum.CallMsg) *types.Reques
 		gasPriceSet = false
 	)
 
-	for i, callReq := range callReqs {
+	for _, callReq := range callReqs {
 		// use the summed value for the batched transaction.
 		if callReq.Value != nil {
 			totalValue = totalValue.Add(totalValue, callReq.Value)
@@ -64,20 +75,52 @@ func (mc *Multicall3) BatchRequests(callReqs ...*ethereum.CallMsg) *types.Reques
 			gasFeeCap = callReq.GasFeeCap
 			gasPriceSet = true
 		}
-
-		calls[i] = bindings.Multicall3Call{
-			Target:   *callReq.To,
-			CallData: callReq.Data,
-		}
 	}
 
 	txRequest, _ := mc.packer.CreateRequest(
 		"", mc.contractAddress, totalValue, gasTipCap, gasFeeCap, gasLimit,
-		tryAggregate, false, calls,
+		mc.method(), mc.args(callReqs)...,
 	)
 	return txRequest
 }
 
+// method returns the Multicall3 method that the calls are batched into.
+func (mc *Multicall3) method() string {
+	if mc.atomic {
+		return aggregate3Value
+	}
+	return tryAggregate
+}
+
+// args returns the arguments of the Multicall3 method for the given call requests.
+func (mc *Multicall3) args(callReqs []*ethereum.CallMsg) []any {
+	if mc.atomic {
+		calls := make([]bindings.Multicall3Call3Value, len(callReqs))
+		for i, callReq := range callReqs {
+			value := callReq.Value
+			if value == nil {
+				value = big.NewInt(0)
+			}
+			calls[i] = bindings.Multicall3Call3Value{
+				Target:       *callReq.To,
+				AllowFailure: false,
+				Value:        value,
+				CallData:     callReq.Data,
+			}
+		}
+		return []any{calls}
+	}
+
+	calls := make([]bindings.Multicall3Call, len(callReqs))
+	for i, callReq := range callReqs {
+		calls[i] = bindings.Multicall3Call{
+			Target:   *callReq.To,
+			CallData: callReq.Data,
+		}
+	}
+	return []any{false, calls}
+}
+
 // BatchCallRequests uses the Multicall3 contract to create a batched call request for the given
 // call messages and return the batched call result data for each call, as a `[]Multicall3Result`.
 func (mc *Multicall3) BatchCallRequests(
@@ -101,7 +144,7 @@ func (mc *Multicall3) BatchCallRequests(
 	}
 
 	// unpack the return data into call results
-	callResult, err := mc.packer.GetCallResult(tryAggregate, ret)
+	callResult, err := mc.packer.GetCallResult(mc.method(), ret)
 	if err != nil {
 		sCtx.Logger().Error("failed to unpack call response", "err", err)
 		return nil, err
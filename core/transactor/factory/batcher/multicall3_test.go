@@ -9,7 +9,9 @@ import (
 	"github.com/berachain/offchain-sdk/core/transactor/factory/batcher"
 	"github.com/berachain/offchain-sdk/core/transactor/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -76,3 +78,43 @@ func TestMulticall3(t *testing.T) {
 	assert.Equal(t, 1, len(ret2))
 	assert.Equal(t, uint64(0), ret2[0].(*big.Int).Uint64())
 }
+
+func TestAtomicMulticall3BatchRequests(t *testing.T) {
+	var (
+		multicall3Addr = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+		target         = common.HexToAddress("0x1")
+		otherTarget    = common.HexToAddress("0x2")
+	)
+	multicaller := batcher.NewAtomicMulticall3(multicall3Addr)
+
+	// batch two calls to the same target and a payable call to another target
+	req := multicaller.BatchRequests(
+		&ethereum.CallMsg{To: &target, Data: []byte{0x1}, Gas: 100},
+		&ethereum.CallMsg{To: &target, Data: []byte{0x2}, Gas: 200},
+		&ethereum.CallMsg{To: &otherTarget, Data: []byte{0x3}, Value: big.NewInt(5), Gas: 300},
+	)
+
+	// the calls are aggregated atomically (no failures allowed), forwarding each call's value
+	mc3Abi, err := bindings.Multicall3MetaData.GetAbi()
+	require.NoError(t, err)
+	expected, err := mc3Abi.Pack("aggregate3Value", []bindings.Multicall3Call3Value{
+		{Target: target, AllowFailure: false, Value: big.NewInt(0), CallData: []byte{0x1}},
+		{Target: target, AllowFailure: false, Value: big.NewInt(0), CallData: []byte{0x2}},
+		{Target: otherTarget, AllowFailure: false, Value: big.NewInt(5), CallData: []byte{0x3}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected, req.Data)
+	require.Equal(t, &multicall3Addr, req.To)
+	require.Equal(t, big.NewInt(5), req.Value)
+	require.Equal(t, uint64(600), req.Gas)
+
+	// the non-atomic multicaller keeps using tryAggregate
+	req = batcher.NewMulticall3(multicall3Addr).BatchRequests(
+		&ethereum.CallMsg{To: &target, Data: []byte{0x1}},
+	)
+	expected, err = mc3Abi.Pack("tryAggregate", false, []bindings.Multicall3Call{
+		{Target: target, CallData: []byte{0x1}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected, req.Data)
+}
@@ -0,0 +1,60 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrBatchContractCreation is returned when multiple requests are built into a batched
+// transaction, but one of them is a contract creation, which can't be batched.
+var ErrBatchContractCreation = errors.New("contract creation requests can't be batched")
+
+// ErrNoNonceManager is returned when building a transaction with multiple signers (see
+// AddSigners) without a nonce manager, which is required to track the nonces of each account.
+var ErrNoNonceManager = errors.New("a nonce manager is required to build with multiple signers")
+
+// RevertError is returned when simulating a transaction before it is sent shows that it would
+// revert.
+type RevertError struct {
+	// Reason is the decoded revert reason, if the revert data is a standard Error(string).
+	Reason string
+	// Data is the raw revert data, if the node returned any.
+	Data []byte
+	// Err is the error returned by the node for the simulated call.
+	Err error
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("transaction simulation reverted: %s", e.Reason)
+	}
+	return fmt.Sprintf("transaction simulation reverted: %v", e.Err)
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.Err
+}
+
+// asRevertError returns the error of a simulated call as a RevertError if the call reverted, or
+// as-is otherwise (e.g. if the node could not be reached).
+func asRevertError(err error) error {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		if strings.Contains(err.Error(), "execution reverted") {
+			return &RevertError{Err: err}
+		}
+		return err
+	}
+
+	revertErr := &RevertError{Err: err}
+	if data, ok := dataErr.ErrorData().(string); ok {
+		revertErr.Data, _ = hexutil.Decode(data)
+		revertErr.Reason, _ = abi.UnpackRevert(revertErr.Data)
+	}
+	return revertErr
+}
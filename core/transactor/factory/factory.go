@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/berachain/offchain-sdk/client/eth"
@@ -13,18 +15,33 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
-// Factory is a transaction factory that builds 1559 transactions with the configured signer.
+// Factory is a transaction factory that builds 1559 transactions with the configured signer. The
+// signing step can be plugged in with NewTxSigner, e.g. to sign through an external KMS/HSM.
+//
+// Transactions carry the (EIP-2930) access list of their request, if any, or else one created by
+// the node if enabled with SetCreateAccessLists.
+//
+// For higher throughput, more signers can be added with AddSigners, in which case new
+// transactions are built from the accounts of the signers in round-robin order.
 type Factory struct {
 	noncer        Noncer
-	signer        kmstypes.TxSigner
+	nonceManager  NonceManager
 	signTxTimeout time.Duration
+	signers       map[common.Address]kmstypes.TxSigner // by address, including the signer
+	accounts      []common.Address                     // of the signers, in round-robin order
+	nextAccount   atomic.Uint64                        // index of the next account to build from
 	batcher       Batcher
+	simulate      bool         // whether to simulate txs before they are signed
+	accessLists   bool         // whether to create access lists for requests without one
+	gasTip        GasTipConfig // how to estimate the gas tip of txs
 
 	// caches
 	ethClient     eth.Client
 	chainID       *big.Int
+	chainIDMu     sync.Mutex // protects chainID, which is fetched on first use
 	signerAddress common.Address
 }
 
@@ -34,18 +51,81 @@ func New(
 ) *Factory {
 	return &Factory{
 		noncer:        noncer,
-		signer:        signer,
 		signTxTimeout: signTxTimeout,
 		batcher:       batcher,
+		signers:       map[common.Address]kmstypes.TxSigner{signer.Address(): signer},
+		accounts:      []common.Address{signer.Address()},
 		signerAddress: signer.Address(),
 	}
 }
 
+// AddSigners adds signers of more (funded) accounts to build transactions from, for spreading
+// sends across accounts. New transactions are built from the configured signer's and the added
+// signers' accounts in round-robin order, while rebuilt transactions keep the account of their
+// request's From. Since each account has its own nonces, a nonce manager must be set (see
+// SetNonceManager); otherwise building fails with ErrNoNonceManager.
+func (f *Factory) AddSigners(signers ...kmstypes.TxSigner) {
+	for _, signer := range signers {
+		if _, ok := f.signers[signer.Address()]; ok {
+			continue
+		}
+		f.signers[signer.Address()] = signer
+		f.accounts = append(f.accounts, signer.Address())
+	}
+}
+
+// From returns the address of the account that the (signed) transaction was built from.
+func (*Factory) From(tx *coretypes.Transaction) (common.Address, error) {
+	return coretypes.Sender(coretypes.LatestSignerForChainID(tx.ChainId()), tx)
+}
+
+// account returns the given account if it's one of the signers' accounts, or else the configured
+// signer's account.
+func (f *Factory) account(from common.Address) common.Address {
+	if _, ok := f.signers[from]; ok {
+		return from
+	}
+	return f.signerAddress
+}
+
+// accountFor returns the account to build the request from: the request's From if it's one of
+// the signers' accounts, or else the next account in round-robin order for a new transaction, or
+// else the configured signer's account.
+func (f *Factory) accountFor(callMsg *ethereum.CallMsg, isNew bool) common.Address {
+	if _, ok := f.signers[callMsg.From]; ok || !isNew {
+		return f.account(callMsg.From)
+	}
+	next := f.nextAccount.Add(1) - 1
+	return f.accounts[next%uint64(len(f.accounts))]
+}
+
 func (f *Factory) SetClient(ethClient eth.Client) {
 	f.ethClient = ethClient
 }
 
-// BuildTransactionFromRequests builds a transaction from a list of requests.
+// SetNonceManager sets the nonce manager to acquire fresh nonces of the signer from, instead of
+// the noncer, so that transactions can be built concurrently without colliding on a nonce.
+func (f *Factory) SetNonceManager(nonceManager NonceManager) {
+	f.nonceManager = nonceManager
+}
+
+// SetSimulateBeforeSend sets whether transactions are simulated (with an eth_call against the
+// pending state) as they are built. If enabled, building a transaction that would revert fails
+// with a RevertError, so that it is never broadcast.
+func (f *Factory) SetSimulateBeforeSend(simulate bool) {
+	f.simulate = simulate
+}
+
+// SetCreateAccessLists sets whether access lists are created (with eth_createAccessList against
+// the pending state) for the requests without one as transactions are built, to reduce the gas
+// used by calls that access other contracts' storage. The estimated gas limit then covers the gas
+// used with the access list.
+func (f *Factory) SetCreateAccessLists(create bool) {
+	f.accessLists = create
+}
+
+// BuildTransactionFromRequests builds a transaction from a list of requests. A batched transaction
+// carries the access lists of all of its requests.
 func (f *Factory) BuildTransactionFromRequests(
 	ctx context.Context, requests ...*ethereum.CallMsg,
 ) (*coretypes.Transaction, error) {
@@ -56,8 +136,14 @@ func (f *Factory) BuildTransactionFromRequests(
 		// if len(txReqs) == 1 then build a single transaction.
 		return f.buildTransaction(ctx, requests[0], 0)
 	default:
-		// len(txReqs) > 1 then build a multicall transaction.
+		// len(txReqs) > 1 then build a multicall transaction, which calls each request's target.
+		for _, request := range requests {
+			if request.To == nil {
+				return nil, ErrBatchContractCreation
+			}
+		}
 		ar := f.batcher.BatchRequests(requests...)
+		ar.AccessList = mergeAccessLists(requests)
 
 		// Build the transaction to include the calldata.
 		// ar.To should be the Multicall3 contract address
@@ -74,41 +160,58 @@ func (f *Factory) RebuildTransactionFromRequest(
 	return f.buildTransaction(ctx, request, forcedNonce)
 }
 
-// buildTransaction builds a transaction with the configured signer. If nonce of 0 is provided,
-// a fresh nonce is acquired from the noncer.
+// buildTransaction builds a transaction with the signer of the account selected for the request
+// (see accountFor). If nonce of 0 is provided, a fresh nonce is acquired from the nonce manager if
+// set, or the noncer otherwise.
 func (f *Factory) buildTransaction(
 	ctx context.Context, callMsg *ethereum.CallMsg, nonce uint64,
-) (*coretypes.Transaction, error) {
-	var err error
-
+) (_ *coretypes.Transaction, err error) {
 	// get the chain ID
-	if f.chainID == nil {
-		f.chainID, err = f.ethClient.ChainID(ctx)
-		if err != nil {
-			return nil, err
-		}
+	chainID, err := f.getChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The noncer only tracks the nonces of the configured signer's account.
+	if nonce == 0 && f.nonceManager == nil && len(f.accounts) > 1 {
+		return nil, ErrNoNonceManager
 	}
 
-	// get the nonce from the noncer if not provided
+	// get the nonce of the account from the noncer if not provided
+	from := f.accountFor(callMsg, nonce == 0)
 	var isReplacing bool
 	if nonce == 0 {
-		nonce, isReplacing = f.noncer.Acquire()
+		if f.nonceManager != nil {
+			if nonce, err = f.nonceManager.Acquire(ctx, from); err != nil {
+				return nil, err
+			}
+			// Release the nonce if the transaction fails to build, so that it's reused.
+			defer func(nonce uint64) {
+				if err != nil {
+					f.nonceManager.Release(from, nonce)
+				}
+			}(nonce)
+		} else {
+			nonce, isReplacing = f.noncer.Acquire()
+		}
 	}
 
 	// start building the 1559 transaction
 	txData := &coretypes.DynamicFeeTx{
-		ChainID: f.chainID,
-		To:      callMsg.To,
-		Value:   callMsg.Value,
-		Data:    callMsg.Data,
-		Nonce:   nonce,
+		ChainID:    chainID,
+		To:         callMsg.To,
+		Value:      callMsg.Value,
+		Data:       callMsg.Data,
+		Nonce:      nonce,
+		AccessList: callMsg.AccessList,
 	}
 
-	// set gas tip cap from eth client if not already provided
+	// set gas tip cap from the estimated tip if not already provided (e.g. by a replacement,
+	// which bumps the tip that was estimated for the replaced tx)
 	if callMsg.GasTipCap != nil {
 		txData.GasTipCap = callMsg.GasTipCap
 	} else {
-		txData.GasTipCap, err = f.ethClient.SuggestGasTipCap(ctx)
+		txData.GasTipCap, err = f.estimateGasTip(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -130,14 +233,30 @@ func (f *Factory) buildTransaction(
 		)
 	}
 
+	// simulate the transaction (if enabled) before estimating its gas limit
+	if f.simulate {
+		if err = f.simulateTransaction(ctx, from, callMsg, txData); err != nil {
+			return nil, err
+		}
+	}
+
+	// create the access list (if enabled and not already provided)
+	var accessListGas uint64
+	if f.accessLists && len(txData.AccessList) == 0 {
+		if accessListGas, err = f.createAccessList(ctx, from, txData); err != nil {
+			return nil, err
+		}
+	}
+
 	// set gas limit from eth client if not already provided
 	if callMsg.Gas > 0 {
 		txData.Gas = callMsg.Gas
 	} else {
-		callMsg.From = f.signer.Address() // set the from address for estimate gas
+		callMsg.From = from // set the from address for estimate gas
 		if txData.Gas, err = f.ethClient.EstimateGas(ctx, *callMsg); err != nil {
 			return nil, err
 		}
+		txData.Gas = max(txData.Gas, accessListGas)
 	}
 
 	// bump gas (if necessary)
@@ -146,12 +265,116 @@ func (f *Factory) buildTransaction(
 		tx = sender.BumpGas(tx)
 	}
 
-	// sign the transaction
+	return f.SignTransaction(ctx, from, tx)
+}
+
+// getChainID returns the chain ID, fetching it on first use.
+func (f *Factory) getChainID(ctx context.Context) (*big.Int, error) {
+	f.chainIDMu.Lock()
+	defer f.chainIDMu.Unlock()
+
+	if f.chainID == nil {
+		chainID, err := f.ethClient.ChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		f.chainID = chainID
+	}
+	return f.chainID, nil
+}
+
+// simulateTransaction calls the transaction, as sent from the given account, against the pending
+// state. Returns a RevertError if the call reverts.
+func (f *Factory) simulateTransaction(
+	ctx context.Context, from common.Address, callMsg *ethereum.CallMsg,
+	txData *coretypes.DynamicFeeTx,
+) error {
+	if _, err := f.ethClient.CallContract(ctx, ethereum.CallMsg{
+		From:      from,
+		To:        txData.To,
+		Gas:       callMsg.Gas,
+		GasFeeCap: txData.GasFeeCap,
+		GasTipCap: txData.GasTipCap,
+		Value:     txData.Value,
+		Data:      txData.Data,
+	}, big.NewInt(int64(rpc.PendingBlockNumber))); err != nil {
+		return asRevertError(err)
+	}
+	return nil
+}
+
+// createAccessList sets the access list of the transaction, as created by the node for the call
+// sent from the given account. Returns the gas used by the call with the access list.
+func (f *Factory) createAccessList(
+	ctx context.Context, from common.Address, txData *coretypes.DynamicFeeTx,
+) (uint64, error) {
+	accessList, gasUsed, err := f.ethClient.CreateAccessList(ctx, ethereum.CallMsg{
+		From:      from,
+		To:        txData.To,
+		GasFeeCap: txData.GasFeeCap,
+		GasTipCap: txData.GasTipCap,
+		Value:     txData.Value,
+		Data:      txData.Data,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if accessList != nil {
+		txData.AccessList = *accessList
+	}
+	return gasUsed, nil
+}
+
+// mergeAccessLists returns the union of the access lists of the requests, in order of first
+// access, or nil if none of them has one.
+func mergeAccessLists(requests []*ethereum.CallMsg) coretypes.AccessList {
+	var (
+		merged  coretypes.AccessList
+		indexes = make(map[common.Address]int)
+		keys    = make(map[common.Address]map[common.Hash]struct{})
+	)
+	for _, request := range requests {
+		for _, tuple := range request.AccessList {
+			i, ok := indexes[tuple.Address]
+			if !ok {
+				i = len(merged)
+				indexes[tuple.Address] = i
+				keys[tuple.Address] = make(map[common.Hash]struct{})
+				merged = append(merged, coretypes.AccessTuple{
+					Address: tuple.Address, StorageKeys: []common.Hash{},
+				})
+			}
+			for _, key := range tuple.StorageKeys {
+				if _, seen := keys[tuple.Address][key]; !seen {
+					keys[tuple.Address][key] = struct{}{}
+					merged[i].StorageKeys = append(merged[i].StorageKeys, key)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// EstimateGas estimates the gas limit of the request, as sent from the request's From if it's one
+// of the signers' accounts, or else the configured signer's account.
+func (f *Factory) EstimateGas(ctx context.Context, callMsg *ethereum.CallMsg) (uint64, error) {
+	callMsg.From = f.account(callMsg.From) // set the from address for estimate gas
+	return f.ethClient.EstimateGas(ctx, *callMsg)
+}
+
+// SignTransaction signs the given transaction as-is with the signer of the given account, or the
+// configured signer if the account isn't one of the signers' (e.g. the zero address).
+func (f *Factory) SignTransaction(
+	ctx context.Context, from common.Address, tx *coretypes.Transaction,
+) (*coretypes.Transaction, error) {
+	from = f.account(from)
+
+	// The signer func may sign within the context (e.g. remotely), so the timeout covers both.
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, f.signTxTimeout)
-	signer, err := f.signer.SignerFunc(ctxWithTimeout, tx.ChainId())
-	cancel()
+	defer cancel()
+	signer, err := f.signers[from].SignerFunc(ctxWithTimeout, tx.ChainId())
 	if err != nil {
 		return nil, err
 	}
-	return signer(f.signerAddress, tx)
+	return signer(from, tx)
 }
@@ -0,0 +1,373 @@
+package factory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/client/eth"
+	"github.com/berachain/offchain-sdk/core/transactor/sender"
+	"github.com/berachain/offchain-sdk/core/transactor/tracker"
+	"github.com/berachain/offchain-sdk/core/transactor/types"
+	"github.com/berachain/offchain-sdk/types/kms/local"
+	"github.com/berachain/offchain-sdk/types/kms/remote"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// mockClient is an eth.Client that only implements the calls needed to build transactions.
+type mockClient struct {
+	eth.Client
+	pendingNonce uint64
+
+	callErr error              // returned by CallContract
+	calls   []ethereum.CallMsg // calls made with CallContract
+
+	accessList      coretypes.AccessList // returned by CreateAccessList
+	accessListCalls []ethereum.CallMsg   // calls made with CreateAccessList
+}
+
+func (*mockClient) ChainID(context.Context) (*big.Int, error) { return big.NewInt(1), nil }
+
+func (*mockClient) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (*mockClient) HeaderByNumber(context.Context, *big.Int) (*coretypes.Header, error) {
+	return &coretypes.Header{BaseFee: big.NewInt(1)}, nil
+}
+
+func (c *mockClient) CallContract(
+	_ context.Context, msg ethereum.CallMsg, blockNumber *big.Int,
+) ([]byte, error) {
+	if blockNumber.Int64() != int64(rpc.PendingBlockNumber) {
+		return nil, errors.New("not called against the pending state")
+	}
+	c.calls = append(c.calls, msg)
+	return nil, c.callErr
+}
+
+func (c *mockClient) CreateAccessList(
+	_ context.Context, msg ethereum.CallMsg,
+) (*coretypes.AccessList, uint64, error) {
+	c.accessListCalls = append(c.accessListCalls, msg)
+	return &c.accessList, 30000, nil
+}
+
+func (*mockClient) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return 25000, nil
+}
+
+func (c *mockClient) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return c.pendingNonce, nil
+}
+
+// testSigner is a TxSigner with a random key.
+type testSigner struct {
+	opts *bind.TransactOpts
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1))
+	require.NoError(t, err)
+	return &testSigner{opts: opts}
+}
+
+func (s *testSigner) Address() common.Address { return s.opts.From }
+
+func (s *testSigner) SignerFunc(context.Context, *big.Int) (bind.SignerFn, error) {
+	return s.opts.Signer, nil
+}
+
+func TestBuildTransactionsConcurrently(t *testing.T) {
+	const numTxs = 50
+	var (
+		client = &mockClient{pendingNonce: 7}
+		f      = New(nil, nil, newTestSigner(t), time.Second)
+		to     = common.HexToAddress("0x1")
+		nonces = make([]uint64, numTxs)
+		wg     sync.WaitGroup
+	)
+	f.SetClient(client)
+	f.SetNonceManager(tracker.NewNonceManager(client))
+
+	for i := 0; i < numTxs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx, err := f.BuildTransactionFromRequests(
+				context.Background(), &ethereum.CallMsg{To: &to, Gas: 21000},
+			)
+			require.NoError(t, err)
+			nonces[i] = tx.Nonce()
+		}(i)
+	}
+	wg.Wait()
+
+	// The nonces are unique and gap-free, starting at the pending nonce.
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for i, nonce := range nonces {
+		require.EqualValues(t, 7+i, nonce)
+	}
+}
+
+func TestBuildTransactionsWithMultipleSigners(t *testing.T) {
+	var (
+		client  = &mockClient{pendingNonce: 7}
+		signers = []*testSigner{newTestSigner(t), newTestSigner(t), newTestSigner(t)}
+		f       = New(nil, nil, signers[0], time.Second)
+		to      = common.HexToAddress("0x1")
+		nonces  = make(map[common.Address][]uint64)
+	)
+	f.SetClient(client)
+	f.AddSigners(signers[1], signers[2], signers[1])
+
+	// A nonce manager is required to track the nonces of each account.
+	_, err := f.BuildTransactionFromRequests(
+		context.Background(), &ethereum.CallMsg{To: &to, Gas: 21000},
+	)
+	require.ErrorIs(t, err, ErrNoNonceManager)
+	f.SetNonceManager(tracker.NewNonceManager(client))
+
+	// New txs are built from the accounts in round-robin order.
+	for i := 0; i < 6; i++ {
+		tx, buildErr := f.BuildTransactionFromRequests(
+			context.Background(), &ethereum.CallMsg{To: &to, Gas: 21000},
+		)
+		require.NoError(t, buildErr)
+		from, fromErr := f.From(tx)
+		require.NoError(t, fromErr)
+		require.Equal(t, signers[i%3].Address(), from)
+		nonces[from] = append(nonces[from], tx.Nonce())
+	}
+
+	// Each account has its own nonces.
+	for _, signer := range signers {
+		require.Equal(t, []uint64{7, 8}, nonces[signer.Address()])
+	}
+
+	// Rebuilt txs keep the account of the request, as do txs signed as-is.
+	tx, err := f.RebuildTransactionFromRequest(
+		context.Background(), &ethereum.CallMsg{From: signers[2].Address(), To: &to, Gas: 21000},
+		8,
+	)
+	require.NoError(t, err)
+	from, err := f.From(tx)
+	require.NoError(t, err)
+	require.Equal(t, signers[2].Address(), from)
+
+	tx, err = f.SignTransaction(context.Background(), signers[1].Address(), tx)
+	require.NoError(t, err)
+	from, err = f.From(tx)
+	require.NoError(t, err)
+	require.Equal(t, signers[1].Address(), from)
+}
+
+func TestBuildTransactionWithRemoteSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	localSigner := local.NewSigner(key)
+
+	// The mock remote signer signs the requested tx with the local key, as a KMS/HSM would.
+	var requests int
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var req remote.SignRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		tx := new(coretypes.Transaction)
+		require.NoError(t, tx.UnmarshalBinary(req.Transaction))
+
+		signature, signErr := localSigner.SignTx(r.Context(), tx, req.ChainID.ToInt())
+		require.NoError(t, signErr)
+		signature[crypto.RecoveryIDOffset] += 27 // remote signers may use legacy V values
+		require.NoError(t, json.NewEncoder(w).Encode(&remote.SignResponse{Signature: signature}))
+	}))
+	defer svr.Close()
+
+	remoteSigner := remote.NewSigner(
+		svr.URL, localSigner.Address(), remote.WithHeader("Authorization", "Bearer secret"),
+	)
+	f := New(nil, nil, NewTxSigner(remoteSigner), time.Second)
+	f.SetClient(&mockClient{})
+	f.SetNonceManager(tracker.NewNonceManager(&mockClient{}))
+
+	to := common.HexToAddress("0x1")
+	tx, err := f.BuildTransactionFromRequests(
+		context.Background(), &ethereum.CallMsg{To: &to, Gas: 21000},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	from, err := coretypes.Sender(coretypes.LatestSignerForChainID(tx.ChainId()), tx)
+	require.NoError(t, err)
+	require.Equal(t, localSigner.Address(), from)
+
+	// A signature that doesn't recover to the signer's address is rejected.
+	otherSigner := remote.NewSigner(
+		svr.URL, common.HexToAddress("0x2"), remote.WithHeader("Authorization", "Bearer secret"),
+	)
+	_, err = otherSigner.SignTx(context.Background(), tx, tx.ChainId())
+	require.ErrorIs(t, err, remote.ErrSignatureMismatch)
+}
+
+// revertError is an RPC error for a reverted call, with the revert data.
+type revertError struct {
+	data []byte
+}
+
+func (*revertError) Error() string { return "execution reverted" }
+
+func (e *revertError) ErrorData() any { return hexutil.Encode(e.data) }
+
+// revertData returns the revert data of a standard Error(string) with the given reason.
+func revertData(t *testing.T, reason string) []byte {
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	data, err := abi.Arguments{{Type: stringType}}.Pack(reason)
+	require.NoError(t, err)
+	return append(crypto.Keccak256([]byte("Error(string)"))[:4], data...)
+}
+
+func TestBuildTransactionSimulateBeforeSend(t *testing.T) {
+	var (
+		to     = common.HexToAddress("0x1")
+		signer = newTestSigner(t)
+		client = &mockClient{}
+		f      = New(nil, nil, signer, time.Second)
+		msg    = &ethereum.CallMsg{To: &to, Gas: 21000, Data: []byte{0x1}}
+	)
+	f.SetClient(client)
+	f.SetNonceManager(tracker.NewNonceManager(client))
+
+	// Disabled by default.
+	_, err := f.BuildTransactionFromRequests(context.Background(), msg)
+	require.NoError(t, err)
+	require.Empty(t, client.calls)
+
+	// A passing simulation calls the tx as sent from the signer.
+	f.SetSimulateBeforeSend(true)
+	tx, err := f.BuildTransactionFromRequests(context.Background(), msg)
+	require.NoError(t, err)
+	require.Len(t, client.calls, 1)
+	require.Equal(t, signer.Address(), client.calls[0].From)
+	require.Equal(t, &to, client.calls[0].To)
+	require.Equal(t, tx.Data(), client.calls[0].Data)
+	require.Equal(t, tx.GasFeeCap(), client.calls[0].GasFeeCap)
+
+	// A reverting simulation fails with the decoded revert reason.
+	data := revertData(t, "insufficient balance")
+	client.callErr = &revertError{data: data}
+	_, err = f.BuildTransactionFromRequests(context.Background(), msg)
+	var revertErr *RevertError
+	require.ErrorAs(t, err, &revertErr)
+	require.Equal(t, "insufficient balance", revertErr.Reason)
+	require.Equal(t, data, revertErr.Data)
+	require.ErrorIs(t, err, client.callErr)
+
+	// Errors other than reverts are returned as-is.
+	client.callErr = errors.New("connection refused")
+	_, err = f.BuildTransactionFromRequests(context.Background(), msg)
+	require.Equal(t, client.callErr, err)
+}
+
+func TestBuildBatchedTransactionContractCreation(t *testing.T) {
+	to := common.HexToAddress("0x1")
+	f := New(nil, nil, newTestSigner(t), time.Second)
+	f.SetClient(&mockClient{})
+
+	_, err := f.BuildTransactionFromRequests(
+		context.Background(), &ethereum.CallMsg{To: &to}, &ethereum.CallMsg{Data: []byte{0x1}},
+	)
+	require.ErrorIs(t, err, ErrBatchContractCreation)
+}
+
+func TestBuildTransactionWithAccessList(t *testing.T) {
+	var (
+		to         = common.HexToAddress("0x1")
+		signer     = newTestSigner(t)
+		client     = &mockClient{}
+		f          = New(nil, nil, signer, time.Second)
+		accessList = coretypes.AccessList{{
+			Address: common.HexToAddress("0x2"), StorageKeys: []common.Hash{common.HexToHash("0x3")},
+		}}
+	)
+	f.SetClient(client)
+	f.SetNonceManager(tracker.NewNonceManager(client))
+
+	// The access list of the request is carried by the tx, and survives a fee bump.
+	tx, err := f.BuildTransactionFromRequests(
+		context.Background(), &ethereum.CallMsg{To: &to, Gas: 21000, AccessList: accessList},
+	)
+	require.NoError(t, err)
+	require.Equal(t, coretypes.DynamicFeeTxType, int(tx.Type()))
+	require.Equal(t, accessList, tx.AccessList())
+	bumped := sender.BumpGas(tx)
+	require.Equal(t, accessList, bumped.AccessList())
+	require.Equal(t, 1, bumped.GasTipCap().Cmp(tx.GasTipCap()))
+	rebuilt, err := f.RebuildTransactionFromRequest(
+		context.Background(), types.CallMsgFromTx(bumped), bumped.Nonce(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, accessList, rebuilt.AccessList())
+	require.Equal(t, bumped.GasTipCap(), rebuilt.GasTipCap())
+
+	// Access lists are only created for requests without one if enabled, covering the gas used.
+	tx, err = f.BuildTransactionFromRequests(context.Background(), &ethereum.CallMsg{To: &to})
+	require.NoError(t, err)
+	require.Empty(t, tx.AccessList())
+	require.Empty(t, client.accessListCalls)
+
+	f.SetCreateAccessLists(true)
+	client.accessList = coretypes.AccessList{{Address: common.HexToAddress("0x4")}}
+	tx, err = f.BuildTransactionFromRequests(context.Background(), &ethereum.CallMsg{To: &to})
+	require.NoError(t, err)
+	require.Equal(t, client.accessList, tx.AccessList())
+	require.Equal(t, uint64(30000), tx.Gas())
+	require.Len(t, client.accessListCalls, 1)
+	require.Equal(t, signer.Address(), client.accessListCalls[0].From)
+
+	tx, err = f.BuildTransactionFromRequests(
+		context.Background(), &ethereum.CallMsg{To: &to, Gas: 21000, AccessList: accessList},
+	)
+	require.NoError(t, err)
+	require.Equal(t, accessList, tx.AccessList())
+	require.Len(t, client.accessListCalls, 1)
+}
+
+func TestMergeAccessLists(t *testing.T) {
+	var (
+		a, b       = common.HexToAddress("0xa"), common.HexToAddress("0xb")
+		k1, k2, k3 = common.HexToHash("0x1"), common.HexToHash("0x2"), common.HexToHash("0x3")
+	)
+	require.Nil(t, mergeAccessLists([]*ethereum.CallMsg{{}, {}}))
+
+	merged := mergeAccessLists([]*ethereum.CallMsg{
+		{AccessList: coretypes.AccessList{{Address: a, StorageKeys: []common.Hash{k1, k2}}}},
+		{},
+		{AccessList: coretypes.AccessList{
+			{Address: b}, {Address: a, StorageKeys: []common.Hash{k2, k3}},
+		}},
+	})
+	require.Equal(t, coretypes.AccessList{
+		{Address: a, StorageKeys: []common.Hash{k1, k2, k3}},
+		{Address: b, StorageKeys: []common.Hash{}},
+	}, merged)
+}
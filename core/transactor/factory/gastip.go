@@ -0,0 +1,117 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// defaultFeeHistoryBlocks is the default number of recent blocks whose tips are used to
+	// estimate the gas tip, if eth_maxPriorityFeePerGas is unsupported.
+	defaultFeeHistoryBlocks = 20
+	// defaultFeeHistoryPercentile is the default percentile of the tips in each recent block used
+	// to estimate the gas tip, if eth_maxPriorityFeePerGas is unsupported.
+	defaultFeeHistoryPercentile = 60
+	// methodNotFoundCode is the JSON-RPC error code for an unsupported method.
+	methodNotFoundCode = -32601
+)
+
+// errNoFeeHistory is returned when the fee history has no tips to estimate the gas tip from.
+var errNoFeeHistory = errors.New("no fee history to estimate the gas tip from")
+
+// GasTipConfig configures the estimation of the gas tip of built transactions, which is used as
+// the base tip that replacements bump from. The tip is suggested by the node with
+// eth_maxPriorityFeePerGas or, if unsupported, estimated from the fee history of recent blocks.
+type GasTipConfig struct {
+	// Percentage of the estimated tip to use as the tip; if 0, defaults to 100% (i.e. the tip is
+	// used as estimated).
+	MultiplierPercent int
+	// Minimum tip (in wei) to use; if 0, there is no minimum.
+	Floor uint64
+	// Number of recent blocks to estimate the tip from, if eth_maxPriorityFeePerGas is
+	// unsupported; if 0, defaults to 20.
+	FeeHistoryBlocks uint64
+	// Percentile of the tips in each recent block to estimate the tip from, if
+	// eth_maxPriorityFeePerGas is unsupported; if 0, defaults to 60.
+	FeeHistoryPercentile float64
+}
+
+// SetGasTipConfig sets how the gas tip of built transactions is estimated, if not provided by the
+// request.
+func (f *Factory) SetGasTipConfig(cfg GasTipConfig) {
+	f.gasTip = cfg
+}
+
+// estimateGasTip returns the gas tip to use for a transaction, as suggested by the node (or
+// estimated from the fee history) and adjusted by the multiplier and floor.
+func (f *Factory) estimateGasTip(ctx context.Context) (*big.Int, error) {
+	tip, err := f.ethClient.SuggestGasTipCap(ctx)
+	if isMethodNotFound(err) {
+		tip, err = f.feeHistoryGasTip(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The suggested tip may be shared by the client (e.g. cached), so it's never modified.
+	if f.gasTip.MultiplierPercent > 0 {
+		tip = new(big.Int).Mul(tip, big.NewInt(int64(f.gasTip.MultiplierPercent)))
+		tip.Quo(tip, big.NewInt(100)) //nolint:gomnd // percentage.
+	}
+	if floor := new(big.Int).SetUint64(f.gasTip.Floor); tip.Cmp(floor) < 0 {
+		tip = floor
+	}
+	return tip, nil
+}
+
+// feeHistoryGasTip estimates the gas tip as the median, across recent blocks, of the configured
+// percentile of the tips in each block.
+func (f *Factory) feeHistoryGasTip(ctx context.Context) (*big.Int, error) {
+	var (
+		blocks     = uint64(defaultFeeHistoryBlocks)
+		percentile = float64(defaultFeeHistoryPercentile)
+	)
+	if f.gasTip.FeeHistoryBlocks > 0 {
+		blocks = f.gasTip.FeeHistoryBlocks
+	}
+	if f.gasTip.FeeHistoryPercentile > 0 {
+		percentile = f.gasTip.FeeHistoryPercentile
+	}
+
+	history, err := f.ethClient.FeeHistory(ctx, blocks, nil, []float64{percentile})
+	if err != nil {
+		return nil, err
+	}
+
+	tips := make([]*big.Int, 0, len(history.Reward))
+	for _, rewards := range history.Reward {
+		if len(rewards) > 0 && rewards[0] != nil {
+			tips = append(tips, rewards[0])
+		}
+	}
+	if len(tips) == 0 {
+		return nil, errNoFeeHistory
+	}
+	sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+	return new(big.Int).Set(tips[len(tips)/2]), nil
+}
+
+// isMethodNotFound returns true if the error is the node not supporting the called method.
+func isMethodNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "method not found") ||
+		strings.Contains(msg, "does not exist/is not available") ||
+		strings.Contains(msg, "not supported")
+}
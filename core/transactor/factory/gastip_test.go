@@ -0,0 +1,120 @@
+package factory
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// tipClient is a mockClient that suggests a known gas tip (returning the same value each time, as a
+// caching client would), or serves the fee history if eth_maxPriorityFeePerGas is unsupported.
+type tipClient struct {
+	mockClient
+	tip        *big.Int // if nil, eth_maxPriorityFeePerGas is unsupported
+	feeHistory *ethereum.FeeHistory
+}
+
+func (c *tipClient) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	if c.tip == nil {
+		return nil, &methodNotFoundError{}
+	}
+	return c.tip, nil
+}
+
+func (c *tipClient) FeeHistory(
+	_ context.Context, _ uint64, _ *big.Int, percentiles []float64,
+) (*ethereum.FeeHistory, error) {
+	if len(percentiles) != 1 || percentiles[0] != defaultFeeHistoryPercentile {
+		return nil, rpc.ErrNoResult
+	}
+	return c.feeHistory, nil
+}
+
+// methodNotFoundError is the RPC error for an unsupported method.
+type methodNotFoundError struct{}
+
+func (*methodNotFoundError) Error() string  { return "the method does not exist" }
+func (*methodNotFoundError) ErrorCode() int { return methodNotFoundCode }
+
+// noncer is a Noncer that always acquires the same nonce.
+type noncer struct {
+	nonce       uint64
+	isReplacing bool
+}
+
+func (n *noncer) Acquire() (uint64, bool) { return n.nonce, n.isReplacing }
+
+func TestEstimateGasTip(t *testing.T) {
+	tests := []struct {
+		name     string
+		tip      *big.Int
+		rewards  [][]*big.Int
+		cfg      GasTipConfig
+		expected *big.Int
+	}{
+		{"suggested", big.NewInt(1000), nil, GasTipConfig{}, big.NewInt(1000)},
+		{
+			"multiplier", big.NewInt(1000), nil,
+			GasTipConfig{MultiplierPercent: 150}, big.NewInt(1500),
+		},
+		{"floor", big.NewInt(1000), nil, GasTipConfig{Floor: 2000}, big.NewInt(2000)},
+		{
+			"fee history median", nil,
+			[][]*big.Int{{big.NewInt(300)}, {big.NewInt(100)}, {big.NewInt(200)}, {}},
+			GasTipConfig{}, big.NewInt(200),
+		},
+		{
+			"fee history with multiplier and floor", nil,
+			[][]*big.Int{{big.NewInt(100)}}, GasTipConfig{MultiplierPercent: 200, Floor: 150},
+			big.NewInt(200),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(&noncer{}, nil, newTestSigner(t), time.Second)
+			f.SetClient(&tipClient{tip: tt.tip, feeHistory: &ethereum.FeeHistory{Reward: tt.rewards}})
+			f.SetGasTipConfig(tt.cfg)
+
+			// The estimate is the same on each build, without compounding the multiplier.
+			to := common.HexToAddress("0x1")
+			for i := 0; i < 2; i++ {
+				tx, err := f.BuildTransactionFromRequests(
+					context.Background(), &ethereum.CallMsg{To: &to, Gas: 21000},
+				)
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, tx.GasTipCap())
+			}
+		})
+	}
+}
+
+func TestEstimateGasTipErrors(t *testing.T) {
+	f := New(&noncer{}, nil, newTestSigner(t), time.Second)
+	f.SetClient(&tipClient{feeHistory: &ethereum.FeeHistory{}})
+
+	to := common.HexToAddress("0x1")
+	_, err := f.BuildTransactionFromRequests(
+		context.Background(), &ethereum.CallMsg{To: &to, Gas: 21000},
+	)
+	require.ErrorIs(t, err, errNoFeeHistory)
+}
+
+func TestReplacementBumpsEstimatedGasTip(t *testing.T) {
+	f := New(&noncer{isReplacing: true}, nil, newTestSigner(t), time.Second)
+	f.SetClient(&tipClient{tip: big.NewInt(1000)})
+
+	// The replacement bumps the estimated tip by 15%, rather than a static tip.
+	to := common.HexToAddress("0x1")
+	tx, err := f.BuildTransactionFromRequests(
+		context.Background(), &ethereum.CallMsg{To: &to, Gas: 21000},
+	)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1150), tx.GasTipCap())
+}
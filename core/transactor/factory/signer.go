@@ -0,0 +1,49 @@
+package factory
+
+import (
+	"context"
+	"math/big"
+
+	kmstypes "github.com/berachain/offchain-sdk/types/kms/types"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Ensure `txSigner` implements the TxSigner interface.
+var _ kmstypes.TxSigner = (*txSigner)(nil)
+
+// txSigner is a TxSigner that delegates the signing step to a Signer, e.g. a local key signer
+// or a remote signer that calls out to an external KMS/HSM.
+type txSigner struct {
+	signer kmstypes.Signer
+}
+
+// NewTxSigner returns a TxSigner, as used by the Factory, that signs transactions with the given
+// Signer.
+func NewTxSigner(signer kmstypes.Signer) kmstypes.TxSigner {
+	return &txSigner{signer: signer}
+}
+
+// Address returns the address of the Signer.
+func (s *txSigner) Address() common.Address {
+	return s.signer.Address()
+}
+
+// SignerFunc returns a function that signs transactions for the chain ID with the Signer, within
+// the given context.
+func (s *txSigner) SignerFunc(ctx context.Context, chainID *big.Int) (bind.SignerFn, error) {
+	signer := coretypes.LatestSignerForChainID(chainID)
+	return func(addr common.Address, tx *coretypes.Transaction) (*coretypes.Transaction, error) {
+		if addr != s.Address() {
+			return nil, bind.ErrNotAuthorized
+		}
+
+		signature, err := s.signer.SignTx(ctx, tx, chainID)
+		if err != nil {
+			return nil, err
+		}
+		return tx.WithSignature(signer, signature)
+	}, nil
+}
@@ -14,6 +14,13 @@ type Noncer interface {
 	Acquire() (uint64, bool)
 }
 
+// NonceManager is an interface for acquiring fresh nonces of any account, which never collide
+// when acquired concurrently, and releasing the acquired nonces that end up unused.
+type NonceManager interface {
+	Acquire(ctx context.Context, account common.Address) (uint64, error)
+	Release(account common.Address, nonce uint64)
+}
+
 // Batcher is an interface for batching requests, commonly implemented by multicallers.
 type Batcher interface {
 	// BatchRequests creates a batched transaction request for the given call requests.
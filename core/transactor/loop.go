@@ -2,8 +2,10 @@ package transactor
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/berachain/offchain-sdk/core/transactor/sender"
 	"github.com/berachain/offchain-sdk/core/transactor/tracker"
 	"github.com/berachain/offchain-sdk/core/transactor/types"
 
@@ -26,13 +28,18 @@ func (t *TxrV2) mainLoop(ctx context.Context) {
 				continue
 			}
 
-			// We got a batch, so we can build and fire, after the previous fire has finished.
+			// We got a batch, so we can build and fire, after the previous fire has finished. The
+			// tx is retried as overridden by the requests, if any of them does.
+			fireCtx := ctx
+			if overrides := requests.RetryOverrides(); overrides != nil {
+				fireCtx = sender.WithRetryOverrides(ctx, overrides)
+			}
 			t.senderMu.Lock()
 			go func() {
 				defer t.senderMu.Unlock()
 
 				t.fire(
-					ctx,
+					fireCtx,
 					&tracker.Response{MsgIDs: requests.MsgIDs(), InitialTimes: requests.Times()},
 					true, requests.Messages()...,
 				)
@@ -111,10 +118,23 @@ func (t *TxrV2) fire(
 
 	// Call the sender to send the transaction to the chain.
 	t.markState(types.StateSending, resp.MsgIDs...)
-	if resp.Error = t.sender.SendTransaction(ctx, resp.Transaction); resp.Error != nil {
+	sentTx, err := t.sender.Send(ctx, resp.Transaction, resp.MsgIDs)
+	if resp.Error = err; resp.Error != nil {
+		// Release the nonces of the built tx and of its last replacement (if the sender replaced
+		// its nonce), so that they're reused.
+		if toBuild {
+			t.releaseNonce(resp.Transaction, resp.Nonce())
+			var sendErr *sender.SendError
+			if errors.As(err, &sendErr) && sendErr.Nonce != resp.Nonce() {
+				t.releaseNonce(resp.Transaction, sendErr.Nonce)
+			}
+		}
 		return
 	}
-	t.logger.Debug("📡 sent transaction", "hash", resp.Hash().Hex(), "reqs", len(resp.MsgIDs))
+	t.logger.Debug("📡 sent transaction", "hash", sentTx.Hash().Hex(), "reqs", len(resp.MsgIDs))
+
+	// Track the tx that was last sent, since the sender may have replaced the built tx.
+	resp.Transaction = sentTx
 
 	// Call the tracker to track the transaction async.
 	t.markState(types.StateInFlight, resp.MsgIDs...)
@@ -0,0 +1,171 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/client/eth/ethmock"
+	"github.com/berachain/offchain-sdk/core/transactor/event"
+	"github.com/berachain/offchain-sdk/core/transactor/factory"
+	"github.com/berachain/offchain-sdk/core/transactor/sender"
+	"github.com/berachain/offchain-sdk/core/transactor/tracker"
+	"github.com/berachain/offchain-sdk/core/transactor/types"
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/berachain/offchain-sdk/types/kms/local"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestTransactor returns a transactor with its components set up on the mock client, whose
+// tracked responses are dispatched to the returned channel.
+func newTestTransactor(t *testing.T, client *ethmock.Client) (*TxrV2, chan *tracker.Response) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := factory.NewTxSigner(local.NewSigner(key))
+
+	noncer := tracker.NewNoncer(signer.Address(), time.Minute)
+	nonceManager := tracker.NewNonceManager(client)
+	txFactory := factory.New(noncer, nil, signer, time.Second)
+	txFactory.SetClient(client)
+	txFactory.SetNonceManager(nonceManager)
+	dispatcher := event.NewDispatcher[*tracker.Response]()
+	txTracker := tracker.New(noncer, dispatcher, signer.Address(), time.Minute, time.Minute)
+	txTracker.SetClient(client)
+	txSender := sender.New(txFactory, nonceManager.Reconciling(signer.Address()))
+	txSender.SetRetryPolicy(sender.NewLinearRetryPolicy(3, time.Millisecond, time.Millisecond))
+	txSender.Setup(client, log.NewBlankLogger(io.Discard))
+
+	responses := make(chan *tracker.Response, 1)
+	dispatcher.Subscribe(responses)
+	return &TxrV2{
+		logger:             log.NewBlankLogger(io.Discard),
+		signerAddr:         signer.Address(),
+		factory:            txFactory,
+		noncer:             noncer,
+		nonceManager:       nonceManager,
+		sender:             txSender,
+		dispatcher:         dispatcher,
+		tracker:            txTracker,
+		preconfirmedStates: make(map[string]types.PreconfirmedState),
+	}, responses
+}
+
+func TestFireTracksReplacement(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := ethmock.New()
+	client.SetGasTipCap(big.NewInt(1e9))
+	txr, responses := newTestTransactor(t, client)
+
+	// The built tx is underpriced, so the sender replaces it with bumped gas.
+	client.QueueError(ethmock.MethodSendTransaction, txpool.ErrReplaceUnderpriced)
+	to := common.HexToAddress("0x2")
+	txr.fire(
+		ctx, &tracker.Response{MsgIDs: []string{"a"}}, true,
+		&ethereum.CallMsg{To: &to, Value: big.NewInt(0), Gas: 21000},
+	)
+	sent := client.SentTransactions()
+	require.Len(t, sent, 1)
+	require.Equal(t, 1, client.Calls(ethmock.MethodSendTransaction)-len(sent))
+	require.Equal(t, types.StateInFlight, txr.GetPreconfirmedState("a"))
+
+	// The replacement lands, which is tracked to success.
+	client.SetReceipt(&coretypes.Receipt{
+		TxHash: sent[0].Hash(), Status: coretypes.ReceiptStatusSuccessful,
+		BlockNumber: big.NewInt(1),
+	})
+	select {
+	case resp := <-responses:
+		require.Equal(t, tracker.StatusSuccess, resp.Status())
+		require.Equal(t, sent[0].Hash(), resp.Hash())
+		require.Equal(t, []string{"a"}, resp.MsgIDs)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "replacement not tracked")
+	}
+}
+
+func TestFireReleasesNonceOnFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := ethmock.New()
+	client.SetGasTipCap(big.NewInt(1e9))
+	txr, responses := newTestTransactor(t, client)
+	client.SetNonce(txr.signerAddr, 5)
+	to := common.HexToAddress("0x2")
+	msg := &ethereum.CallMsg{To: &to, Value: big.NewInt(0), Gas: 21000}
+
+	// The tx fails to send without being retried, so its nonce is released.
+	client.QueueError(ethmock.MethodSendTransaction, errors.New("insufficient funds"))
+	txr.fire(ctx, &tracker.Response{MsgIDs: []string{"a"}}, true, msg)
+	resp := <-responses
+	require.Equal(t, tracker.StatusError, resp.Status())
+	require.EqualValues(t, 5, resp.Nonce())
+
+	// So the next tx reuses it, leaving no gap.
+	txr.fire(ctx, &tracker.Response{MsgIDs: []string{"b"}}, true, msg)
+	sent := client.SentTransactions()
+	require.Len(t, sent, 1)
+	require.EqualValues(t, 5, sent[0].Nonce())
+}
+
+func TestOnStaleReleasesNonce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := ethmock.New()
+	client.SetGasTipCap(big.NewInt(1e9))
+	txr, _ := newTestTransactor(t, client)
+	client.SetNonce(txr.signerAddr, 5)
+	to := common.HexToAddress("0x2")
+	msg := &ethereum.CallMsg{To: &to, Value: big.NewInt(0), Gas: 21000}
+
+	resp := &tracker.Response{MsgIDs: []string{"a"}}
+	txr.fire(ctx, resp, true, msg)
+	require.EqualValues(t, 5, resp.Nonce())
+
+	// The tx is dropped, so the next tx fills the gap at its nonce.
+	require.NoError(t, txr.OnStale(ctx, resp, false))
+	txr.fire(ctx, &tracker.Response{MsgIDs: []string{"b"}}, true, msg)
+	sent := client.SentTransactions()
+	require.Len(t, sent, 2)
+	require.EqualValues(t, 5, sent[1].Nonce())
+}
+
+func TestFireNonceTooLowMultiSigner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := ethmock.New()
+	client.SetGasTipCap(big.NewInt(1e9))
+	txr, _ := newTestTransactor(t, client)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	other := factory.NewTxSigner(local.NewSigner(key))
+	txr.factory.AddSigners(other)
+	client.SetNonce(txr.signerAddr, 2)
+	client.SetNonce(other.Address(), 7)
+
+	// The tx built from the other signer's account has a nonce too low, so it's replaced with a
+	// fresh nonce of that account (not of the primary signer's).
+	client.QueueError(ethmock.MethodSendTransaction, core.ErrNonceTooLow)
+	to := common.HexToAddress("0x2")
+	txr.fire(
+		ctx, &tracker.Response{MsgIDs: []string{"a"}}, true,
+		&ethereum.CallMsg{From: other.Address(), To: &to, Value: big.NewInt(0), Gas: 21000},
+	)
+	sent := client.SentTransactions()
+	require.Len(t, sent, 1)
+	from, err := coretypes.Sender(coretypes.LatestSignerForChainID(sent[0].ChainId()), sent[0])
+	require.NoError(t, err)
+	require.Equal(t, other.Address(), from)
+	require.EqualValues(t, 8, sent[0].Nonce())
+}
@@ -0,0 +1,106 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultBreakerCooldown is the default time the circuit breaker stays open before probing.
+const defaultBreakerCooldown = 10 * time.Second
+
+// Circuit breaker states.
+const (
+	breakerClosed = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker short-circuits sends to the chain after too many consecutive failures.
+//
+// It opens after threshold consecutive failures within window (any consecutive failures count if
+// window is 0). Once cooldown has elapsed, it is half-open: a single probe send is allowed, and
+// the breaker closes if the probe succeeds or opens again if the probe fails.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu            sync.Mutex
+	state         int
+	failures      int       // consecutive failures
+	firstFailure  time.Time // time of the first of the consecutive failures
+	openedAt      time.Time
+	probeInFlight bool
+}
+
+func newCircuitBreaker(threshold int, window, cooldown time.Duration) *circuitBreaker {
+	if cooldown == 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &circuitBreaker{
+		threshold: threshold, window: window, cooldown: cooldown, now: time.Now,
+	}
+}
+
+// allow returns ErrCircuitOpen if a send must not be attempted. Otherwise, the outcome of the
+// send must be reported with success, failure or abort.
+func (cb *circuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case breakerOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return ErrCircuitOpen
+		}
+		cb.state = breakerHalfOpen
+		fallthrough
+	case breakerHalfOpen:
+		if cb.probeInFlight {
+			return ErrCircuitOpen
+		}
+		cb.probeInFlight = true
+	}
+	return nil
+}
+
+// success reports that an allowed send reached the chain, which closes the breaker.
+func (cb *circuitBreaker) success() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.state, cb.failures, cb.probeInFlight = breakerClosed, 0, false
+}
+
+// failure reports that an allowed send failed to reach the chain.
+func (cb *circuitBreaker) failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+	switch cb.state {
+	case breakerHalfOpen:
+		cb.open(now)
+	case breakerClosed:
+		if cb.failures == 0 || (cb.window > 0 && now.Sub(cb.firstFailure) > cb.window) {
+			cb.failures, cb.firstFailure = 0, now
+		}
+		if cb.failures++; cb.failures >= cb.threshold {
+			cb.open(now)
+		}
+	}
+}
+
+// abort reports that an allowed send was abandoned by its caller, so its outcome is unknown.
+func (cb *circuitBreaker) abort() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probeInFlight = false
+}
+
+// open opens the breaker at the given time. Requires cb.mu to be held.
+func (cb *circuitBreaker) open(now time.Time) {
+	cb.state, cb.openedAt, cb.failures, cb.probeInFlight = breakerOpen, now, 0, false
+}
@@ -0,0 +1,104 @@
+package sender
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	cb := newCircuitBreaker(3, time.Minute, 10*time.Second)
+	cb.now = func() time.Time { return now }
+
+	// Closed: failures outside the window don't add up.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, cb.allow())
+		cb.failure()
+	}
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, cb.allow())
+	cb.failure()
+	require.Equal(t, breakerClosed, cb.state)
+
+	// A success resets the consecutive failures.
+	require.NoError(t, cb.allow())
+	cb.success()
+	for i := 0; i < 2; i++ {
+		require.NoError(t, cb.allow())
+		cb.failure()
+	}
+	require.Equal(t, breakerClosed, cb.state)
+
+	// Open: the third consecutive failure opens the breaker until the cooldown elapses.
+	require.NoError(t, cb.allow())
+	cb.failure()
+	require.Equal(t, breakerOpen, cb.state)
+	require.ErrorIs(t, cb.allow(), ErrCircuitOpen)
+	now = now.Add(5 * time.Second)
+	require.ErrorIs(t, cb.allow(), ErrCircuitOpen)
+
+	// Half-open: only one probe is allowed, and a failed probe opens the breaker again.
+	now = now.Add(5 * time.Second)
+	require.NoError(t, cb.allow())
+	require.Equal(t, breakerHalfOpen, cb.state)
+	require.ErrorIs(t, cb.allow(), ErrCircuitOpen)
+	cb.failure()
+	require.Equal(t, breakerOpen, cb.state)
+	require.ErrorIs(t, cb.allow(), ErrCircuitOpen)
+
+	// An aborted probe lets another probe through.
+	now = now.Add(10 * time.Second)
+	require.NoError(t, cb.allow())
+	cb.abort()
+	require.NoError(t, cb.allow())
+
+	// Closed: a successful probe closes the breaker.
+	cb.success()
+	require.Equal(t, breakerClosed, cb.state)
+	require.NoError(t, cb.allow())
+}
+
+func TestSendTransactionCircuitBreaker(t *testing.T) {
+	var (
+		sends int
+		down  = true
+	)
+	s := newTestSender(
+		NewLinearRetryPolicy(0, time.Millisecond, time.Millisecond),
+		func(context.Context, *coretypes.Transaction) error {
+			sends++
+			if down {
+				return errRPCUnavailable
+			}
+			return nil
+		},
+	)
+	now := time.Unix(0, 0)
+	s.breaker = newCircuitBreaker(3, 0, time.Second)
+	s.breaker.now = func() time.Time { return now }
+
+	// The breaker opens after 3 failed attempts, which stops the retries.
+	_, err := s.SendTransaction(context.Background(), newTestTx(0), []string{"a"})
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.Equal(t, 3, sends)
+	require.False(t, s.IsSending("a"))
+
+	// Further sends fail fast.
+	_, err = s.SendTransaction(context.Background(), newTestTx(1), []string{"b"})
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.Equal(t, 3, sends)
+	require.False(t, s.IsSending("b"))
+
+	// After the cooldown, a successful probe closes the breaker.
+	now = now.Add(time.Second)
+	down = false
+	_, err = s.SendTransaction(context.Background(), newTestTx(1), nil)
+	require.NoError(t, err)
+	require.Equal(t, 4, sends)
+	require.Equal(t, breakerClosed, s.breaker.state)
+}
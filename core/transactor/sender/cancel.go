@@ -0,0 +1,177 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// sendingTx is a tx that is sending, tracked by its account and nonce so that it can be
+// cancelled.
+type sendingTx struct {
+	cancel context.CancelCauseFunc // cancels sending the tx
+	done   chan struct{}           // closed once the send is done and its msgIDs are released
+
+	mu sync.Mutex
+	tx *coretypes.Transaction // the tx last attempted, which may have replaced the original
+}
+
+// accountNonce is a nonce of an account, which at most one tx is sending at.
+type accountNonce struct {
+	from  common.Address
+	nonce uint64
+}
+
+// accountNonceOf returns the account and nonce of the tx. If the account can't be recovered (i.e.
+// the tx isn't signed), the zero address is used.
+func accountNonceOf(tx *coretypes.Transaction) accountNonce {
+	from, _ := txFrom(tx)
+	return accountNonce{from: from, nonce: tx.Nonce()}
+}
+
+// trackTx tracks the tx as sending at its account and nonce, until untrackTx is called.
+func (s *Sender) trackTx(st *sendingTx, tx *coretypes.Transaction) {
+	st.tx = tx
+	s.sendingNonces.Store(accountNonceOf(tx), st)
+}
+
+// untrackTx stops tracking the sending tx (if tracked) and marks its send as done.
+func (s *Sender) untrackTx(st *sendingTx) {
+	st.mu.Lock()
+	if st.tx != nil {
+		s.sendingNonces.CompareAndDelete(accountNonceOf(st.tx), st)
+	}
+	st.mu.Unlock()
+	st.cancel(nil)
+	close(st.done)
+}
+
+// updateTrackedTx updates the tracked sending tx from oldTx to its replacement newTx, which may
+// be at a different nonce. A no-op if oldTx isn't tracked.
+func (s *Sender) updateTrackedTx(oldTx, newTx *coretypes.Transaction) {
+	oldKey := accountNonceOf(oldTx)
+	v, ok := s.sendingNonces.Load(oldKey)
+	if !ok {
+		return
+	}
+	st := v.(*sendingTx)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.tx != oldTx {
+		return
+	}
+	st.tx = newTx
+	if newKey := accountNonceOf(newTx); newKey != oldKey &&
+		s.sendingNonces.CompareAndDelete(oldKey, st) {
+		s.sendingNonces.Store(newKey, st)
+	}
+}
+
+// cancellationKey is the context key of the tx cancelled by a send.
+type cancellationKey struct{}
+
+// withCancellation returns a copy of the context for sending the cancellation of the given tx.
+func withCancellation(ctx context.Context, tx *coretypes.Transaction) context.Context {
+	return context.WithValue(ctx, cancellationKey{}, tx)
+}
+
+// cancelledTx returns the tx cancelled by the send made with the context, or nil if the send
+// isn't a cancellation.
+func cancelledTx(ctx context.Context) *coretypes.Transaction {
+	tx, _ := ctx.Value(cancellationKey{}).(*coretypes.Transaction)
+	return tx
+}
+
+// CancelTransaction cancels the tx sending from the given account at the given nonce by replacing
+// it with a 0 value tx to its sender, which is sent (and retried) with Send like any other tx, so
+// that it can itself be cancelled. The original send is stopped first, which then fails with
+// ErrTxCancelled and releases its message IDs. The cancellation pays bumped gas over the tx last
+// attempted, as required to replace it, and never changes its nonce: if the nonce was already
+// used (e.g. the original tx was mined meanwhile, see Receipt), ErrNothingToCancel is returned.
+// Returns the hash of the cancellation tx that was last successfully broadcast, or ErrNotSending
+// if no tx is sending from the account at the nonce. Must not be called from a hook of the send
+// it cancels.
+func (s *Sender) CancelTransaction(
+	ctx context.Context, from common.Address, nonce uint64,
+) (_ common.Hash, err error) {
+	ctx, span := s.tracer.Start(ctx, spanCancelTransaction, trace.WithAttributes(
+		attribute.String("tx.from", from.Hex()), attribute.Int64("tx.nonce", int64(nonce)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	v, ok := s.sendingNonces.LoadAndDelete(accountNonce{from: from, nonce: nonce})
+	if !ok {
+		return common.Hash{}, ErrNotSending
+	}
+	st := v.(*sendingTx)
+
+	// Stop the original send, waiting until it's done so that it no longer replaces the tx.
+	st.cancel(ErrTxCancelled)
+	select {
+	case <-st.done:
+	case <-ctx.Done():
+		return common.Hash{}, ctx.Err()
+	}
+	st.mu.Lock()
+	tx := st.tx
+	st.mu.Unlock()
+
+	cancelTx, err := s.buildCancellation(tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if cancelTx, err = s.factory.SignTransaction(ctx, *cancelTx.To(), cancelTx); err != nil {
+		return common.Hash{}, err
+	}
+
+	sentTx, err := s.Send(withCancellation(ctx, tx), cancelTx, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return sentTx.Hash(), nil
+}
+
+// buildCancellation builds the (unsigned) 0 value tx to self that replaces the given tx, with
+// its gas bumped by the replacement policy.
+func (s *Sender) buildCancellation(tx *coretypes.Transaction) (*coretypes.Transaction, error) {
+	if tx.Type() == coretypes.BlobTxType {
+		return nil, errors.New("cannot cancel a blob tx with a non-blob tx")
+	}
+	from, err := txFrom(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, replacementPolicy := s.policies()
+	bumped, err := replacementPolicy.GetNew(
+		tx, txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced,
+	)
+	if err != nil {
+		return nil, err
+	}
+	cancelTx := coretypes.NewTx(&coretypes.DynamicFeeTx{
+		ChainID:   tx.ChainId(),
+		Nonce:     tx.Nonce(),
+		GasTipCap: bumped.GasTipCap(),
+		GasFeeCap: bumped.GasFeeCap(),
+		Gas:       params.TxGas,
+		To:        &from,
+		Value:     new(big.Int),
+	})
+
+	// The cancellation must pay strictly more than the tx for the txpool to replace it.
+	if !clearsMinBump(tx, cancelTx) {
+		return nil, ErrInsufficientBump
+	}
+	return cancelTx, nil
+}
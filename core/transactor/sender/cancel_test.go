@@ -0,0 +1,124 @@
+package sender
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestCancelTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	tx := signTestTx(t, key, newTestTx(5))
+
+	// The original tx hangs while sending, until its send is cancelled.
+	var (
+		mu      sync.Mutex
+		sent    []*coretypes.Transaction
+		sending = make(chan struct{})
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Minute},
+		func(ctx context.Context, sentTx *coretypes.Transaction) error {
+			mu.Lock()
+			sent = append(sent, sentTx)
+			mu.Unlock()
+			if sentTx.Hash() == tx.Hash() {
+				close(sending)
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		},
+	)
+
+	sendErr := make(chan error)
+	go func() {
+		_, sErr := s.SendTransaction(context.Background(), tx, []string{"msg"})
+		sendErr <- sErr
+	}()
+	<-sending
+
+	// No tx is sending at another nonce of the account, or at the nonce of another account.
+	_, err = s.CancelTransaction(context.Background(), from, 4)
+	require.ErrorIs(t, err, ErrNotSending)
+	_, err = s.CancelTransaction(context.Background(), common.HexToAddress("0x1"), 5)
+	require.ErrorIs(t, err, ErrNotSending)
+
+	cancelHash, err := s.CancelTransaction(context.Background(), from, 5)
+	require.NoError(t, err)
+	require.ErrorIs(t, <-sendErr, ErrTxCancelled)
+	require.False(t, s.IsSending("msg"))
+
+	// The cancellation was sent like any other tx.
+	status := s.Status()
+	require.EqualValues(t, 1, status.Sent)
+	require.EqualValues(t, 1, status.Failed)
+
+	// The cancellation is a 0 value tx to self, at the same nonce with higher gas.
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, sent, 2)
+	cancelTx := sent[1]
+	require.Equal(t, cancelHash, cancelTx.Hash())
+	require.Equal(t, tx.Nonce(), cancelTx.Nonce())
+	require.Equal(t, from, *cancelTx.To())
+	require.Zero(t, cancelTx.Value().Sign())
+	require.Empty(t, cancelTx.Data())
+	require.Equal(t, params.TxGas, cancelTx.Gas())
+	require.Equal(t, 1, cancelTx.GasTipCap().Cmp(tx.GasTipCap()))
+	require.Equal(t, 1, cancelTx.GasFeeCap().Cmp(tx.GasFeeCap()))
+
+	// Once cancelled, the tx is no longer sending at the nonce.
+	_, err = s.CancelTransaction(context.Background(), from, 5)
+	require.ErrorIs(t, err, ErrNotSending)
+}
+
+func TestCancelTransactionNothingToCancel(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	tx := signTestTx(t, key, newTestTx(5))
+
+	// The original tx hangs while sending, and is mined before its cancellation is sent.
+	var (
+		mu      sync.Mutex
+		sent    []*coretypes.Transaction
+		sending = make(chan struct{})
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(ctx context.Context, sentTx *coretypes.Transaction) error {
+			mu.Lock()
+			sent = append(sent, sentTx)
+			mu.Unlock()
+			if sentTx.Hash() == tx.Hash() {
+				close(sending)
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return core.ErrNonceTooLow
+		},
+	)
+	go func() { _, _ = s.SendTransaction(context.Background(), tx, nil) }()
+	<-sending
+	s.chain.(*mockClient).mine(tx.Hash(), 10)
+
+	// The cancellation isn't replaced with a fresh nonce, since there's nothing left to cancel.
+	_, err = s.CancelTransaction(context.Background(), from, 5)
+	require.ErrorIs(t, err, ErrNothingToCancel)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, sent, 2)
+	require.Equal(t, tx.Nonce(), sent[1].Nonce())
+}
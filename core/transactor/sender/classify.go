@@ -0,0 +1,164 @@
+package sender
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// ErrorClass is the class of an error returned by a node on sending a tx, which determines how
+// the Sender handles it.
+type ErrorClass uint8
+
+const (
+	// ErrorClassUnknown is any error not in a more specific class; the tx is retried as-is.
+	ErrorClassUnknown ErrorClass = iota
+	// ErrorClassNonceTooLow means the tx's nonce was already used; the tx is replaced with a
+	// fresh nonce.
+	ErrorClassNonceTooLow
+	// ErrorClassReplaceUnderpriced means a tx with the same nonce is pending with a similar gas
+	// price; the tx is replaced with gas bumped enough to outbid it.
+	ErrorClassReplaceUnderpriced
+	// ErrorClassAlreadyKnown means the tx is already in the node's mempool, so it was sent.
+	ErrorClassAlreadyKnown
+	// ErrorClassInsufficientFunds means the sender can't pay for the tx; the tx fails.
+	ErrorClassInsufficientFunds
+	// ErrorClassUnderpriced means the tx's gas price is below the node's minimum (regardless of
+	// other txs); the tx is replaced with gas bumped by the underpriced bump, which may differ.
+	ErrorClassUnderpriced
+	// ErrorClassIntrinsicGasTooLow means the tx's gas limit is below its intrinsic gas; the tx is
+	// replaced with a higher gas limit.
+	ErrorClassIntrinsicGasTooLow
+)
+
+// String returns the name of the error class.
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassNonceTooLow:
+		return "nonce_too_low"
+	case ErrorClassReplaceUnderpriced:
+		return "replace_underpriced"
+	case ErrorClassAlreadyKnown:
+		return "already_known"
+	case ErrorClassInsufficientFunds:
+		return "insufficient_funds"
+	case ErrorClassUnderpriced:
+		return "underpriced"
+	case ErrorClassIntrinsicGasTooLow:
+		return "intrinsic_gas_too_low"
+	case ErrorClassUnknown:
+		return "unknown"
+	default:
+		return "unknown"
+	}
+}
+
+// expected returns true if errors of the class routinely occur while sending txs and are handled
+// by replacing the tx, rather than being a sign that something is wrong.
+func (c ErrorClass) expected() bool {
+	switch c {
+	case ErrorClassNonceTooLow, ErrorClassReplaceUnderpriced, ErrorClassUnderpriced,
+		ErrorClassIntrinsicGasTooLow:
+		return true
+	case ErrorClassUnknown, ErrorClassAlreadyKnown, ErrorClassInsufficientFunds:
+		return false
+	default:
+		return false
+	}
+}
+
+// terminal returns true if errors of the class can't be resolved by retrying or replacing the tx.
+func (c ErrorClass) terminal() bool {
+	return c == ErrorClassInsufficientFunds
+}
+
+// ErrorClassifier classifies the errors returned by a node on sending a tx.
+type ErrorClassifier interface {
+	// Classify returns the class of the error, or ErrorClassUnknown if it isn't recognized.
+	Classify(err error) ErrorClass
+}
+
+// ErrorClassifierFunc is a func that implements ErrorClassifier.
+type ErrorClassifierFunc func(err error) ErrorClass
+
+// Classify calls f(err).
+func (f ErrorClassifierFunc) Classify(err error) ErrorClass {
+	return f(err)
+}
+
+// DefaultErrorClassifier classifies the errors of Geth, as well as the differently phrased errors
+// of Erigon, Nethermind, Besu and OpenEthereum, which hosted providers pass on. Errors are matched
+// both by value and by message, since RPC clients only return the message.
+var DefaultErrorClassifier ErrorClassifier = ErrorClassifierFunc(classifyDefault)
+
+// errorClassMessages are the (lowercase) substrings of the error messages of each class, in the
+// order the classes are matched.
+var errorClassMessages = []struct {
+	class    ErrorClass
+	messages []string
+}{
+	{ErrorClassAlreadyKnown, []string{
+		"already known", "known transaction", "already imported", "alreadyknown",
+	}},
+	{ErrorClassNonceTooLow, []string{"nonce too low", "nonce is too low", "oldnonce"}},
+	// Matched before underpriced, whose messages are a substring.
+	{ErrorClassReplaceUnderpriced, []string{
+		"replacement transaction underpriced", "another transaction with same nonce",
+	}},
+	{ErrorClassUnderpriced, []string{
+		"underpriced", "fee too low", "feetoolow", "gas price is too low",
+		"below configured minimum gas price",
+	}},
+	{ErrorClassInsufficientFunds, []string{
+		"insufficient funds", "insufficientfunds", "insufficient balance",
+		"exceeds account balance",
+	}},
+	{ErrorClassIntrinsicGasTooLow, []string{
+		"intrinsic gas too low", "intrinsic gas exceeds gas limit",
+	}},
+}
+
+// classifyDefault classifies the error by its value or message.
+func classifyDefault(err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ErrorClassUnknown
+	case errors.Is(err, txpool.ErrAlreadyKnown):
+		return ErrorClassAlreadyKnown
+	case errors.Is(err, core.ErrNonceTooLow):
+		return ErrorClassNonceTooLow
+	case errors.Is(err, txpool.ErrReplaceUnderpriced):
+		return ErrorClassReplaceUnderpriced
+	case errors.Is(err, txpool.ErrUnderpriced):
+		return ErrorClassUnderpriced
+	case errors.Is(err, core.ErrInsufficientFunds) || errors.Is(err, vm.ErrInsufficientBalance):
+		return ErrorClassInsufficientFunds
+	case errors.Is(err, core.ErrIntrinsicGas):
+		return ErrorClassIntrinsicGasTooLow
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, ecm := range errorClassMessages {
+		for _, m := range ecm.messages {
+			if strings.Contains(msg, m) {
+				return ecm.class
+			}
+		}
+	}
+	return ErrorClassUnknown
+}
+
+// chainedClassifier classifies errors with the first classifier that recognizes them.
+type chainedClassifier []ErrorClassifier
+
+func (cc chainedClassifier) Classify(err error) ErrorClass {
+	for _, c := range cc {
+		if class := c.Classify(err); class != ErrorClassUnknown {
+			return class
+		}
+	}
+	return ErrorClassUnknown
+}
@@ -0,0 +1,143 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDefaultErrorClassifier(t *testing.T) {
+	for _, tc := range []struct {
+		provider string
+		err      error
+		class    ErrorClass
+	}{
+		// Geth, by value (wrapped) and by the messages returned over RPC.
+		{"geth", fmt.Errorf("send: %w", core.ErrNonceTooLow), ErrorClassNonceTooLow},
+		{"geth", txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced},
+		{"geth", txpool.ErrUnderpriced, ErrorClassUnderpriced},
+		{"geth", errors.New("nonce too low: next nonce 5, tx nonce 3"), ErrorClassNonceTooLow},
+		{"geth", errors.New("replacement transaction underpriced"), ErrorClassReplaceUnderpriced},
+		{"geth", errors.New("already known"), ErrorClassAlreadyKnown},
+		{
+			"geth", errors.New("insufficient funds for gas * price + value: balance 0, tx cost 1"),
+			ErrorClassInsufficientFunds,
+		},
+		{"geth", errors.New("insufficient balance for transfer"), ErrorClassInsufficientFunds},
+		{"geth", errors.New("transaction underpriced"), ErrorClassUnderpriced},
+		{
+			"geth", errors.New("intrinsic gas too low: have 20000, want 21000"),
+			ErrorClassIntrinsicGasTooLow,
+		},
+		{"geth", errors.New("execution reverted"), ErrorClassUnknown},
+
+		// Erigon.
+		{"erigon", errors.New("nonce too low"), ErrorClassNonceTooLow},
+		{"erigon", errors.New("underpriced"), ErrorClassUnderpriced},
+		{"erigon", errors.New("fee too low"), ErrorClassUnderpriced},
+		{"erigon", errors.New("insufficient funds"), ErrorClassInsufficientFunds},
+
+		// Nethermind.
+		{
+			"nethermind", errors.New("OldNonce, Current nonce: 5, nonce of rejected tx: 3"),
+			ErrorClassNonceTooLow,
+		},
+		{"nethermind", errors.New("AlreadyKnown"), ErrorClassAlreadyKnown},
+		{
+			"nethermind", errors.New("FeeTooLow, EffectivePriorityFeePerGas too low"),
+			ErrorClassUnderpriced,
+		},
+		{"nethermind", errors.New("InsufficientFunds, Account balance: 0"), ErrorClassInsufficientFunds},
+
+		// Besu.
+		{"besu", errors.New("Nonce too low"), ErrorClassNonceTooLow},
+		{"besu", errors.New("Known transaction"), ErrorClassAlreadyKnown},
+		{"besu", errors.New("Replacement transaction underpriced"), ErrorClassReplaceUnderpriced},
+		{
+			"besu", errors.New("Gas price below configured minimum gas price"),
+			ErrorClassUnderpriced,
+		},
+		{
+			"besu", errors.New("Upfront cost exceeds account balance"),
+			ErrorClassInsufficientFunds,
+		},
+
+		// OpenEthereum, as still returned by some hosted providers.
+		{
+			"openethereum", errors.New("Transaction nonce is too low. Try incrementing the nonce."),
+			ErrorClassNonceTooLow,
+		},
+		{
+			"openethereum", errors.New("Transaction with the same hash was already imported."),
+			ErrorClassAlreadyKnown,
+		},
+		{
+			"openethereum", errors.New("Transaction gas price is too low. There is another " +
+				"transaction with same nonce in the queue. Try increasing the gas price or " +
+				"incrementing the nonce."),
+			ErrorClassReplaceUnderpriced,
+		},
+		{
+			"openethereum", errors.New("Transaction gas price is too low. It does not satisfy " +
+				"your node's minimal gas price (minimal: 1, got: 0). Try increasing the gas price."),
+			ErrorClassUnderpriced,
+		},
+		{
+			"openethereum", errors.New("Insufficient funds. The account you tried to send " +
+				"transaction from does not have enough funds. Required 2 and got: 1."),
+			ErrorClassInsufficientFunds,
+		},
+
+		// Hosted providers, which wrap the node's error.
+		{
+			"hosted", errors.New("rpc error: nonce too low: address 0x1, tx: 3 state: 5"),
+			ErrorClassNonceTooLow,
+		},
+		{"hosted", errors.New("429 Too Many Requests"), ErrorClassUnknown},
+	} {
+		t.Run(tc.provider+"/"+tc.err.Error(), func(t *testing.T) {
+			require.Equal(t, tc.class, DefaultErrorClassifier.Classify(tc.err))
+		})
+	}
+}
+
+func TestWithErrorClassifier(t *testing.T) {
+	// A provider quirk for replacements that the default classifier doesn't recognize.
+	errQuirk := errors.New("tx with same nonce pending, raise fee")
+	quirks := ErrorClassifierFunc(func(err error) ErrorClass {
+		if strings.Contains(err.Error(), "raise fee") {
+			return ErrorClassReplaceUnderpriced
+		}
+		return ErrorClassUnknown
+	})
+	require.Equal(t, ErrorClassUnknown, DefaultErrorClassifier.Classify(errQuirk))
+
+	var (
+		metrics = &recordingMetrics{}
+		errs    = []error{errQuirk, core.ErrNonceTooLow, nil}
+		sent    []*coretypes.Transaction
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(_ context.Context, tx *coretypes.Transaction) error {
+			sent = append(sent, tx)
+			return errs[len(sent)-1]
+		},
+		WithErrorClassifier(quirks), WithMetrics(metrics),
+	)
+
+	// The quirk is replaced with bumped gas, and the default classification still applies.
+	_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{RetryReasonReplaceUnderpriced, RetryReasonNonceTooLow}, metrics.retries)
+	require.Equal(t, 1, sent[1].GasFeeCap().Cmp(sent[0].GasFeeCap()))
+}
@@ -0,0 +1,86 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits for durations to elapse, so that the timing of sends (e.g. the
+// backoff between retries) can be controlled in tests (see WithClock and FakeClock).
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that receives the current time once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock of the system's time.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// FakeClock is a Clock whose time only moves when advanced, for tests. It is safe for concurrent
+// use.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFakeClock creates a FakeClock at the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current (virtual) time.
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+// After returns a channel that receives the current time once the clock is advanced by d.
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- fc.now
+		return ch
+	}
+	fc.waiters = append(fc.waiters, fakeWaiter{at: fc.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d, firing the channels of the waits that have elapsed.
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.now = fc.now.Add(d)
+	waiters := fc.waiters[:0]
+	for _, w := range fc.waiters {
+		if w.at.After(fc.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- fc.now
+	}
+	fc.waiters = waiters
+}
+
+// Waiters returns the number of waits that haven't elapsed yet, e.g. for a test to know when a
+// send is backing off.
+func (fc *FakeClock) Waiters() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return len(fc.waiters)
+}
@@ -0,0 +1,155 @@
+package sender
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/stretchr/testify/require"
+
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+	require.Equal(t, start, clock.Now())
+
+	// Non-positive waits elapse immediately.
+	require.Equal(t, start, <-clock.After(0))
+
+	short, long := clock.After(time.Second), clock.After(time.Minute)
+	require.Equal(t, 2, clock.Waiters())
+	clock.Advance(time.Second - 1)
+	require.Empty(t, short)
+	clock.Advance(1)
+	require.Equal(t, start.Add(time.Second), <-short)
+	require.Empty(t, long)
+	require.Equal(t, 1, clock.Waiters())
+	clock.Advance(time.Hour)
+	require.Equal(t, start.Add(time.Hour+time.Second), <-long)
+	require.Zero(t, clock.Waiters())
+}
+
+func TestSendTransactionBackoffSchedule(t *testing.T) {
+	var (
+		clock    = NewFakeClock(time.Unix(0, 0))
+		metrics  = &recordingMetrics{}
+		attempts = make(chan time.Time, 5)
+	)
+	s := newTestSender(
+		NewLinearRetryPolicy(0, time.Second, 3*time.Second),
+		func(context.Context, *coretypes.Transaction) error {
+			attempts <- clock.Now()
+			if len(attempts) < cap(attempts) {
+				return errRPCUnavailable
+			}
+			return nil
+		},
+		WithClock(clock), WithMetrics(metrics),
+	)
+
+	sent := make(chan error, 1)
+	go func() {
+		_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+		sent <- err
+	}()
+
+	// Each retry waits exactly its linear backoff, capped at 3s, without any real waiting.
+	schedule := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for _, backoff := range schedule {
+		require.Eventually(t, func() bool { return clock.Waiters() == 1 },
+			time.Second, time.Millisecond)
+		clock.Advance(backoff - 1)
+		require.Equal(t, 1, clock.Waiters(), "retried before the %s backoff", backoff)
+		clock.Advance(1)
+	}
+	require.NoError(t, <-sent)
+
+	close(attempts)
+	var times []time.Duration
+	for at := range attempts {
+		times = append(times, at.Sub(time.Unix(0, 0)))
+	}
+	require.Equal(t, []time.Duration{
+		0, time.Second, 3 * time.Second, 6 * time.Second, 9 * time.Second,
+	}, times)
+	require.Equal(t, []time.Duration{9 * time.Second}, metrics.latencies)
+}
+
+func TestDeadlineRetryPolicyUsesClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	fromConfig, err := NewFromConfig(&mockFactory{}, &mockNoncer{}, Config{
+		RetryPolicy: RetryPolicyDeadline, RetryBudget: 2500 * time.Millisecond,
+		CircuitBreakerThreshold: 10,
+	}, WithClock(clock))
+	require.NoError(t, err)
+	fromConfig.Setup(&mockClient{
+		sendFn: func(context.Context, *coretypes.Transaction) error { return errRPCUnavailable },
+	}, log.NewBlankLogger(io.Discard))
+	require.Equal(t, clock.Now(), fromConfig.breaker.now())
+
+	for name, s := range map[string]*Sender{
+		"new": newTestSender(
+			NewDeadlineRetryPolicy(2500*time.Millisecond),
+			func(context.Context, *coretypes.Transaction) error { return errRPCUnavailable },
+			WithClock(clock),
+		),
+		"config": fromConfig,
+	} {
+		sent := make(chan error, 1)
+		go func() {
+			_, sendErr := s.SendTransaction(context.Background(), newTestTx(0), nil)
+			sent <- sendErr
+		}()
+
+		// The budget elapses on the Sender's clock, with the last backoff ending at the deadline.
+		for _, backoff := range []time.Duration{
+			500 * time.Millisecond, time.Second, time.Second,
+		} {
+			require.Eventually(t, func() bool { return clock.Waiters() == 1 },
+				time.Second, time.Millisecond, name)
+			clock.Advance(backoff)
+		}
+		select {
+		case err = <-sent:
+			require.ErrorIs(t, err, errRPCUnavailable, name)
+		case <-time.After(time.Second):
+			require.FailNow(t, "retried past the budget", name)
+		}
+	}
+}
+
+func TestSendAndWaitUsesClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	client := &mockClient{}
+	client.sendFn = func(_ context.Context, sent *coretypes.Transaction) error {
+		client.mine(sent.Hash(), 10)
+		return nil
+	}
+	s := newTestSender(&fixedRetryPolicy{}, nil, WithClock(clock))
+	s.Setup(client, s.logger)
+
+	tx := newTestTx(0)
+	waited := make(chan error, 1)
+	go func() {
+		_, err := s.SendAndWait(context.Background(), tx, nil, 2)
+		waited <- err
+	}()
+
+	// The receipt is polled again once the poll interval elapses on the Sender's clock.
+	require.Eventually(t, func() bool { return clock.Waiters() == 1 },
+		time.Second, time.Millisecond)
+	client.mu.Lock()
+	client.blockNumber++
+	client.mu.Unlock()
+	clock.Advance(receiptPollInterval)
+	select {
+	case err := <-waited:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "receipt not polled on the clock")
+	}
+}
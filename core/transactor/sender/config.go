@@ -0,0 +1,203 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Names of the retry policies that can be selected from the Config.
+const (
+	RetryPolicyExpo     = "expo"
+	RetryPolicyLinear   = "linear"
+	RetryPolicyDeadline = "deadline"
+	RetryPolicyNone     = "none"
+)
+
+// Config is the configuration for the Sender's retry and replacement policies.
+type Config struct {
+	// Name of the retry policy to use. If empty, the "expo" policy is used, with the settings below.
+	RetryPolicy string
+	// Maximum number of retries per tx; 0 retries indefinitely.
+	MaxRetries int
+	// Backoff before the first retry (also the increase per retry for the "linear" policy); if
+	// 0, defaults to 500ms.
+	BaseBackoff time.Duration
+	// Cap on the backoff for the "expo", "linear" and "deadline" policies; if 0, defaults to 30s.
+	MaxBackoff time.Duration
+	// Wall-clock budget for retrying each tx from its first failed attempt for the "deadline"
+	// policy, regardless of MaxRetries; required for the "deadline" policy.
+	RetryBudget time.Duration
+	// Fraction of the backoff window to randomize for the "expo" policy, in [0, 1]. If 0, up to
+	// 1s of jitter is added to the backoff instead, within MaxBackoff.
+	JitterFraction float64
+	// Custom backoff schedule (e.g. BlockAlignedBackoff), overriding the backoff computed by the
+	// "expo", "linear" and "deadline" policies; if nil, the policy's backoff is used.
+	Backoff BackoffFunc `mapstructure:"-"`
+
+	// Maximum number of txs sent concurrently by SendTransactions; if 0, defaults to 10.
+	BatchConcurrency int
+	// Timeout for each attempt to send a tx, after which the attempt may be retried; if 0, an
+	// attempt is bounded only by the caller's context.
+	PerAttemptTimeout time.Duration
+
+	// Number of consecutive failed sends after which the circuit breaker opens, failing further
+	// sends fast with ErrCircuitOpen; if 0, there is no circuit breaker. Sends that the chain
+	// responds to (even with an error like nonce too low) don't count as failures.
+	CircuitBreakerThreshold int
+	// Window within which the consecutive failures must occur to open the circuit breaker; if 0,
+	// any consecutive failures count.
+	CircuitBreakerWindow time.Duration
+	// Time the circuit breaker stays open before allowing a probe send, which closes the breaker
+	// if it succeeds; if 0, defaults to 10s.
+	CircuitBreakerCooldown time.Duration
+
+	// Percentage to bump the gas by when replacing a tx; if 0, defaults to 15%. Must be at least
+	// 10% for the chain to accept the replacement.
+	ReplacementBumpPercent int
+	// Percentage to bump the gas by when a tx is underpriced, i.e. below the node's minimum gas
+	// price, as opposed to underpriced as a replacement of a pending tx with the same nonce; if 0,
+	// the ReplacementBumpPercent applies. Like it, it must be at least 10%.
+	UnderpricedBumpPercent int
+	// Minimum amount in wei to bump the gas price (or gas tip cap and gas fee cap) by when
+	// replacing a tx, if more than the percentage bump; if 0, only the percentage bump applies.
+	// Ensures replacements of txs with very low gas prices clear the node's minimum.
+	ReplacementMinBump uint64
+	// Ceiling on the gas price (or gas fee cap) in wei that replacements may bump up to; if 0,
+	// there is no ceiling. Bumps are clamped to the ceiling. Once a tx is at the ceiling, a
+	// further replacement fails with ErrGasPriceCeiling, which stops retrying the tx regardless of
+	// the retry policy. So does ErrInsufficientBump, if clamping a replacement leaves its bump too
+	// small for the txpool to accept it.
+	MaxGasPrice uint64
+	// Percentage to bump the gas limit by when a tx's gas limit is too low ("intrinsic gas too
+	// low"); if 0, defaults to 20%. The gas is also re-estimated, and the higher limit is used.
+	GasLimitMarginPercent int
+}
+
+// Validate ensures that the Config is valid.
+func (c Config) Validate() error {
+	switch c.RetryPolicy {
+	case "", RetryPolicyExpo, RetryPolicyLinear, RetryPolicyNone:
+	case RetryPolicyDeadline:
+		if c.RetryBudget <= 0 {
+			return errors.New("retry budget must be positive for the deadline retry policy")
+		}
+	default:
+		return fmt.Errorf("unknown retry policy: %q", c.RetryPolicy)
+	}
+
+	if c.MaxRetries < 0 {
+		return errors.New("max retries must not be negative")
+	}
+	if c.BaseBackoff < 0 || c.MaxBackoff < 0 {
+		return errors.New("backoffs must not be negative")
+	}
+	if c.baseBackoff() > c.maxBackoff() {
+		return fmt.Errorf("base backoff %s exceeds max backoff %s", c.baseBackoff(), c.maxBackoff())
+	}
+	if c.BatchConcurrency < 0 {
+		return errors.New("batch concurrency must not be negative")
+	}
+	if c.PerAttemptTimeout < 0 {
+		return errors.New("per attempt timeout must not be negative")
+	}
+	if c.CircuitBreakerThreshold < 0 || c.CircuitBreakerWindow < 0 ||
+		c.CircuitBreakerCooldown < 0 {
+		return errors.New("circuit breaker settings must not be negative")
+	}
+	if c.JitterFraction < 0 || c.JitterFraction > 1 {
+		return errors.New("jitter fraction must be between 0 and 1")
+	}
+	if c.GasLimitMarginPercent < 0 {
+		return errors.New("gas limit margin percent must not be negative")
+	}
+	if !validBumpPercent(c.ReplacementBumpPercent) {
+		return fmt.Errorf("replacement bump percent must be 0 (default) or at least %d, got %d",
+			minBumpPercent, c.ReplacementBumpPercent)
+	}
+	if !validBumpPercent(c.UnderpricedBumpPercent) {
+		return fmt.Errorf("underpriced bump percent must be 0 (default) or at least %d, got %d",
+			minBumpPercent, c.UnderpricedBumpPercent)
+	}
+
+	return nil
+}
+
+// validBumpPercent returns whether the gas bump percentage is either unset (0) or high enough for
+// the chain to accept the replacement.
+func validBumpPercent(percent int) bool {
+	return percent == 0 || percent >= minBumpPercent
+}
+
+// retryPolicy returns the retry policy selected by the Config.
+func (c Config) retryPolicy() RetryPolicy {
+	switch c.RetryPolicy {
+	case RetryPolicyNone:
+		return &noRetryPolicy{}
+	case "", RetryPolicyExpo:
+		erp := NewExpoRetryPolicy(c.MaxRetries, c.baseBackoff())
+		erp.SetMaxBackoff(c.maxBackoff())
+		erp.SetJitterFraction(c.JitterFraction)
+		erp.SetBackoffFunc(c.Backoff)
+		return erp
+	case RetryPolicyLinear:
+		lrp := NewLinearRetryPolicy(c.MaxRetries, c.baseBackoff(), c.maxBackoff())
+		lrp.SetBackoffFunc(c.Backoff)
+		return lrp
+	default: // RetryPolicyDeadline, as validated
+		drp := NewDeadlineRetryPolicy(c.RetryBudget)
+		drp.baseBackoff = c.baseBackoff()
+		drp.SetMaxBackoff(c.maxBackoff())
+		drp.SetBackoffFunc(c.Backoff)
+		return drp
+	}
+}
+
+// minBump returns the minimum absolute gas bump selected by the Config, or nil if there is none.
+func (c Config) minBump() *big.Int {
+	if c.ReplacementMinBump == 0 {
+		return nil
+	}
+	return new(big.Int).SetUint64(c.ReplacementMinBump)
+}
+
+// maxGasPrice returns the gas price ceiling selected by the Config, or nil if there is none.
+func (c Config) maxGasPrice() *big.Int {
+	if c.MaxGasPrice == 0 {
+		return nil
+	}
+	return new(big.Int).SetUint64(c.MaxGasPrice)
+}
+
+// baseBackoff returns the backoff before the first retry selected by the Config.
+func (c Config) baseBackoff() time.Duration {
+	if c.BaseBackoff == 0 {
+		return backoffStart
+	}
+	return c.BaseBackoff
+}
+
+// maxBackoff returns the cap on the backoff selected by the Config.
+func (c Config) maxBackoff() time.Duration {
+	if c.MaxBackoff == 0 {
+		return maxBackoff
+	}
+	return c.MaxBackoff
+}
+
+// bumpPercent returns the gas bump percentage selected by the Config.
+func (c Config) bumpPercent() int {
+	if c.ReplacementBumpPercent == 0 {
+		return defaultBumpPercent
+	}
+	return c.ReplacementBumpPercent
+}
+
+// gasLimitMarginPercent returns the gas limit bump percentage selected by the Config.
+func (c Config) gasLimitMarginPercent() int {
+	if c.GasLimitMarginPercent == 0 {
+		return defaultGasLimitMarginPercent
+	}
+	return c.GasLimitMarginPercent
+}
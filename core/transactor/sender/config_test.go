@@ -0,0 +1,130 @@
+package sender
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+		check   func(*testing.T, *Sender)
+	}{
+		{
+			name: "default",
+			cfg:  Config{},
+			check: func(t *testing.T, s *Sender) {
+				erp, ok := s.retryPolicy.(*ExpoRetryPolicy)
+				require.True(t, ok)
+				require.Zero(t, erp.maxRetries)
+				require.Equal(t, backoffStart, erp.baseBackoff)
+				require.Equal(t, maxBackoff, erp.maxBackoff)
+			},
+		},
+		{
+			// Like "expo", the default policy is configured by the settings.
+			name: "default with settings",
+			cfg: Config{
+				MaxRetries: 10, BaseBackoff: time.Second, MaxBackoff: time.Minute,
+				JitterFraction: 0.5,
+			},
+			check: func(t *testing.T, s *Sender) {
+				erp, ok := s.retryPolicy.(*ExpoRetryPolicy)
+				require.True(t, ok)
+				require.Equal(t, 10, erp.maxRetries)
+				require.Equal(t, time.Second, erp.baseBackoff)
+				require.Equal(t, time.Minute, erp.maxBackoff)
+				require.InDelta(t, 0.5, erp.jitterFraction, 0)
+			},
+		},
+		{
+			name: "expo",
+			cfg:  Config{RetryPolicy: RetryPolicyExpo, MaxRetries: 5, ReplacementBumpPercent: 20},
+			check: func(t *testing.T, s *Sender) {
+				erp, ok := s.retryPolicy.(*ExpoRetryPolicy)
+				require.True(t, ok)
+				require.Equal(t, 5, erp.maxRetries)
+				require.Equal(t, backoffStart, erp.baseBackoff)
+
+				drp, ok := s.txReplacementPolicy.(*defaultTxReplacementPolicy)
+				require.True(t, ok)
+				require.Equal(t, 20, drp.bumpPercent)
+			},
+		},
+		{
+			name: "underpriced bump",
+			cfg:  Config{UnderpricedBumpPercent: 50},
+			check: func(t *testing.T, s *Sender) {
+				drp, ok := s.txReplacementPolicy.(*defaultTxReplacementPolicy)
+				require.True(t, ok)
+				require.Equal(t, defaultBumpPercent, drp.bumpPercent)
+				require.Equal(t, 50, drp.underpricedBumpPercent)
+			},
+		},
+		{
+			name: "none",
+			cfg:  Config{RetryPolicy: RetryPolicyNone},
+			check: func(t *testing.T, s *Sender) {
+				require.IsType(t, &noRetryPolicy{}, s.retryPolicy)
+			},
+		},
+		{
+			name: "deadline",
+			cfg: Config{
+				RetryPolicy: RetryPolicyDeadline, RetryBudget: time.Minute, MaxBackoff: 5 * time.Second,
+			},
+			check: func(t *testing.T, s *Sender) {
+				drp, ok := s.retryPolicy.(*DeadlineRetryPolicy)
+				require.True(t, ok)
+				require.Equal(t, time.Minute, drp.budget)
+				require.Equal(t, backoffStart, drp.baseBackoff)
+				require.Equal(t, 5*time.Second, drp.maxBackoff)
+			},
+		},
+		{
+			name: "circuit breaker",
+			cfg:  Config{CircuitBreakerThreshold: 5, CircuitBreakerWindow: time.Minute},
+			check: func(t *testing.T, s *Sender) {
+				require.NotNil(t, s.breaker)
+				require.Equal(t, 5, s.breaker.threshold)
+				require.Equal(t, time.Minute, s.breaker.window)
+				require.Equal(t, defaultBreakerCooldown, s.breaker.cooldown)
+			},
+		},
+		{name: "unknown policy", cfg: Config{RetryPolicy: "fibonacci"}, wantErr: true},
+		{name: "deadline without budget", cfg: Config{RetryPolicy: RetryPolicyDeadline}, wantErr: true},
+		{name: "negative breaker", cfg: Config{CircuitBreakerThreshold: -1}, wantErr: true},
+		{name: "negative bump", cfg: Config{ReplacementBumpPercent: -1}, wantErr: true},
+		{name: "negative underpriced bump", cfg: Config{UnderpricedBumpPercent: -1}, wantErr: true},
+		{
+			name:    "base backoff above max",
+			cfg:     Config{BaseBackoff: time.Minute, MaxBackoff: time.Second},
+			wantErr: true,
+		},
+		{name: "low bump", cfg: Config{ReplacementBumpPercent: 5}, wantErr: true},
+		{name: "low underpriced bump", cfg: Config{UnderpricedBumpPercent: 9}, wantErr: true},
+		{
+			name: "min bump",
+			cfg:  Config{ReplacementBumpPercent: minBumpPercent},
+			check: func(t *testing.T, s *Sender) {
+				drp, ok := s.txReplacementPolicy.(*defaultTxReplacementPolicy)
+				require.True(t, ok)
+				require.Equal(t, minBumpPercent, drp.bumpPercent)
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewFromConfig(&mockFactory{}, &mockNoncer{}, tc.cfg)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, s)
+		})
+	}
+}
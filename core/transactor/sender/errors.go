@@ -0,0 +1,84 @@
+package sender
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrGasPriceCeiling is returned when a tx must be replaced with a higher gas price, but its gas
+// price is already at the configured ceiling.
+var ErrGasPriceCeiling = errors.New("gas price is already at the configured ceiling")
+
+// ErrInsufficientBump is returned when a tx must be replaced, but the bumped gas price would not
+// be high enough for the replacement to be accepted.
+var ErrInsufficientBump = errors.New("gas bump is too small to replace the tx")
+
+// ErrAlreadySending is returned when a tx is not sent because one of its message IDs is already
+// being sent by another tx.
+var ErrAlreadySending = errors.New("message is already sending")
+
+// ErrDraining is returned when a tx is not sent because the Sender is draining.
+var ErrDraining = errors.New("sender is draining, not sending tx")
+
+// ErrCircuitOpen is returned when a tx is not sent because too many recent sends have failed,
+// which likely means the chain's RPC endpoint is down.
+var ErrCircuitOpen = errors.New("circuit breaker is open, not sending tx")
+
+// ErrSendAborted is returned when a tx is not broadcast because the before send hook returned an
+// error, which is also wrapped.
+var ErrSendAborted = errors.New("send aborted by before send hook")
+
+// ErrBuildFailed is returned when a tx is not retried because its replacement failed to be built
+// (or signed) by the factory on every attempt. The error of the last attempt is also wrapped.
+var ErrBuildFailed = errors.New("failed to build replacement tx")
+
+// ErrNilTransaction is returned when the replacement policy returned a nil tx without error, and
+// wrapped by ErrBuildFailed when the factory built one.
+var ErrNilTransaction = errors.New("got a nil tx")
+
+// ErrNotSending is returned when cancelling a tx, but no tx is sending from the given account at
+// the given nonce.
+var ErrNotSending = errors.New("no tx is sending at the nonce")
+
+// ErrNothingToCancel is returned when cancelling a tx whose nonce was already used, e.g. because
+// the tx was mined before its cancellation.
+var ErrNothingToCancel = errors.New("nothing to cancel, the nonce was already used")
+
+// ErrTxCancelled is returned when sending a tx is stopped because the tx was cancelled.
+var ErrTxCancelled = errors.New("tx was cancelled")
+
+// ErrInsufficientFunds matches (with errors.Is) a SendError for a tx that failed because its
+// sender has insufficient funds, which is not retried.
+var ErrInsufficientFunds = errors.New("insufficient funds to send tx")
+
+// SendError is returned by SendTransaction when a tx that was attempted to be sent fails, e.g.
+// because its retries were exhausted. It wraps the final error, so errors.Is and errors.As match
+// the underlying error.
+type SendError struct {
+	// Err is the final error that sending the tx failed with.
+	Err error
+	// Class is the class of the final error.
+	Class ErrorClass
+	// Attempts is the number of attempts made to send the tx (including its replacements).
+	Attempts int
+	// Hash is the hash of the tx last attempted, which may be a replacement of the original tx.
+	Hash common.Hash
+	// Nonce is the nonce of the tx last attempted.
+	Nonce uint64
+}
+
+// Error returns the message of the final error.
+func (e *SendError) Error() string {
+	return e.Err.Error()
+}
+
+// Is returns true if the target is ErrInsufficientFunds and the final error is of that class.
+func (e *SendError) Is(target error) bool {
+	return target == ErrInsufficientFunds && e.Class == ErrorClassInsufficientFunds
+}
+
+// Unwrap returns the final error.
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,71 @@
+package sender
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// defaultEventBufferSize is the default number of tx events buffered for a slow consumer.
+const defaultEventBufferSize = 256
+
+// TxEventState is a state in the lifecycle of sending a tx.
+type TxEventState int
+
+// States in the lifecycle of sending a tx.
+const (
+	// TxEventSending is emitted before each attempt to send the tx.
+	TxEventSending TxEventState = iota
+	// TxEventRetrying is emitted when an attempt failed and the tx will be retried.
+	TxEventRetrying
+	// TxEventBuilding is emitted before the tx is rebuilt (and signed) to be retried.
+	TxEventBuilding
+	// TxEventReplaced is emitted when the tx is replaced by a tx with a different hash (e.g. with
+	// bumped gas or a new nonce), with the hash of the replacement.
+	TxEventReplaced
+	// TxEventSent is emitted once the tx was sent successfully.
+	TxEventSent
+	// TxEventFailed is emitted once sending the tx failed permanently (or the context was done).
+	TxEventFailed
+)
+
+// String returns the name of the state.
+func (s TxEventState) String() string {
+	switch s {
+	case TxEventSending:
+		return "sending"
+	case TxEventRetrying:
+		return "retrying"
+	case TxEventBuilding:
+		return "building"
+	case TxEventReplaced:
+		return "replaced"
+	case TxEventSent:
+		return "sent"
+	case TxEventFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// TxEvent is a state transition of a tx being sent.
+type TxEvent struct {
+	MsgIDs []string     // message IDs of the tx
+	Hash   common.Hash  // hash of the tx as of the transition
+	State  TxEventState // state transitioned to
+	Err    error        // error that caused the transition, if any
+}
+
+// Events returns the channel of state transitions of the txs being sent. To never block sending,
+// events are buffered (see WithEventBufferSize) and dropped while the buffer is full, so a slow
+// (or absent) consumer misses events rather than slowing down sends.
+func (s *Sender) Events() <-chan TxEvent {
+	return s.events
+}
+
+// emit emits the event, dropping it if the buffer is full.
+func (s *Sender) emit(msgIDs []string, hash common.Hash, state TxEventState, err error) {
+	select {
+	case s.events <- TxEvent{MsgIDs: msgIDs, Hash: hash, State: state, Err: err}:
+	default:
+	}
+}
@@ -0,0 +1,30 @@
+package sender
+
+import "context"
+
+// labelKey is the context key of the label of a send.
+type labelKey struct{}
+
+// WithLabel returns a copy of the context that labels the sends made with it, e.g. with the name
+// of the job that triggered them. The Sender includes the label in the logs, traces and metrics
+// (see LabeledMetrics) of the send, tying its retries back to the operation.
+func WithLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, labelKey{}, label)
+}
+
+// LabelFromContext returns the label carried by the context, or an empty string if there is none.
+func LabelFromContext(ctx context.Context) string {
+	label, _ := ctx.Value(labelKey{}).(string)
+	return label
+}
+
+// metricsFor returns the Metrics to report the send made with the context to, which are specific
+// to the send's label if it has one and the Metrics support labels.
+func (s *Sender) metricsFor(ctx context.Context) Metrics {
+	if lm, ok := s.metrics.(LabeledMetrics); ok {
+		if label := LabelFromContext(ctx); label != "" {
+			return lm.ForLabel(label)
+		}
+	}
+	return s.metrics
+}
@@ -0,0 +1,106 @@
+package sender
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/stretchr/testify/require"
+
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// fieldsLogger is a log.Logger that captures the fields of each log (including those added with
+// With), by message.
+type fieldsLogger struct {
+	fields []any
+	logs   *map[string][]any
+	mu     *sync.Mutex
+}
+
+func newFieldsLogger() *fieldsLogger {
+	logs := make(map[string][]any)
+	return &fieldsLogger{logs: &logs, mu: &sync.Mutex{}}
+}
+
+func (l *fieldsLogger) log(msg string, keyVals ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	(*l.logs)[msg] = append(append([]any(nil), l.fields...), keyVals...)
+}
+
+// field returns the value of the field of the log with the message, or nil if there is none.
+func (l *fieldsLogger) field(msg, key string) any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	keyVals := (*l.logs)[msg]
+	for i := 0; i+1 < len(keyVals); i += 2 {
+		if keyVals[i] == key {
+			return keyVals[i+1]
+		}
+	}
+	return nil
+}
+
+func (l *fieldsLogger) Debug(msg string, keyVals ...any) { l.log(msg, keyVals...) }
+func (l *fieldsLogger) Info(msg string, keyVals ...any)  { l.log(msg, keyVals...) }
+func (l *fieldsLogger) Warn(msg string, keyVals ...any)  { l.log(msg, keyVals...) }
+func (l *fieldsLogger) Error(msg string, keyVals ...any) { l.log(msg, keyVals...) }
+func (l *fieldsLogger) Impl() any                        { return l }
+
+func (l *fieldsLogger) With(keyVals ...any) log.Logger {
+	return &fieldsLogger{
+		fields: append(append([]any(nil), l.fields...), keyVals...), logs: l.logs, mu: l.mu,
+	}
+}
+
+// labeledMetrics records the metrics of sends by label.
+type labeledMetrics struct {
+	recordingMetrics
+	byLabel map[string]*recordingMetrics
+}
+
+func (m *labeledMetrics) ForLabel(label string) Metrics {
+	if m.byLabel[label] == nil {
+		m.byLabel[label] = &recordingMetrics{}
+	}
+	return m.byLabel[label]
+}
+
+func TestSendTransactionLabel(t *testing.T) {
+	var (
+		logger  = newFieldsLogger()
+		metrics = &labeledMetrics{byLabel: make(map[string]*recordingMetrics)}
+		sends   int
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(context.Context, *coretypes.Transaction) error {
+			if sends++; sends%2 == 1 {
+				return errRPCUnavailable
+			}
+			return nil
+		},
+		WithMetrics(metrics),
+	)
+	s.Setup(s.chain, logger)
+
+	// The label is included in the logs and metrics of the labeled send.
+	ctx := WithLabel(context.Background(), "rebalance-job")
+	_, err := s.SendTransaction(ctx, newTestTx(0), []string{"msg"})
+	require.NoError(t, err)
+	require.Equal(t, "rebalance-job", logger.field("failed to send tx, retrying...", "label"))
+	require.Equal(t, []string{"msg"}, logger.field("failed to send tx, retrying...", "msgIDs"))
+	require.Equal(t, []string{RetryReasonOther}, metrics.byLabel["rebalance-job"].retries)
+	require.Len(t, metrics.byLabel["rebalance-job"].latencies, 1)
+	require.Empty(t, metrics.retries)
+
+	// Sends without a label aren't labeled.
+	_, err = s.SendTransaction(context.Background(), newTestTx(1), []string{"other"})
+	require.NoError(t, err)
+	require.Nil(t, logger.field("failed to send tx, retrying...", "label"))
+	require.Equal(t, []string{RetryReasonOther}, metrics.retries)
+	require.Len(t, metrics.byLabel, 1)
+}
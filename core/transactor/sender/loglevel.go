@@ -0,0 +1,34 @@
+package sender
+
+import "github.com/berachain/offchain-sdk/log"
+
+// LogLevel is the level at which the Sender logs an event.
+type LogLevel = log.Level
+
+// Levels at which the Sender may log an event.
+const (
+	LogLevelDebug = log.LevelDebug
+	LogLevelInfo  = log.LevelInfo
+	LogLevelWarn  = log.LevelWarn
+	LogLevelError = log.LevelError
+)
+
+// Default levels at which failed sends that will be retried are logged.
+const (
+	defaultExpectedRetryLogLevel   = LogLevelWarn
+	defaultUnexpectedRetryLogLevel = LogLevelError
+)
+
+// logAt logs the message with the key/value pairs at the given level.
+func logAt(logger log.Logger, level LogLevel, msg string, keyVals ...any) {
+	switch level {
+	case LogLevelDebug:
+		logger.Debug(msg, keyVals...)
+	case LogLevelInfo:
+		logger.Info(msg, keyVals...)
+	case LogLevelWarn:
+		logger.Warn(msg, keyVals...)
+	default:
+		logger.Error(msg, keyVals...)
+	}
+}
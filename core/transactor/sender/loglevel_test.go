@@ -0,0 +1,82 @@
+package sender
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// levelLogger is a log.Logger that captures the level of each log, by message.
+type levelLogger struct {
+	mu     sync.Mutex
+	levels map[string][]LogLevel
+}
+
+func (l *levelLogger) log(level LogLevel, msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.levels == nil {
+		l.levels = make(map[string][]LogLevel)
+	}
+	l.levels[msg] = append(l.levels[msg], level)
+}
+
+func (l *levelLogger) Debug(msg string, _ ...any) { l.log(LogLevelDebug, msg) }
+func (l *levelLogger) Info(msg string, _ ...any)  { l.log(LogLevelInfo, msg) }
+func (l *levelLogger) Warn(msg string, _ ...any)  { l.log(LogLevelWarn, msg) }
+func (l *levelLogger) Error(msg string, _ ...any) { l.log(LogLevelError, msg) }
+func (l *levelLogger) With(...any) log.Logger     { return l }
+func (l *levelLogger) Impl() any                  { return l }
+
+func TestRetryLogLevels(t *testing.T) {
+	tests := []struct {
+		name     string
+		sendErr  error
+		opts     []Option
+		expected LogLevel
+	}{
+		{"replace underpriced", txpool.ErrReplaceUnderpriced, nil, LogLevelWarn},
+		{"nonce too low", core.ErrNonceTooLow, nil, LogLevelWarn},
+		{"intrinsic gas too low", core.ErrIntrinsicGas, nil, LogLevelWarn},
+		{"unexpected", errRPCUnavailable, nil, LogLevelError},
+		{
+			"configured expected", txpool.ErrReplaceUnderpriced,
+			[]Option{WithRetryLogLevels(LogLevelDebug, LogLevelWarn)}, LogLevelDebug,
+		},
+		{
+			"configured unexpected", errRPCUnavailable,
+			[]Option{WithRetryLogLevels(LogLevelDebug, LogLevelWarn)}, LogLevelWarn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sends int
+			s := newTestSender(
+				&fixedRetryPolicy{backoff: time.Millisecond},
+				func(context.Context, *coretypes.Transaction) error {
+					if sends++; sends == 1 {
+						return tt.sendErr
+					}
+					return nil
+				},
+				tt.opts...,
+			)
+			logger := &levelLogger{}
+			s.logger = logger
+
+			_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+			require.NoError(t, err)
+			require.Equal(
+				t, []LogLevel{tt.expected}, logger.levels["failed to send tx, retrying..."],
+			)
+		})
+	}
+}
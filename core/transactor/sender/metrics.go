@@ -0,0 +1,42 @@
+package sender
+
+import "time"
+
+// Retry reasons reported to Metrics.IncRetry.
+const (
+	RetryReasonNonceTooLow        = "nonce_too_low"
+	RetryReasonReplaceUnderpriced = "replace_underpriced"
+	RetryReasonIntrinsicGas       = "intrinsic_gas_too_low"
+	RetryReasonUnderpriced        = "underpriced"
+	RetryReasonOther              = "other"
+)
+
+var _ Metrics = noopMetrics{}
+
+// noopMetrics is the default Metrics implementation, which does nothing.
+type noopMetrics struct{}
+
+func (noopMetrics) IncRetry(string) {}
+
+func (noopMetrics) IncReplacement() {}
+
+func (noopMetrics) ObserveSendLatency(time.Duration) {}
+
+// retryReason returns the reason to report for retrying a tx that errored with an error of the
+// given class.
+func retryReason(class ErrorClass) string {
+	switch class {
+	case ErrorClassNonceTooLow:
+		return RetryReasonNonceTooLow
+	case ErrorClassReplaceUnderpriced:
+		return RetryReasonReplaceUnderpriced
+	case ErrorClassIntrinsicGasTooLow:
+		return RetryReasonIntrinsicGas
+	case ErrorClassUnderpriced:
+		return RetryReasonUnderpriced
+	case ErrorClassUnknown, ErrorClassAlreadyKnown, ErrorClassInsufficientFunds:
+		return RetryReasonOther
+	default:
+		return RetryReasonOther
+	}
+}
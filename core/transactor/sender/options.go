@@ -0,0 +1,150 @@
+package sender
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option is a functional option for configuring a Sender.
+type Option func(*Sender)
+
+// WithMetrics sets the metrics hooks that the Sender reports send outcomes to.
+func WithMetrics(metrics Metrics) Option {
+	return func(s *Sender) {
+		s.metrics = metrics
+	}
+}
+
+// WithMaxConcurrentSends limits the number of txs that may be sent (or retrying) at once to n.
+// Further calls to SendTransaction block until a send finishes. If n <= 0, sends are unlimited.
+func WithMaxConcurrentSends(n int) Option {
+	return func(s *Sender) {
+		if n > 0 {
+			s.sendSlots = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithOnPermanentFailure sets a hook that is called when a tx fails permanently, e.g. to push its
+// messages to a dead-letter queue. The hook runs synchronously on the sending goroutine, before
+// SendTransaction returns, so it should not block for long. It is not called if the tx failed
+// because the context given to SendTransaction was done. The error is a *SendError; a tx whose
+// sender has insufficient funds fails without retrying, with an error matching
+// ErrInsufficientFunds.
+func WithOnPermanentFailure(hook FailureHook) Option {
+	return func(s *Sender) {
+		s.onPermanentFailure = hook
+	}
+}
+
+// WithOnRetry sets a hook that is called each time a tx is retried, before backing off. The hook
+// runs synchronously on the sending goroutine, so it must not block.
+func WithOnRetry(hook RetryHook) Option {
+	return func(s *Sender) {
+		s.onRetry = hook
+	}
+}
+
+// WithBeforeSend sets a hook that is called with each signed tx right before it is broadcast,
+// including replacements on retries, e.g. to audit log the tx or to forward it elsewhere. If the
+// hook returns an error, the tx is not broadcast and SendTransaction fails (without retrying)
+// with ErrSendAborted. The hook runs synchronously on the sending goroutine.
+func WithBeforeSend(hook BeforeSendHook) Option {
+	return func(s *Sender) {
+		s.beforeSend = hook
+	}
+}
+
+// WithPrivateSender makes the Sender send txs (including replacements on retries) through the
+// private sender instead of the public mempool, e.g. to avoid front-running. If fallbackToPublic
+// is true, a tx is sent publicly if the private sender can't be reached.
+func WithPrivateSender(privateSender PrivateSender, fallbackToPublic bool) Option {
+	return func(s *Sender) {
+		s.privateSender, s.privateFallback = privateSender, fallbackToPublic
+	}
+}
+
+// WithDryRun makes the Sender go through the whole flow of sending txs without ever broadcasting
+// them, e.g. for staging. Each tx that would have been broadcast is passed to the sink (if not
+// nil) instead, and is considered sent successfully, so txs are never retried or replaced.
+func WithDryRun(sink DryRunSink) Option {
+	return func(s *Sender) {
+		s.dryRun, s.dryRunSink = true, sink
+	}
+}
+
+// WithSendStateStore sets a store that the Sender records sending message IDs to, in addition to
+// keeping them in memory. Store errors are logged and don't fail the send.
+func WithSendStateStore(store SendStateStore) Option {
+	return func(s *Sender) {
+		s.stateStore = store
+	}
+}
+
+// WithWaitForDuplicateSends makes SendTransaction wait until any prior send of the same message
+// IDs finishes, rather than failing with ErrAlreadySending.
+func WithWaitForDuplicateSends() Option {
+	return func(s *Sender) {
+		s.waitForDuplicates = true
+	}
+}
+
+// WithEventBufferSize sets the number of tx events (see Sender.Events) buffered for a slow
+// consumer, beyond which events are dropped. If n <= 0, defaults to 256.
+func WithEventBufferSize(n int) Option {
+	return func(s *Sender) {
+		if n > 0 {
+			s.events = make(chan TxEvent, n)
+		}
+	}
+}
+
+// WithStateTTL sets how long the terminal state (sent or failed) of a message is retained, for
+// Sender.State. If ttl <= 0, defaults to 5 minutes.
+func WithStateTTL(ttl time.Duration) Option {
+	return func(s *Sender) {
+		if ttl > 0 {
+			s.terminalStates.ttl = ttl
+		}
+	}
+}
+
+// WithRetryLogLevels sets the levels at which failed sends that will be retried are logged: the
+// expected level for errors that routinely occur while sending txs (e.g. a replacement being
+// underpriced or a nonce being too low), and the unexpected level for all other errors. Defaults
+// to LogLevelWarn and LogLevelError respectively.
+func WithRetryLogLevels(expected, unexpected LogLevel) Option {
+	return func(s *Sender) {
+		s.expectedRetryLevel, s.unexpectedRetryLevel = expected, unexpected
+	}
+}
+
+// WithErrorClassifier adds a classifier of send errors (e.g. for the quirks of an RPC provider),
+// which is consulted before the default classifier and any previously added classifiers. Errors
+// it classifies as ErrorClassUnknown fall through to the other classifiers.
+func WithErrorClassifier(classifier ErrorClassifier) Option {
+	return func(s *Sender) {
+		s.classifier = chainedClassifier{classifier, s.classifier}
+	}
+}
+
+// WithTracer sets the tracer that sends are traced with. Each SendTransaction is a span (a child
+// of any span in its context) with a child span per send attempt, and retries and replacements
+// are events of the send span. Defaults to a no-op tracer.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(s *Sender) {
+		s.tracer = tracer
+	}
+}
+
+// WithClock sets the clock that the Sender tells the time with (e.g. for the send latencies, the
+// retention of terminal states, the circuit breaker and the budget of a DeadlineRetryPolicy) and
+// times the backoffs between retries with. It defaults to the system's clock; tests may use a
+// FakeClock to control time.
+func WithClock(clock Clock) Option {
+	return func(s *Sender) {
+		s.clock = clock
+		s.terminalStates.now = clock.Now
+	}
+}
@@ -0,0 +1,93 @@
+package sender
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/berachain/offchain-sdk/core/transactor/types"
+
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// retryOverridesKey is the context key of the retry overrides of a send.
+type retryOverridesKey struct{}
+
+// WithRetryOverrides returns a copy of the context that overrides the retry policy for the sends
+// made with it, e.g. with the overrides of the requests batched into the tx (see
+// types.Requests.RetryOverrides). The zero value of each override falls back to the policy.
+func WithRetryOverrides(ctx context.Context, overrides *types.RetryOverrides) context.Context {
+	return context.WithValue(ctx, retryOverridesKey{}, overrides)
+}
+
+// RetryOverridesFromContext returns the retry overrides carried by the context, or nil if there
+// are none.
+func RetryOverridesFromContext(ctx context.Context) *types.RetryOverrides {
+	overrides, _ := ctx.Value(retryOverridesKey{}).(*types.RetryOverrides)
+	return overrides
+}
+
+// retryDecision applies the retry overrides (if any) to the decision of the retry policy on
+// retrying a tx after the given number of attempts. If the policy is exhausted but the overrides
+// allow more attempts, the tx is retried after the policy's last backoff (or 500ms if none).
+func (s *Sender) retryDecision(
+	overrides *types.RetryOverrides, attempts int, retry bool, backoff, lastBackoff time.Duration,
+) (bool, time.Duration) {
+	if overrides == nil {
+		return retry, backoff
+	}
+	if overrides.MaxAttempts > 0 {
+		exhausted := !retry
+		if retry = attempts < overrides.MaxAttempts; retry && exhausted {
+			backoff = lastBackoff
+			if backoff == 0 {
+				backoff = backoffStart
+			}
+		}
+	}
+	if !retry || overrides.Deadline.IsZero() {
+		return retry, backoff
+	}
+
+	// Never retry after the deadline, shortening the last backoff to end at it.
+	remaining := overrides.Deadline.Sub(s.clock.Now())
+	if remaining <= 0 {
+		return false, 0
+	}
+	return true, min(backoff, remaining)
+}
+
+// replacementPolicyFor returns the replacement policy with the gas price ceiling replaced by the
+// given max gas price, if not nil.
+func replacementPolicyFor(p TxReplacementPolicy, maxGasPrice *big.Int) TxReplacementPolicy {
+	if maxGasPrice == nil {
+		return p
+	}
+	if d, ok := p.(*defaultTxReplacementPolicy); ok {
+		withCeiling := *d
+		withCeiling.maxGasPrice = maxGasPrice
+		return &withCeiling
+	}
+	return &cappedReplacementPolicy{TxReplacementPolicy: p, maxGasPrice: maxGasPrice}
+}
+
+// cappedReplacementPolicy clamps the replacements of a (custom) replacement policy to a max gas
+// price. Once a tx is at the max gas price, replacing it with a higher one fails with
+// ErrGasPriceCeiling (see also capReplacement).
+type cappedReplacementPolicy struct {
+	TxReplacementPolicy
+	maxGasPrice *big.Int
+}
+
+func (c *cappedReplacementPolicy) GetNew(
+	tx *coretypes.Transaction, err error, class ErrorClass,
+) (*coretypes.Transaction, error) {
+	newTx, err := c.TxReplacementPolicy.GetNew(tx, err, class)
+	if err != nil || newTx == nil || newTx.GasFeeCap().Cmp(c.maxGasPrice) <= 0 {
+		return newTx, err
+	}
+	if tx.GasFeeCap().Cmp(c.maxGasPrice) >= 0 {
+		return nil, ErrGasPriceCeiling
+	}
+	return capReplacement(tx, newTx, c.maxGasPrice, class)
+}
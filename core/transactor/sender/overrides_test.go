@@ -0,0 +1,164 @@
+package sender
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/core/transactor/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestRetryOverridesMaxAttempts(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		overrides *types.RetryOverrides
+		attempts  int
+	}{
+		{name: "policy", attempts: 2},
+		{name: "unset", overrides: &types.RetryOverrides{}, attempts: 2},
+		{name: "more", overrides: &types.RetryOverrides{MaxAttempts: 5}, attempts: 5},
+		{name: "fewer", overrides: &types.RetryOverrides{MaxAttempts: 1}, attempts: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// The policy retries once.
+			s := newTestSender(
+				NewLinearRetryPolicy(1, time.Millisecond, time.Millisecond),
+				func(context.Context, *coretypes.Transaction) error { return errRPCUnavailable },
+			)
+
+			ctx := context.Background()
+			if tc.overrides != nil {
+				ctx = WithRetryOverrides(ctx, tc.overrides)
+			}
+			_, err := s.SendTransaction(ctx, newTestTx(0), nil)
+			var sendErr *SendError
+			require.ErrorAs(t, err, &sendErr)
+			require.ErrorIs(t, err, errRPCUnavailable)
+			require.Equal(t, tc.attempts, sendErr.Attempts)
+		})
+	}
+}
+
+func TestRetryOverridesDeadline(t *testing.T) {
+	var (
+		clock    = NewFakeClock(time.Unix(0, 0))
+		attempts = make(chan time.Time, 10)
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Second},
+		func(context.Context, *coretypes.Transaction) error {
+			attempts <- clock.Now()
+			return errRPCUnavailable
+		},
+		WithClock(clock),
+	)
+
+	sent := make(chan error, 1)
+	ctx := WithRetryOverrides(context.Background(), &types.RetryOverrides{
+		Deadline: time.Unix(0, 0).Add(2500 * time.Millisecond),
+	})
+	go func() {
+		_, err := s.SendTransaction(ctx, newTestTx(0), nil)
+		sent <- err
+	}()
+
+	// The last backoff is shortened to end at the deadline, after which the tx isn't retried.
+	for _, backoff := range []time.Duration{time.Second, time.Second, 500 * time.Millisecond} {
+		require.Eventually(t, func() bool { return clock.Waiters() == 1 },
+			time.Second, time.Millisecond)
+		clock.Advance(backoff)
+	}
+	require.ErrorIs(t, <-sent, errRPCUnavailable)
+
+	close(attempts)
+	var times []time.Duration
+	for at := range attempts {
+		times = append(times, at.Sub(time.Unix(0, 0)))
+	}
+	require.Equal(t, []time.Duration{
+		0, time.Second, 2 * time.Second, 2500 * time.Millisecond,
+	}, times)
+}
+
+func TestRetryOverridesMaxGasPrice(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		overrides *types.RetryOverrides
+		feeCaps   []int64
+		err       error
+	}{
+		{name: "ceiling", feeCaps: []int64{2e9, 2.2e9}, err: ErrGasPriceCeiling},
+		{
+			name:      "higher",
+			overrides: &types.RetryOverrides{MaxGasPrice: big.NewInt(3e9)},
+			feeCaps:   []int64{2e9, 2.3e9, 2.645e9, 3e9},
+			err:       ErrGasPriceCeiling,
+		},
+		{
+			// Clamped to 2.1 gwei, the bump is too small to replace the tx, so it isn't sent.
+			name:      "lower",
+			overrides: &types.RetryOverrides{MaxGasPrice: big.NewInt(2.1e9)},
+			feeCaps:   []int64{2e9},
+			err:       ErrInsufficientBump,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				feeCaps []int64
+			)
+			s := newTestSender(
+				&fixedRetryPolicy{backoff: time.Millisecond},
+				func(_ context.Context, tx *coretypes.Transaction) error {
+					mu.Lock()
+					defer mu.Unlock()
+					feeCaps = append(feeCaps, tx.GasFeeCap().Int64())
+					return txpool.ErrReplaceUnderpriced
+				},
+			)
+			s.SetReplacementPolicy(&defaultTxReplacementPolicy{
+				noncer: &mockNoncer{}, bumpPercent: defaultBumpPercent,
+				maxGasPrice: big.NewInt(2.2e9),
+			})
+
+			ctx := context.Background()
+			if tc.overrides != nil {
+				ctx = WithRetryOverrides(ctx, tc.overrides)
+			}
+			_, err := s.SendTransaction(ctx, newTestTx(0), nil)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.feeCaps, feeCaps)
+		})
+	}
+}
+
+// doublingReplacementPolicy is a custom replacement policy that doubles the fees.
+type doublingReplacementPolicy struct{}
+
+func (doublingReplacementPolicy) GetNew(
+	tx *coretypes.Transaction, _ error, _ ErrorClass,
+) (*coretypes.Transaction, error) {
+	return bumpGas(tx, 100, nil), nil //nolint:gomnd // doubles.
+}
+
+func TestCappedReplacementPolicy(t *testing.T) {
+	// The replacements of a custom policy are clamped to the max gas price.
+	p := replacementPolicyFor(doublingReplacementPolicy{}, big.NewInt(3e9))
+	tx, err := p.GetNew(newTestTx(0), txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(3e9), tx.GasFeeCap())
+
+	_, err = p.GetNew(tx, txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+	require.ErrorIs(t, err, ErrGasPriceCeiling)
+
+	// Clamping a replacement below the txpool's minimum bump fails.
+	p = replacementPolicyFor(doublingReplacementPolicy{}, big.NewInt(2.1e9))
+	_, err = p.GetNew(newTestTx(0), txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+	require.ErrorIs(t, err, ErrInsufficientBump)
+}
@@ -0,0 +1,177 @@
+package sender
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// defaultQueueSize is the default max number of sends queued (or sending) in a SendQueue.
+	defaultQueueSize = 256
+	// defaultQueueWorkers is the default number of txs a SendQueue sends concurrently.
+	defaultQueueWorkers = 10
+)
+
+// SendResult is the result of a send queued in a SendQueue, as returned by SendTransaction.
+type SendResult struct {
+	Hash common.Hash
+	Err  error
+}
+
+// SendQueue is a bounded queue of txs that are sent through a Sender by a fixed number of workers.
+// Txs from the same account are sent one at a time in the order they were enqueued, so that they
+// reach the chain in nonce order; txs from different accounts are sent concurrently.
+type SendQueue struct {
+	sender  *Sender
+	workers int
+	slots   chan struct{}       // held by each queued or sending tx, bounding the queue
+	ready   chan common.Address // accounts with queued txs and no tx sending
+
+	mu     sync.Mutex
+	queues map[common.Address]*accountQueue
+	err    error // set once the queue is stopped, failing further enqueues
+}
+
+// accountQueue is the queue of txs from an account.
+type accountQueue struct {
+	sends   []*queuedSend
+	sending bool // whether a tx from the account is sending
+}
+
+type queuedSend struct {
+	tx     *coretypes.Transaction
+	msgIDs []string
+	result chan SendResult
+}
+
+// NewSendQueue creates a SendQueue that holds at most size txs (queued or sending) and sends up to
+// workers txs concurrently through the sender. If size or workers is <= 0, it defaults to 256 or
+// 10 respectively. The queue must be started to send txs.
+func NewSendQueue(sender *Sender, size, workers int) *SendQueue {
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	if workers <= 0 {
+		workers = defaultQueueWorkers
+	}
+	return &SendQueue{
+		sender:  sender,
+		workers: workers,
+		slots:   make(chan struct{}, size),
+		// Each account is ready at most once and holds at least 1 slot, so this never blocks.
+		ready:  make(chan common.Address, size),
+		queues: make(map[common.Address]*accountQueue),
+	}
+}
+
+// Start starts the workers, which send the queued txs with the given context until it is done.
+// Once the context is done, the queue is drained: each tx still queued receives a SendResult with
+// the context's error (releasing its slot), and further Enqueues fail with the error. Txs that are
+// already sending receive the result of their send.
+func (q *SendQueue) Start(ctx context.Context) {
+	for i := 0; i < q.workers; i++ {
+		go q.work(ctx)
+	}
+}
+
+// Enqueue queues the signed tx with the given message IDs to be sent, blocking while the queue is
+// full (or until the context is done). The returned channel receives the result of sending the tx
+// once it's sent, or fails to send, through the Sender's SendTransaction. Fails with the error of
+// the context the queue was started with once it's done (see Start).
+func (q *SendQueue) Enqueue(
+	ctx context.Context, tx *coretypes.Transaction, msgIDs []string,
+) (<-chan SendResult, error) {
+	from, err := txFrom(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	send := &queuedSend{tx: tx, msgIDs: msgIDs, result: make(chan SendResult, 1)}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.err != nil {
+		<-q.slots
+		return nil, q.err
+	}
+	aq, ok := q.queues[from]
+	if !ok {
+		aq = &accountQueue{}
+		q.queues[from] = aq
+	}
+	aq.sends = append(aq.sends, send)
+	if !aq.sending && len(aq.sends) == 1 {
+		q.ready <- from
+	}
+	return send.result, nil
+}
+
+// work sends the next queued tx of each ready account, until the context is done. Then it drains
+// the queue.
+func (q *SendQueue) work(ctx context.Context) {
+	for {
+		select {
+		case from := <-q.ready:
+			q.sendNext(ctx, from)
+		case <-ctx.Done():
+			q.drain(ctx.Err())
+			return
+		}
+	}
+}
+
+// drain fails the queued txs with the given error, releasing their slots, and stops the queue
+// from accepting more txs.
+func (q *SendQueue) drain(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.err = err
+	for from, aq := range q.queues {
+		for _, send := range aq.sends {
+			send.result <- SendResult{Err: err}
+			<-q.slots
+		}
+		aq.sends = nil
+		if !aq.sending {
+			delete(q.queues, from)
+		}
+	}
+}
+
+// sendNext sends the next queued tx of the account, then readies the account again if it has
+// more queued txs.
+func (q *SendQueue) sendNext(ctx context.Context, from common.Address) {
+	q.mu.Lock()
+	aq, ok := q.queues[from]
+	if !ok || len(aq.sends) == 0 {
+		// The queue was drained after the account was readied.
+		q.mu.Unlock()
+		return
+	}
+	send := aq.sends[0]
+	aq.sends = aq.sends[1:]
+	aq.sending = true
+	q.mu.Unlock()
+
+	hash, err := q.sender.SendTransaction(ctx, send.tx, send.msgIDs)
+	send.result <- SendResult{Hash: hash, Err: err}
+	<-q.slots
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	aq.sending = false
+	if len(aq.sends) == 0 {
+		delete(q.queues, from)
+		return
+	}
+	q.ready <- from
+}
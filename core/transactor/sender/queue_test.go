@@ -0,0 +1,189 @@
+package sender
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestAccount returns the address of a new account and a func that returns test txs signed by
+// the account.
+func newTestAccount(t *testing.T) (common.Address, func(nonce uint64) *coretypes.Transaction) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return crypto.PubkeyToAddress(key.PublicKey), func(nonce uint64) *coretypes.Transaction {
+		return signTestTx(t, key, newTestTx(nonce))
+	}
+}
+
+func signTestTx(
+	t *testing.T, key *ecdsa.PrivateKey, tx *coretypes.Transaction,
+) *coretypes.Transaction {
+	t.Helper()
+	signed, err := coretypes.SignTx(tx, coretypes.LatestSignerForChainID(big.NewInt(1)), key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestSendQueueOrderPerAccount(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		nonces = make(map[common.Address][]uint64)
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{},
+		func(_ context.Context, tx *coretypes.Transaction) error {
+			from, err := txFrom(tx)
+			require.NoError(t, err)
+			time.Sleep(time.Millisecond) // gives later txs the chance to overtake
+			mu.Lock()
+			defer mu.Unlock()
+			nonces[from] = append(nonces[from], tx.Nonce())
+			return nil
+		},
+	)
+	q := NewSendQueue(s, 0, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	// Interleave the txs of 2 accounts.
+	addr1, newTx1 := newTestAccount(t)
+	addr2, newTx2 := newTestAccount(t)
+	var results []<-chan SendResult
+	for nonce := uint64(1); nonce <= 10; nonce++ {
+		for _, newTx := range []func(uint64) *coretypes.Transaction{newTx1, newTx2} {
+			result, err := q.Enqueue(ctx, newTx(nonce), nil)
+			require.NoError(t, err)
+			results = append(results, result)
+		}
+	}
+	for _, result := range results {
+		require.NoError(t, (<-result).Err)
+	}
+
+	expected := []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	require.Equal(t, expected, nonces[addr1])
+	require.Equal(t, expected, nonces[addr2])
+}
+
+func TestSendQueueParallelAcrossAccounts(t *testing.T) {
+	addr1, newTx1 := newTestAccount(t)
+	_, newTx2 := newTestAccount(t)
+
+	// The tx of account 1 only sends once the tx of account 2 has, which requires them to be
+	// sent concurrently.
+	sent2 := make(chan struct{})
+	s := newTestSender(
+		&fixedRetryPolicy{},
+		func(ctx context.Context, tx *coretypes.Transaction) error {
+			from, err := txFrom(tx)
+			require.NoError(t, err)
+			if from != addr1 {
+				close(sent2)
+				return nil
+			}
+			select {
+			case <-sent2:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	)
+	q := NewSendQueue(s, 0, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	q.Start(ctx)
+
+	result1, err := q.Enqueue(ctx, newTx1(1), nil)
+	require.NoError(t, err)
+	result2, err := q.Enqueue(ctx, newTx2(1), nil)
+	require.NoError(t, err)
+	require.NoError(t, (<-result2).Err)
+	require.NoError(t, (<-result1).Err)
+}
+
+func TestSendQueueBackpressure(t *testing.T) {
+	unblock := make(chan struct{})
+	s := newTestSender(
+		&fixedRetryPolicy{},
+		func(context.Context, *coretypes.Transaction) error {
+			<-unblock
+			return nil
+		},
+	)
+	q := NewSendQueue(s, 2, 1)
+	q.Start(context.Background())
+	_, newTx := newTestAccount(t)
+
+	// The queue holds 2 txs, so enqueuing a 3rd blocks until one is sent.
+	result, err := q.Enqueue(context.Background(), newTx(1), nil)
+	require.NoError(t, err)
+	_, err = q.Enqueue(context.Background(), newTx(2), nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = q.Enqueue(ctx, newTx(3), nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unblock <- struct{}{}
+	require.NoError(t, (<-result).Err)
+	_, err = q.Enqueue(context.Background(), newTx(3), nil)
+	require.NoError(t, err)
+	close(unblock)
+}
+
+func TestSendQueueUnsignedTx(t *testing.T) {
+	q := NewSendQueue(newTestSender(&fixedRetryPolicy{}, nil), 0, 0)
+	_, err := q.Enqueue(context.Background(), newTestTx(1), nil)
+	require.Error(t, err)
+}
+
+func TestSendQueueDrain(t *testing.T) {
+	sending := make(chan struct{})
+	s := newTestSender(
+		&fixedRetryPolicy{},
+		func(ctx context.Context, _ *coretypes.Transaction) error {
+			close(sending)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	)
+	q := NewSendQueue(s, 3, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+	_, newTx := newTestAccount(t)
+
+	// The 1st tx is sending while the others are queued behind it.
+	var results []<-chan SendResult
+	for nonce := uint64(1); nonce <= 3; nonce++ {
+		result, err := q.Enqueue(context.Background(), newTx(nonce), nil)
+		require.NoError(t, err)
+		results = append(results, result)
+	}
+	<-sending
+
+	// Once the queue's context is done, the queued txs fail with its error and free their slots.
+	cancel()
+	for _, result := range results {
+		require.ErrorIs(t, (<-result).Err, context.Canceled)
+	}
+	require.Eventually(t, func() bool { return len(q.slots) == 0 }, time.Second, time.Millisecond)
+
+	// Further txs aren't queued.
+	_, err := q.Enqueue(context.Background(), newTx(4), nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, q.slots)
+}
@@ -0,0 +1,65 @@
+package sender
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// defaultReceiptCacheSize is the default number of receipts of mined txs that are cached.
+	defaultReceiptCacheSize = 1024
+	// defaultReceiptCacheTTL is how long a receipt is cached; kept short, since a reorg may drop
+	// the tx from its block.
+	defaultReceiptCacheTTL = 30 * time.Second
+)
+
+// receiptCache caches the receipts of recently mined txs by hash.
+type receiptCache = expirable.LRU[common.Hash, *coretypes.Receipt]
+
+// newReceiptCache returns an empty receipt cache.
+func newReceiptCache() *receiptCache {
+	return expirable.NewLRU[common.Hash, *coretypes.Receipt](
+		defaultReceiptCacheSize, nil, defaultReceiptCacheTTL,
+	)
+}
+
+// Receipt returns the receipt of the tx with the given hash, or ethereum.NotFound if the tx is
+// not mined (yet). Receipts of mined txs are cached briefly, so looking up a tx the Sender just
+// found to be mined (e.g. while sending it) doesn't query the chain again. A nil receipt returned
+// by the chain is treated as not found and isn't cached.
+func (s *Sender) Receipt(ctx context.Context, hash common.Hash) (*coretypes.Receipt, error) {
+	if receipt, ok := s.receipts.Get(hash); ok {
+		return receipt, nil
+	}
+	return s.fetchReceipt(ctx, hash)
+}
+
+// fetchReceipt fetches the receipt of the tx with the given hash from the chain, bypassing the
+// cache (e.g. to notice a reorg), and caches it.
+func (s *Sender) fetchReceipt(ctx context.Context, hash common.Hash) (*coretypes.Receipt, error) {
+	receipt, err := s.chain.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	s.receipts.Add(hash, receipt)
+	return receipt, nil
+}
+
+// minedReceipt returns the receipt of the tx if it's already mined, or nil if it isn't (or its
+// receipt can't be looked up).
+func (s *Sender) minedReceipt(ctx context.Context, hash common.Hash) *coretypes.Receipt {
+	receipt, err := s.Receipt(ctx, hash)
+	if err != nil {
+		return nil
+	}
+	return receipt
+}
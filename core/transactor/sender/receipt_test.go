@@ -0,0 +1,127 @@
+package sender
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSendTransactionAlreadyMined(t *testing.T) {
+	for _, sendErr := range []error{txpool.ErrAlreadyKnown, core.ErrNonceTooLow} {
+		var sends int
+		s := newTestSender(
+			&fixedRetryPolicy{backoff: time.Millisecond},
+			func(context.Context, *coretypes.Transaction) error {
+				sends++
+				return sendErr
+			},
+		)
+		chain := s.chain.(*mockClient)
+		tx := newTestTx(0)
+		chain.mine(tx.Hash(), 10)
+
+		// The tx is found to be mined, so it's sent successfully without being retried.
+		hash, err := s.SendTransaction(context.Background(), tx, nil)
+		require.NoError(t, err)
+		require.Equal(t, tx.Hash(), hash)
+		require.Equal(t, 1, sends)
+		require.Equal(t, 1, chain.receiptCalls)
+
+		// Its receipt is cached.
+		receipt, err := s.Receipt(context.Background(), hash)
+		require.NoError(t, err)
+		require.Equal(t, hash, receipt.TxHash)
+		require.Equal(t, 1, chain.receiptCalls)
+	}
+}
+
+func TestSendTransactionNonceTooLowNotMined(t *testing.T) {
+	var sent []*coretypes.Transaction
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(_ context.Context, tx *coretypes.Transaction) error {
+			if sent = append(sent, tx); len(sent) == 1 {
+				return core.ErrNonceTooLow
+			}
+			return nil
+		},
+	)
+
+	// The tx isn't mined, so it's replaced with the next nonce.
+	_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+	require.NoError(t, err)
+	require.Len(t, sent, 2)
+	require.NotEqual(t, sent[0].Hash(), sent[1].Hash())
+
+	// Txs that aren't mined are looked up again, since they may be mined later.
+	_, err = s.Receipt(context.Background(), sent[1].Hash())
+	require.ErrorIs(t, err, ethereum.NotFound)
+	s.chain.(*mockClient).mine(sent[1].Hash(), 10)
+	receipt, err := s.Receipt(context.Background(), sent[1].Hash())
+	require.NoError(t, err)
+	require.Equal(t, sent[1].Hash(), receipt.TxHash)
+}
+
+func TestReceiptNil(t *testing.T) {
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(context.Context, *coretypes.Transaction) error { return nil },
+	)
+	chain := s.chain.(*mockClient)
+	tx := newTestTx(0)
+	chain.receipts = map[common.Hash]*coretypes.Receipt{tx.Hash(): nil}
+
+	// A nil receipt is treated as not found and isn't cached.
+	_, err := s.Receipt(context.Background(), tx.Hash())
+	require.ErrorIs(t, err, ethereum.NotFound)
+	_, err = s.Receipt(context.Background(), tx.Hash())
+	require.ErrorIs(t, err, ethereum.NotFound)
+	require.Equal(t, 2, chain.receiptCalls)
+
+	// Waiting on the tx keeps polling (instead of panicking) until the context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*receiptPollInterval)
+	defer cancel()
+	_, err = s.SendAndWait(ctx, tx, nil, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSendAndWaitReorg(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	s := newTestSender(&fixedRetryPolicy{}, nil, WithClock(clock))
+	chain := s.chain.(*mockClient)
+	tx := newTestTx(0)
+
+	// The tx was mined (and its receipt cached), but then reorged into a later block.
+	chain.mine(tx.Hash(), 10)
+	_, err := s.Receipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	chain.mine(tx.Hash(), 12)
+
+	waited := make(chan *coretypes.Receipt, 1)
+	go func() {
+		receipt, _ := s.waitConfirmed(context.Background(), tx.Hash(), 3)
+		waited <- receipt
+	}()
+
+	// The confirmations are counted from the tx's new block, not from the cached receipt's.
+	require.Eventually(t, func() bool { return clock.Waiters() == 1 },
+		time.Second, time.Millisecond)
+	chain.mu.Lock()
+	chain.blockNumber = 14
+	chain.mu.Unlock()
+	clock.Advance(receiptPollInterval)
+	select {
+	case receipt := <-waited:
+		require.EqualValues(t, 12, receipt.BlockNumber.Uint64())
+	case <-time.After(time.Second):
+		require.FailNow(t, "receipt not confirmed")
+	}
+}
@@ -1,47 +1,102 @@
 package sender
 
 import (
-	"errors"
-	"strings"
+	"fmt"
+	"math/big"
 
-	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/txpool"
 	coretypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/core/vm"
 )
 
-var _ txReplacementPolicy = (*defaultTxReplacementPolicy)(nil)
+var _ TxReplacementPolicy = (*defaultTxReplacementPolicy)(nil)
 
 // defaultTxReplacementPolicy is the default transaction replacement policy. It bumps the gas price
-// by 15% (only 10% is required but we add a buffer to be safe) and generates a replacement 1559
-// dynamic fee transaction.
+// by 15% by default (only 10% is required but we add a buffer to be safe) and generates a
+// replacement 1559 dynamic fee transaction. If a min bump is set, the gas price is bumped by at
+// least that many wei, so that bumps of very low gas prices still clear the node's minimum. If a
+// max gas price is set, bumps are clamped to it and once a tx is at the max gas price, replacing
+// it fails with ErrGasPriceCeiling (or ErrInsufficientBump if the clamped bump is too small to
+// replace the pending tx). If the gas limit of a tx is too low, it is bumped by the gas limit
+// margin.
+//
+// A tx that is "replacement underpriced" (ErrorClassReplaceUnderpriced) must outbid the pending tx
+// with the same nonce, whereas a tx that is "underpriced" (ErrorClassUnderpriced) must clear the
+// node's minimum gas price, which may be far above its gas price (e.g. during a fee spike). So the
+// latter may be bumped by a different percentage, which defaults to the replacement bump.
 type defaultTxReplacementPolicy struct {
-	noncer Noncer
+	noncer                 Noncer
+	bumpPercent            int
+	underpricedBumpPercent int      // optional, 0 means bumpPercent applies
+	minBump                *big.Int // optional, nil means only the percentage bump applies
+	maxGasPrice            *big.Int // optional, nil means no ceiling
+	gasLimitMarginPercent  int
 }
 
 func (d *defaultTxReplacementPolicy) GetNew(
-	tx *coretypes.Transaction, err error,
+	tx *coretypes.Transaction, err error, class ErrorClass,
 ) (*coretypes.Transaction, error) {
-	// If the sender is out of balance, return the error.
-	if errors.Is(err, vm.ErrInsufficientBalance) ||
-		(err != nil && strings.Contains(err.Error(), "insufficient balance for transfer")) {
+	var (
+		shouldBumpGas bool
+		bumpPercent   = d.bumpPercent
+	)
+	switch class {
+	case ErrorClassInsufficientFunds:
+		// If the sender is out of balance, return the error.
 		return nil, err
-	}
-
-	// Replace the nonce if the nonce was too low.
-	var shouldBumpGas bool
-	if errors.Is(err, core.ErrNonceTooLow) ||
-		(err != nil && strings.Contains(err.Error(), "nonce too low")) {
-		var newNonce uint64
-		newNonce, shouldBumpGas = d.noncer.Acquire()
-		tx = SetNonce(tx, newNonce)
+	case ErrorClassIntrinsicGasTooLow:
+		// Bump the gas limit if it was too low.
+		return bumpGasLimit(tx, d.gasLimitMarginPercent), nil
+	case ErrorClassNonceTooLow:
+		// Replace the nonce if the nonce was too low, with a fresh nonce of the account that sent
+		// the tx (or of the default account if the tx isn't signed).
+		from, _ := txFrom(tx)
+		newNonce, nonceErr := d.noncer.Acquire(from)
+		if nonceErr != nil {
+			return nil, fmt.Errorf("failed to acquire nonce: %w", nonceErr)
+		}
+		return SetNonce(tx, newNonce), nil
+	case ErrorClassReplaceUnderpriced:
+		shouldBumpGas = true
+	case ErrorClassUnderpriced:
+		shouldBumpGas = true
+		if d.underpricedBumpPercent > 0 {
+			bumpPercent = d.underpricedBumpPercent
+		}
+	case ErrorClassUnknown, ErrorClassAlreadyKnown:
 	}
 
 	// Bump the gas according to the replacement policy if a replacement is required.
-	if shouldBumpGas || errors.Is(err, txpool.ErrReplaceUnderpriced) ||
-		(err != nil && strings.Contains(err.Error(), "replacement transaction underpriced")) {
-		tx = BumpGas(tx)
+	if shouldBumpGas {
+		return d.bumpGas(tx, bumpPercent, class)
 	}
 
 	return tx, nil
 }
+
+// bumpGas bumps the gas on the tx by the given percentage, clamped to the max gas price (if set).
+func (d *defaultTxReplacementPolicy) bumpGas(
+	tx *coretypes.Transaction, percent int, class ErrorClass,
+) (*coretypes.Transaction, error) {
+	if d.maxGasPrice != nil && tx.GasFeeCap().Cmp(d.maxGasPrice) >= 0 {
+		return nil, ErrGasPriceCeiling
+	}
+
+	bumped := bumpGas(tx, percent, d.minBump)
+	if d.maxGasPrice == nil {
+		return bumped, nil
+	}
+	return capReplacement(tx, bumped, d.maxGasPrice, class)
+}
+
+// capReplacement clamps the replacement of the tx to the max gas price. Unless the tx is merely
+// underpriced, the replacement must outbid the pending tx with the same nonce, so if clamping
+// leaves the bump too small for the txpool to accept it, ErrInsufficientBump is returned instead,
+// as sending it would fail the same way again.
+func capReplacement(
+	tx, newTx *coretypes.Transaction, maxGasPrice *big.Int, class ErrorClass,
+) (*coretypes.Transaction, error) {
+	capped := capGasPrice(newTx, maxGasPrice)
+	if class != ErrorClassUnderpriced && !clearsMinBump(tx, capped) {
+		return nil, ErrInsufficientBump
+	}
+	return capped, nil
+}
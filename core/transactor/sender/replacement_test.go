@@ -0,0 +1,291 @@
+package sender
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestReplacementMaxGasPrice(t *testing.T) {
+	d := &defaultTxReplacementPolicy{
+		noncer: &mockNoncer{}, bumpPercent: defaultBumpPercent, maxGasPrice: big.NewInt(2.2e9),
+	}
+
+	// The first bump (2 gwei -> 2.3 gwei) is clamped to the ceiling.
+	tx, err := d.GetNew(newTestTx(0), txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(2.2e9), tx.GasFeeCap())
+	require.Equal(t, big.NewInt(1.15e9), tx.GasTipCap())
+
+	// Once at the ceiling, the tx can no longer be replaced.
+	_, err = d.GetNew(tx, txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+	require.ErrorIs(t, err, ErrGasPriceCeiling)
+}
+
+func TestReplacementClampedBelowMinBump(t *testing.T) {
+	d := &defaultTxReplacementPolicy{
+		noncer: &mockNoncer{}, bumpPercent: defaultBumpPercent, maxGasPrice: big.NewInt(2.1e9),
+	}
+
+	// Clamped to the ceiling, the bump (2 gwei -> 2.1 gwei) can't replace the pending tx.
+	_, err := d.GetNew(newTestTx(0), txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+	require.ErrorIs(t, err, ErrInsufficientBump)
+
+	// An underpriced tx doesn't replace a pending tx, so it is still clamped to the ceiling.
+	tx, err := d.GetNew(newTestTx(0), txpool.ErrUnderpriced, ErrorClassUnderpriced)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(2.1e9), tx.GasFeeCap())
+}
+
+func TestReplacementDynamicFeeMinimums(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		bumpPercent       int
+		gasTipCap, feeCap int64
+	}{
+		{name: "gwei", bumpPercent: defaultBumpPercent, gasTipCap: 1e9, feeCap: 2e9},
+		{name: "1 wei", bumpPercent: defaultBumpPercent, gasTipCap: 1, feeCap: 1},
+		{name: "rounding", bumpPercent: defaultBumpPercent, gasTipCap: 7, feeCap: 19},
+		{name: "below min bump", bumpPercent: 5, gasTipCap: 1e9, feeCap: 2e9},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &defaultTxReplacementPolicy{noncer: &mockNoncer{}, bumpPercent: tc.bumpPercent}
+			to := newTestTx(0).To()
+			tx := coretypes.NewTx(&coretypes.DynamicFeeTx{
+				ChainID:   big.NewInt(1),
+				GasTipCap: big.NewInt(tc.gasTipCap),
+				GasFeeCap: big.NewInt(tc.feeCap),
+				Gas:       21000,
+				To:        to,
+				Value:     big.NewInt(0),
+			})
+
+			replacement, err := d.GetNew(tx, txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+			require.NoError(t, err)
+			require.Equal(t, coretypes.DynamicFeeTxType, int(replacement.Type()))
+			requireReplaceable(t, tx.GasTipCap(), replacement.GasTipCap())
+			requireReplaceable(t, tx.GasFeeCap(), replacement.GasFeeCap())
+		})
+	}
+}
+
+// requireReplaceable asserts that the new fee satisfies the txpool's replacement rules: strictly
+// greater than the old fee and at least minBumpPercent greater.
+func requireReplaceable(t *testing.T, oldFee, newFee *big.Int) {
+	t.Helper()
+	threshold := new(big.Int).Mul(oldFee, big.NewInt(100+minBumpPercent))
+	threshold.Quo(threshold, big.NewInt(100))
+	require.Positive(t, newFee.Cmp(oldFee), "new fee %s must exceed old fee %s", newFee, oldFee)
+	require.GreaterOrEqual(t, newFee.Cmp(threshold), 0, "new fee %s below %s", newFee, threshold)
+}
+
+func TestReplacementLegacyMinimums(t *testing.T) {
+	for _, gasPrice := range []int64{1, 7, 19, 1e9} {
+		d := &defaultTxReplacementPolicy{noncer: &mockNoncer{}, bumpPercent: defaultBumpPercent}
+		for _, txData := range []coretypes.TxData{
+			&coretypes.LegacyTx{GasPrice: big.NewInt(gasPrice), Gas: 21000, To: newTestTx(0).To()},
+			&coretypes.AccessListTx{
+				ChainID: big.NewInt(1), GasPrice: big.NewInt(gasPrice), Gas: 21000,
+				To: newTestTx(0).To(),
+			},
+		} {
+			tx := coretypes.NewTx(txData)
+			replacement, err := d.GetNew(tx, txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+			require.NoError(t, err)
+			require.Equal(t, tx.Type(), replacement.Type())
+			requireReplaceable(t, tx.GasPrice(), replacement.GasPrice())
+		}
+	}
+}
+
+func TestReplacementMinBump(t *testing.T) {
+	// The min bump of 0.1 gwei exceeds the 15% bump of 1 wei, but not that of 1 gwei.
+	minBump := big.NewInt(1e8)
+	for _, tc := range []struct {
+		name             string
+		gasPrice, bumped int64
+	}{
+		{name: "1 wei", gasPrice: 1, bumped: 1e8 + 1},
+		{name: "1 gwei", gasPrice: 1e9, bumped: 1.15e9},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &defaultTxReplacementPolicy{
+				noncer: &mockNoncer{}, bumpPercent: defaultBumpPercent, minBump: minBump,
+			}
+			gasPrice, to := big.NewInt(tc.gasPrice), newTestTx(0).To()
+			for _, txData := range []coretypes.TxData{
+				&coretypes.LegacyTx{GasPrice: gasPrice, Gas: 21000, To: to},
+				&coretypes.DynamicFeeTx{
+					ChainID: big.NewInt(1), GasTipCap: gasPrice, GasFeeCap: gasPrice, Gas: 21000,
+					To: to,
+				},
+			} {
+				tx := coretypes.NewTx(txData)
+				replacement, err := d.GetNew(tx, txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+				require.NoError(t, err)
+				require.Equal(t, tx.Type(), replacement.Type())
+				require.Equal(t, big.NewInt(tc.bumped), replacement.GasTipCap())
+				require.Equal(t, big.NewInt(tc.bumped), replacement.GasFeeCap())
+			}
+		})
+	}
+}
+
+func TestClearsMinBump(t *testing.T) {
+	tx := newTestTx(0)
+	require.True(t, clearsMinBump(tx, bumpGas(tx, minBumpPercent, nil)))
+	require.False(t, clearsMinBump(tx, tx))
+
+	// A bump that is too small for the txpool is caught.
+	tooSmall := coretypes.NewTx(&coretypes.DynamicFeeTx{
+		ChainID:   tx.ChainId(),
+		GasTipCap: new(big.Int).Add(tx.GasTipCap(), big.NewInt(1)),
+		GasFeeCap: new(big.Int).Add(tx.GasFeeCap(), big.NewInt(1)),
+		Gas:       tx.Gas(),
+		To:        tx.To(),
+	})
+	require.False(t, clearsMinBump(tx, tooSmall))
+
+	// Blob txs must clear the blobpool's larger bump.
+	blobTx := newTestBlobTx()
+	require.True(t, clearsMinBump(blobTx, bumpGas(blobTx, defaultBumpPercent, nil)))
+}
+
+func TestReplacementUnderpricedBump(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		sendErr           error
+		underpricedBump   int
+		gasTipCap, feeCap int64
+	}{
+		{name: "replace underpriced", sendErr: txpool.ErrReplaceUnderpriced, underpricedBump: 50,
+			gasTipCap: 1.15e9, feeCap: 2.3e9},
+		{name: "underpriced", sendErr: txpool.ErrUnderpriced, underpricedBump: 50,
+			gasTipCap: 1.5e9, feeCap: 3e9},
+		{name: "underpriced by default", sendErr: txpool.ErrUnderpriced,
+			gasTipCap: 1.15e9, feeCap: 2.3e9},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewFromConfig(&mockFactory{}, &mockNoncer{}, Config{
+				UnderpricedBumpPercent: tc.underpricedBump,
+			})
+			require.NoError(t, err)
+			s.retryPolicy = &fixedRetryPolicy{backoff: time.Millisecond}
+			var sent []*coretypes.Transaction
+			s.Setup(&mockClient{sendFn: func(_ context.Context, tx *coretypes.Transaction) error {
+				if sent = append(sent, tx); len(sent) == 1 {
+					return tc.sendErr
+				}
+				return nil
+			}}, log.NewBlankLogger(io.Discard))
+
+			// The replacement is bumped by the percentage for the error.
+			_, err = s.SendTransaction(context.Background(), newTestTx(0), nil)
+			require.NoError(t, err)
+			require.Len(t, sent, 2)
+			require.Equal(t, big.NewInt(tc.gasTipCap), sent[1].GasTipCap())
+			require.Equal(t, big.NewInt(tc.feeCap), sent[1].GasFeeCap())
+		})
+	}
+}
+
+func TestReplacementKeepsAccessList(t *testing.T) {
+	d := &defaultTxReplacementPolicy{
+		noncer: &mockNoncer{}, bumpPercent: defaultBumpPercent, maxGasPrice: big.NewInt(5e9),
+	}
+	to := newTestTx(0).To()
+	accessList := coretypes.AccessList{{
+		Address: common.HexToAddress("0x2"), StorageKeys: []common.Hash{common.HexToHash("0x3")},
+	}}
+	for _, tx := range []*coretypes.Transaction{
+		coretypes.NewTx(&coretypes.DynamicFeeTx{
+			ChainID: big.NewInt(1), GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9),
+			Gas: 21000, To: to, Value: big.NewInt(0), AccessList: accessList,
+		}),
+		coretypes.NewTx(&coretypes.AccessListTx{
+			ChainID: big.NewInt(1), GasPrice: big.NewInt(2e9), Gas: 21000, To: to,
+			Value: big.NewInt(0), AccessList: accessList,
+		}),
+	} {
+		// Bumped until clamped to the max gas price, the replacements keep the access list.
+		replacement := tx
+		for i := 0; i < 10; i++ {
+			bumped, err := d.GetNew(replacement, txpool.ErrUnderpriced, ErrorClassUnderpriced)
+			if err != nil {
+				require.ErrorIs(t, err, ErrGasPriceCeiling)
+				break
+			}
+			replacement = bumped
+		}
+		require.Equal(t, tx.Type(), replacement.Type())
+		require.Equal(t, big.NewInt(5e9), replacement.GasFeeCap())
+		require.Equal(t, accessList, replacement.AccessList())
+		require.Equal(t, accessList, SetNonce(replacement, 1).AccessList())
+	}
+}
+
+func TestReplacementNonceTooLow(t *testing.T) {
+	noncer := &mockNoncer{nonce: 6}
+	d := &defaultTxReplacementPolicy{noncer: noncer, bumpPercent: defaultBumpPercent}
+	tx := newTestTx(3)
+
+	// The tx gets a fresh nonce, which doesn't replace a pending tx, so its gas isn't bumped.
+	replacement, err := d.GetNew(tx, core.ErrNonceTooLow, ErrorClassNonceTooLow)
+	require.NoError(t, err)
+	require.EqualValues(t, 7, replacement.Nonce())
+	require.Equal(t, tx.GasFeeCap(), replacement.GasFeeCap())
+
+	// If no nonce can be acquired, the tx isn't replaced (rather than sent with nonce 0).
+	noncer.err = errRPCUnavailable
+	_, err = d.GetNew(tx, core.ErrNonceTooLow, ErrorClassNonceTooLow)
+	require.ErrorIs(t, err, errRPCUnavailable)
+}
+
+func TestReplacementBlobTx(t *testing.T) {
+	d := &defaultTxReplacementPolicy{noncer: &mockNoncer{}, bumpPercent: defaultBumpPercent}
+	tx := newTestBlobTx()
+
+	replacement, err := d.GetNew(tx, txpool.ErrReplaceUnderpriced, ErrorClassReplaceUnderpriced)
+	require.NoError(t, err)
+	require.Equal(t, coretypes.BlobTxType, int(replacement.Type()))
+	require.Equal(t, tx.ChainId(), replacement.ChainId())
+	require.Equal(t, tx.BlobHashes(), replacement.BlobHashes())
+	require.Equal(t, tx.BlobTxSidecar(), replacement.BlobTxSidecar())
+
+	// All fee caps are doubled, as required by the blobpool.
+	require.Equal(t, big.NewInt(2e9), replacement.GasTipCap())
+	require.Equal(t, big.NewInt(4e9), replacement.GasFeeCap())
+	require.Equal(t, big.NewInt(6e9), replacement.BlobGasFeeCap())
+}
+
+// newTestBlobTx returns a blob tx carrying a single (empty) blob.
+func newTestBlobTx() *coretypes.Transaction {
+	sidecar := &coretypes.BlobTxSidecar{
+		Blobs:       []kzg4844.Blob{{}},
+		Commitments: []kzg4844.Commitment{{}},
+		Proofs:      []kzg4844.Proof{{}},
+	}
+	return coretypes.NewTx(&coretypes.BlobTx{
+		ChainID:    uint256.NewInt(1),
+		GasTipCap:  uint256.NewInt(1e9),
+		GasFeeCap:  uint256.NewInt(2e9),
+		Gas:        21000,
+		To:         *newTestTx(0).To(),
+		Value:      uint256.NewInt(0),
+		BlobFeeCap: uint256.NewInt(3e9),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+}
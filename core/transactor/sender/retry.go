@@ -3,28 +3,125 @@ package sender
 import (
 	"crypto/rand"
 	"math/big"
+	mrand "math/rand"
 	"sync"
 	"time"
 
 	goutils "github.com/berachain/go-utils/utils"
+	lru "github.com/hashicorp/golang-lru/v2"
 
 	"github.com/ethereum/go-ethereum/common"
 	coretypes "github.com/ethereum/go-ethereum/core/types"
 )
 
 const (
-	maxRetriesPerTx   = 3                      // TODO: read from config.
-	backoffStart      = 500 * time.Millisecond // TODO: read from config.
+	maxRetriesPerTx   = 3                      // default, configurable with NewExpoRetryPolicy.
+	backoffStart      = 500 * time.Millisecond // default, configurable with NewExpoRetryPolicy.
 	backoffMultiplier = 2                      // TODO: read from config.
-	maxBackoff        = 3 * time.Second        // TODO: read from config.
+	maxBackoff        = 30 * time.Second       // default, configurable with SetMaxBackoff.
 	jitterRange       = 1000                   // TODO: read from config.
+
+	// historySize is the number of txs whose history is kept once they are done sending.
+	historySize = 1024
 )
 
 var (
-	_ retryPolicy = (*noRetryPolicy)(nil)
-	_ retryPolicy = (*expoRetryPolicy)(nil)
+	_ RetryPolicy = (*noRetryPolicy)(nil)
+	_ RetryPolicy = (*ExpoRetryPolicy)(nil)
+	_ RetryPolicy = (*LinearRetryPolicy)(nil)
+	_ RetryPolicy = (*DeadlineRetryPolicy)(nil)
+
+	_ retryHooks = (*noRetryPolicy)(nil)
+	_ retryHooks = (*ExpoRetryPolicy)(nil)
+	_ retryHooks = (*LinearRetryPolicy)(nil)
+	_ retryHooks = (*DeadlineRetryPolicy)(nil)
+
+	_ sendScopedPolicy = (*ExpoRetryPolicy)(nil)
+	_ sendScopedPolicy = (*LinearRetryPolicy)(nil)
+	_ sendScopedPolicy = (*DeadlineRetryPolicy)(nil)
+
+	_ clockedPolicy = (*DeadlineRetryPolicy)(nil)
 )
 
+// retryPolicyForSend returns the retry policy bound to the given send, if the policy counts the
+// retries of each send separately (see sendScopedPolicy), or else the policy itself.
+func retryPolicyForSend(p RetryPolicy, send *sendingTx) RetryPolicy {
+	if sp, ok := p.(sendScopedPolicy); ok {
+		return &sendRetryPolicy{policy: sp, send: send}
+	}
+	return p
+}
+
+// sendRetryPolicy is a retry policy of this package bound to a single send, so that concurrent
+// sends of the same tx each get their own retries.
+type sendRetryPolicy struct {
+	policy sendScopedPolicy
+	send   *sendingTx
+}
+
+func (p *sendRetryPolicy) Get(tx *coretypes.Transaction, err error) (bool, time.Duration) {
+	return p.policy.get(p.send, tx, err)
+}
+
+func (p *sendRetryPolicy) UpdateTxModified(oldTx, newTx common.Hash) {
+	p.policy.updateTxModified(p.send, oldTx, newTx)
+}
+
+func (p *sendRetryPolicy) History(txHash common.Hash) []common.Hash {
+	return p.policy.History(txHash)
+}
+
+func (p *sendRetryPolicy) done(txHash common.Hash) {
+	p.policy.doneSend(p.send, txHash)
+}
+
+func (p *sendRetryPolicy) expected(class ErrorClass) bool {
+	return p.policy.expected(class)
+}
+
+func (p *sendRetryPolicy) terminal(class ErrorClass) bool {
+	return p.policy.terminal(class)
+}
+
+// retryDone notifies the retry policy that sending the tx with the given hash ended, if the
+// policy implements retryHooks.
+func retryDone(p RetryPolicy, txHash common.Hash) {
+	if hooks, ok := p.(retryHooks); ok {
+		hooks.done(txHash)
+	}
+}
+
+// retryExpected returns true if send errors of the class are expected by the retry policy, or by
+// default if it doesn't implement retryHooks.
+func retryExpected(p RetryPolicy, class ErrorClass) bool {
+	if hooks, ok := p.(retryHooks); ok {
+		return hooks.expected(class)
+	}
+	return class.expected()
+}
+
+// retryTerminal returns true if send errors of the class are terminal for the retry policy, or by
+// default if it doesn't implement retryHooks.
+func retryTerminal(p RetryPolicy, class ErrorClass) bool {
+	if hooks, ok := p.(retryHooks); ok {
+		return hooks.terminal(class)
+	}
+	return class.terminal()
+}
+
+// BackoffFunc returns the backoff before retrying a tx after its given failed attempt, starting
+// at 1, e.g. for schedules aligned to the chain's block time. Set on a retry policy, it overrides
+// the backoff computed by the policy, while the policy still decides whether to retry.
+type BackoffFunc func(attempt int) time.Duration
+
+// BlockAlignedBackoff returns a BackoffFunc that retries once per block, since a (replacement) tx
+// can't be included before the next block anyway.
+func BlockAlignedBackoff(blockTime time.Duration) BackoffFunc {
+	return func(int) time.Duration {
+		return blockTime
+	}
+}
+
 // noRetryPolicy does not retry transactions.
 type noRetryPolicy struct{}
 
@@ -34,56 +131,419 @@ func (*noRetryPolicy) Get(*coretypes.Transaction, error) (bool, time.Duration) {
 
 func (*noRetryPolicy) UpdateTxModified(common.Hash, common.Hash) {}
 
-// expoRetryPolicy is a RetryPolicy that does an exponential backoff until maxRetries is
+func (*noRetryPolicy) History(common.Hash) []common.Hash {
+	return nil
+}
+
+func (*noRetryPolicy) done(common.Hash) {}
+
+func (*noRetryPolicy) expected(class ErrorClass) bool {
+	return class.expected()
+}
+
+func (*noRetryPolicy) terminal(class ErrorClass) bool {
+	return class.terminal()
+}
+
+// ExpoRetryPolicy is a RetryPolicy that does an exponential backoff until maxRetries is
 // reached. This does not assume anything about whether the specifc tx should be retried.
-type expoRetryPolicy struct {
-	retries sync.Map
+type ExpoRetryPolicy struct {
+	maxRetries  int           // if <= 0, txs are retried indefinitely
+	baseBackoff time.Duration // backoff before the first retry
+	maxBackoff  time.Duration // cap on the backoff (including jitter)
+
+	// if > 0, the backoff is randomized within the last jitterFraction of the computed window
+	jitterFraction float64
+	rng            *mrand.Rand // per-policy source of randomness for jitterFraction
+	rngMu          sync.Mutex
+
+	txRetries
+}
+
+// NewExpoRetryPolicy creates a new exponential retry policy. Each send is retried at most
+// maxRetries times, starting with a backoff of baseBackoff, which doubles on each retry up to
+// 30s. Passing a maxRetries of 0 retries indefinitely.
+func NewExpoRetryPolicy(maxRetries int, baseBackoff time.Duration) *ExpoRetryPolicy {
+	return &ExpoRetryPolicy{
+		maxRetries: maxRetries, baseBackoff: baseBackoff, maxBackoff: maxBackoff,
+	}
+}
+
+// SetMaxBackoff caps the backoff at the given duration instead of the default 30s. It must be set
+// before the policy is used. The cap includes the jitter, which is never added on top of it.
+func (erp *ExpoRetryPolicy) SetMaxBackoff(backoff time.Duration) {
+	erp.maxBackoff = backoff
+}
+
+// SetJitterFraction randomizes each backoff within [(1 - fraction) * backoff, backoff], which
+// avoids many senders retrying in lockstep. A fraction of 1 is "full" jitter and 0.5 is "equal"
+// jitter. The fraction is clamped to [0, 1]; 0 (the default) keeps the standard jitter of up to
+// 1s added to the backoff (within the max backoff).
+func (erp *ExpoRetryPolicy) SetJitterFraction(fraction float64) {
+	erp.rngMu.Lock()
+	defer erp.rngMu.Unlock()
+
+	erp.jitterFraction = min(max(fraction, 0), 1)
+	if erp.rng == nil {
+		erp.rng = mrand.New(mrand.NewSource(time.Now().UnixNano())) //nolint:gosec // its okay.
+	}
 }
 
-func (erp *expoRetryPolicy) Get(tx *coretypes.Transaction, err error) (bool, time.Duration) {
-	var (
-		txHash = tx.Hash()
-		tri    *txRetryInfo
-		jitter time.Duration
-	)
+func (erp *ExpoRetryPolicy) Get(tx *coretypes.Transaction, err error) (bool, time.Duration) {
+	return erp.get(nil, tx, err)
+}
 
+func (erp *ExpoRetryPolicy) get(
+	send *sendingTx, tx *coretypes.Transaction, err error,
+) (bool, time.Duration) {
+	key := retryKey{send: send, hash: tx.Hash()}
 	// If the retry error is nil, the transaction was retried successfully.
 	if err == nil {
-		erp.retries.Delete(txHash)
+		erp.finishKey(key)
 		return false, 0
 	}
 
-	txri, found := erp.retries.Load(txHash)
-	if !found {
-		tri = &txRetryInfo{backoff: backoffStart}
-		erp.retries.Store(txHash, tri)
-	} else if tri = goutils.MustGetAs[*txRetryInfo](txri); tri.numRetries >= maxRetriesPerTx {
-		erp.retries.Delete(txHash)
+	tri, ok := erp.next(key, min(erp.baseBackoff, erp.maxBackoff), erp.maxRetries)
+	if !ok {
 		return false, 0
 	}
-	tri.numRetries++
+	defer tri.mu.Unlock()
+
+	// Exponential backoff with jitter, unless overridden.
+	waitTime, ok := erp.customBackoff(tri)
+	if !ok {
+		waitTime = erp.jitter(tri.backoff)
+	}
+	tri.backoff = multiplyBackoff(tri.backoff, backoffMultiplier, erp.maxBackoff)
+
+	return true, waitTime
+}
 
-	// Exponential backoff with jitter.
+// jitter returns the backoff with jitter applied, never exceeding the max backoff.
+func (erp *ExpoRetryPolicy) jitter(backoff time.Duration) time.Duration {
+	erp.rngMu.Lock()
+	defer erp.rngMu.Unlock()
+
+	if erp.jitterFraction > 0 {
+		window := erp.jitterFraction * float64(backoff)
+		return backoff - time.Duration(erp.rng.Float64()*window)
+	}
+
+	var jitter time.Duration
 	if random, _ := rand.Int(rand.Reader, big.NewInt(jitterRange)); random != nil {
 		jitter = time.Duration(random.Int64()) * time.Millisecond
 	}
-	waitTime := tri.backoff + jitter
-	if tri.backoff *= backoffMultiplier; tri.backoff > maxBackoff {
-		tri.backoff = maxBackoff
+	if backoff > erp.maxBackoff-jitter {
+		return erp.maxBackoff
+	}
+	return backoff + jitter
+}
+
+// multiplyBackoff returns the backoff multiplied by the multiplier, capped at maxBackoff. Guards
+// against overflowing, which would make the backoff negative (or wrap around).
+func multiplyBackoff(
+	backoff time.Duration, multiplier int64, maxBackoff time.Duration,
+) time.Duration {
+	if backoff > maxBackoff/time.Duration(multiplier) {
+		return maxBackoff
+	}
+	return backoff * time.Duration(multiplier)
+}
+
+// LinearRetryPolicy is a RetryPolicy that increases the backoff by a fixed step on each retry, up
+// to maxBackoff, until maxRetries is reached. This does not assume anything about whether the
+// specific tx should be retried.
+type LinearRetryPolicy struct {
+	maxRetries int           // if <= 0, txs are retried indefinitely
+	step       time.Duration // backoff before the first retry and increase on each retry
+	maxBackoff time.Duration // cap on the backoff
+
+	txRetries
+}
+
+// NewLinearRetryPolicy creates a new linear retry policy. Each send is retried at most maxRetries
+// times, waiting step before the first retry and step longer before each subsequent retry, never
+// more than maxBackoff. Passing a maxRetries of 0 retries indefinitely.
+func NewLinearRetryPolicy(maxRetries int, step, maxBackoff time.Duration) *LinearRetryPolicy {
+	return &LinearRetryPolicy{maxRetries: maxRetries, step: step, maxBackoff: maxBackoff}
+}
+
+func (lrp *LinearRetryPolicy) Get(tx *coretypes.Transaction, err error) (bool, time.Duration) {
+	return lrp.get(nil, tx, err)
+}
+
+func (lrp *LinearRetryPolicy) get(
+	send *sendingTx, tx *coretypes.Transaction, err error,
+) (bool, time.Duration) {
+	key := retryKey{send: send, hash: tx.Hash()}
+	// If the retry error is nil, the transaction was retried successfully.
+	if err == nil {
+		lrp.finishKey(key)
+		return false, 0
+	}
+
+	tri, ok := lrp.next(key, min(lrp.step, lrp.maxBackoff), lrp.maxRetries)
+	if !ok {
+		return false, 0
+	}
+	defer tri.mu.Unlock()
+
+	waitTime, ok := lrp.customBackoff(tri)
+	if !ok {
+		waitTime = tri.backoff
+	}
+	if tri.backoff > lrp.maxBackoff-lrp.step {
+		// Avoids overflowing with a large step.
+		tri.backoff = lrp.maxBackoff
+	} else {
+		tri.backoff += lrp.step
 	}
 
 	return true, waitTime
 }
 
-func (erp *expoRetryPolicy) UpdateTxModified(oldTx, newTx common.Hash) {
-	if txri, found := erp.retries.Load(oldTx); found {
-		erp.retries.Delete(oldTx)
-		erp.retries.Store(newTx, txri)
+// DeadlineRetryPolicy is a RetryPolicy that retries with an exponential backoff until a
+// wall-clock budget from the first failed attempt is exceeded, regardless of the number of
+// retries. The last backoff is shortened to end at the deadline. This does not assume anything
+// about whether the specific tx should be retried.
+type DeadlineRetryPolicy struct {
+	budget      time.Duration
+	baseBackoff time.Duration // backoff before the first retry
+	maxBackoff  time.Duration // cap on the backoff
+	now         func() time.Time
+
+	txRetries
+}
+
+// NewDeadlineRetryPolicy creates a new deadline retry policy. Each send is retried until budget
+// has elapsed since its first failed attempt, starting with a backoff of 500ms, which doubles on
+// each retry up to 30s.
+func NewDeadlineRetryPolicy(budget time.Duration) *DeadlineRetryPolicy {
+	return &DeadlineRetryPolicy{
+		budget: budget, baseBackoff: backoffStart, maxBackoff: maxBackoff, now: time.Now,
+	}
+}
+
+// SetMaxBackoff caps the backoff at the given duration instead of the default 30s. It must be set
+// before the policy is used.
+func (drp *DeadlineRetryPolicy) SetMaxBackoff(backoff time.Duration) {
+	drp.maxBackoff = backoff
+}
+
+// setClock makes the policy tell the time with the given clock instead of the system's.
+func (drp *DeadlineRetryPolicy) setClock(clock Clock) {
+	drp.now = clock.Now
+}
+
+func (drp *DeadlineRetryPolicy) Get(tx *coretypes.Transaction, err error) (bool, time.Duration) {
+	return drp.get(nil, tx, err)
+}
+
+func (drp *DeadlineRetryPolicy) get(
+	send *sendingTx, tx *coretypes.Transaction, err error,
+) (bool, time.Duration) {
+	key := retryKey{send: send, hash: tx.Hash()}
+	// If the retry error is nil, the transaction was retried successfully.
+	if err == nil {
+		drp.finishKey(key)
+		return false, 0
+	}
+
+	tri, _ := drp.next(key, min(drp.baseBackoff, drp.maxBackoff), 0)
+	defer tri.mu.Unlock()
+	now := drp.now()
+	if tri.started.IsZero() {
+		tri.started = now
+	}
+	remaining := tri.started.Add(drp.budget).Sub(now)
+	if remaining <= 0 {
+		drp.finish(key, tri)
+		return false, 0
+	}
+
+	waitTime, ok := drp.customBackoff(tri)
+	if !ok {
+		waitTime = tri.backoff
+	}
+	waitTime = min(waitTime, remaining)
+	tri.backoff = multiplyBackoff(tri.backoff, backoffMultiplier, drp.maxBackoff)
+
+	return true, waitTime
+}
+
+// txRetries tracks the retry info of txs that are being sent, keyed by the send and the latest
+// tx hash, so that concurrent sends of the same tx are retried independently. Sends through the
+// policy's exported methods rather than the Sender share a nil send. Once a tx is done sending,
+// its history is kept among the historySize most recently done txs.
+type txRetries struct {
+	retries     sync.Map    // retryKey -> *txRetryInfo
+	backoffFunc BackoffFunc // overrides the policy's backoff, may be nil
+
+	histories     *lru.Cache[common.Hash, []common.Hash] // of done txs, by their latest hash
+	historiesOnce sync.Once
+}
+
+// retryKey identifies the retries of a tx, by its latest hash, within a send.
+type retryKey struct {
+	send *sendingTx
+	hash common.Hash
+}
+
+// SetBackoffFunc overrides the backoff computed by the policy with the given schedule (see
+// BackoffFunc). It must be set before the policy is used.
+func (tr *txRetries) SetBackoffFunc(fn BackoffFunc) {
+	tr.backoffFunc = fn
+}
+
+// customBackoff returns the backoff from the backoff func for the tx's latest retry, or false if
+// no backoff func is set.
+func (tr *txRetries) customBackoff(tri *txRetryInfo) (time.Duration, bool) {
+	if tr.backoffFunc == nil {
+		return 0, false
 	}
+	return tr.backoffFunc(tri.numRetries), true
+}
+
+// next returns the retry info for the given tx, tracking it with the initial backoff if not yet
+// tracked, and counts a retry. The retry info is returned locked, so the caller must unlock it
+// once done computing the backoff. Returns false (and stops tracking the tx) if the tx has already
+// been retried maxRetries times. A maxRetries <= 0 allows unlimited retries.
+func (tr *txRetries) next(
+	key retryKey, initialBackoff time.Duration, maxRetries int,
+) (*txRetryInfo, bool) {
+	txri, found := tr.retries.Load(key)
+	if !found {
+		txri, _ = tr.retries.LoadOrStore(
+			key, &txRetryInfo{backoff: initialBackoff, hashes: []common.Hash{key.hash}},
+		)
+	}
+	tri := goutils.MustGetAs[*txRetryInfo](txri)
+
+	tri.mu.Lock()
+	if maxRetries > 0 && tri.numRetries >= maxRetries {
+		tr.finish(key, tri)
+		tri.mu.Unlock()
+		return nil, false
+	}
+	tri.numRetries++
+
+	return tri, true
+}
+
+// done stops tracking the retry info of the given tx, keeping its history.
+func (tr *txRetries) done(txHash common.Hash) {
+	tr.doneSend(nil, txHash)
+}
+
+// doneSend stops tracking the retry info of the given tx within the send, keeping its history.
+func (tr *txRetries) doneSend(send *sendingTx, txHash common.Hash) {
+	tr.finishKey(retryKey{send: send, hash: txHash})
+}
+
+// finishKey stops tracking the retry info with the given key (if tracked), keeping its history.
+func (tr *txRetries) finishKey(key retryKey) {
+	if txri, found := tr.retries.Load(key); found {
+		tri := goutils.MustGetAs[*txRetryInfo](txri)
+		tri.mu.Lock()
+		defer tri.mu.Unlock()
+		tr.finish(key, tri)
+	}
+}
+
+// finish stops tracking the retry info with the given key, keeping its history. Requires tri.mu
+// to be held.
+func (tr *txRetries) finish(key retryKey, tri *txRetryInfo) {
+	tr.retries.Delete(key)
+	tr.finished().Add(key.hash, append([]common.Hash(nil), tri.hashes...))
+}
+
+// finished returns the histories of the txs that are done sending.
+func (tr *txRetries) finished() *lru.Cache[common.Hash, []common.Hash] {
+	tr.historiesOnce.Do(func() {
+		tr.histories, _ = lru.New[common.Hash, []common.Hash](historySize)
+	})
+	return tr.histories
+}
+
+// expected returns true if send errors of the class routinely occur while sending txs and are
+// handled by replacing the tx.
+func (*txRetries) expected(class ErrorClass) bool {
+	return class.expected()
+}
+
+// terminal returns true if send errors of the class can't be resolved by retrying the tx, e.g.
+// the sender having insufficient funds, for which bumping the gas would only make it worse.
+func (*txRetries) terminal(class ErrorClass) bool {
+	return class.terminal()
+}
+
+// UpdateTxModified moves the retry info of the old tx to the new tx.
+func (tr *txRetries) UpdateTxModified(oldTx, newTx common.Hash) {
+	tr.updateTxModified(nil, oldTx, newTx)
+}
+
+// updateTxModified moves the retry info of the old tx to the new tx within the send.
+func (tr *txRetries) updateTxModified(send *sendingTx, oldTx, newTx common.Hash) {
+	oldKey := retryKey{send: send, hash: oldTx}
+	if txri, found := tr.retries.LoadAndDelete(oldKey); found {
+		tri := goutils.MustGetAs[*txRetryInfo](txri)
+		tri.mu.Lock()
+		tri.hashes = append(tri.hashes, newTx)
+		tri.mu.Unlock()
+
+		tr.retries.Store(retryKey{send: send, hash: newTx}, tri)
+	}
+}
+
+// History returns the hashes that the tx with the given (latest) hash has been sent as, in order,
+// ending with the given hash. Returns nil if the tx was never retried. Once sending the tx ends,
+// its history is kept until historySize more txs are done sending.
+func (tr *txRetries) History(txHash common.Hash) []common.Hash {
+	tri := tr.sending(txHash)
+	if tri == nil {
+		if hashes, ok := tr.finished().Peek(txHash); ok {
+			return append([]common.Hash(nil), hashes...)
+		}
+		return nil
+	}
+
+	tri.mu.Lock()
+	defer tri.mu.Unlock()
+	return append([]common.Hash(nil), tri.hashes...)
+}
+
+// NumRetries returns the number of times the tx with the given (latest) hash has been retried, or
+// 0 if it is not being retried.
+func (tr *txRetries) NumRetries(txHash common.Hash) int {
+	tri := tr.sending(txHash)
+	if tri == nil {
+		return 0
+	}
+
+	tri.mu.Lock()
+	defer tri.mu.Unlock()
+	return tri.numRetries
+}
+
+// sending returns the retry info of a send of the tx with the given (latest) hash, or nil if the
+// tx is not being retried.
+func (tr *txRetries) sending(txHash common.Hash) *txRetryInfo {
+	var tri *txRetryInfo
+	tr.retries.Range(func(key, txri any) bool {
+		if goutils.MustGetAs[retryKey](key).hash != txHash {
+			return true
+		}
+		tri = goutils.MustGetAs[*txRetryInfo](txri)
+		return false
+	})
+	return tri
 }
 
 // txRetryInfo contains the necessary information to determine if a transaction should be retried.
 type txRetryInfo struct {
-	numRetries int
-	backoff    time.Duration
+	mu         sync.Mutex    // protects the fields, which may be accessed concurrently
+	numRetries int           // number of retries so far
+	hashes     []common.Hash // hashes the tx has been sent as, in order
+	backoff    time.Duration // backoff before the next retry
+	started    time.Time     // time of the first failed attempt, for the DeadlineRetryPolicy
 }
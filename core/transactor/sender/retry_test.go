@@ -0,0 +1,372 @@
+package sender
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestExpoRetryPolicyMaxRetries(t *testing.T) {
+	tx := newTestTx(0)
+
+	erp := NewExpoRetryPolicy(2, time.Millisecond)
+	for i := 0; i < 2; i++ {
+		retry, _ := erp.Get(tx, errRPCUnavailable)
+		require.True(t, retry)
+	}
+	retry, _ := erp.Get(tx, errRPCUnavailable)
+	require.False(t, retry)
+
+	// The retry count is reset for the next send of the same tx.
+	retry, _ = erp.Get(tx, errRPCUnavailable)
+	require.True(t, retry)
+
+	// A max retries of 0 retries indefinitely.
+	unlimited := NewExpoRetryPolicy(0, time.Millisecond)
+	for i := 0; i < 100; i++ {
+		retry, _ = unlimited.Get(tx, errRPCUnavailable)
+		require.True(t, retry)
+	}
+}
+
+func TestRetryPolicyConcurrentSends(t *testing.T) {
+	// The same tx is sent twice concurrently, and each send is retried twice.
+	var (
+		started = make(chan struct{})
+		sends   sync.WaitGroup
+	)
+	sends.Add(2)
+	s := newTestSender(
+		NewLinearRetryPolicy(2, time.Millisecond, time.Millisecond),
+		func(context.Context, *coretypes.Transaction) error {
+			<-started
+			return errRPCUnavailable
+		},
+	)
+	errs := make([]error, 2)
+	for i := range errs {
+		go func(i int) {
+			defer sends.Done()
+			_, errs[i] = s.SendTransaction(context.Background(), newTestTx(0), nil)
+		}(i)
+	}
+	close(started)
+	sends.Wait()
+
+	for _, err := range errs {
+		var sendErr *SendError
+		require.ErrorAs(t, err, &sendErr)
+		require.Equal(t, 3, sendErr.Attempts)
+	}
+}
+
+func TestRetryPolicyConcurrentFirstFailures(t *testing.T) {
+	// The first failures of a tx are all counted, however they race.
+	tx := newTestTx(0)
+	erp := NewExpoRetryPolicy(0, time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			erp.Get(tx, errRPCUnavailable)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 50, erp.NumRetries(tx.Hash()))
+}
+
+func TestLinearRetryPolicy(t *testing.T) {
+	var (
+		tx  = newTestTx(0)
+		lrp = NewLinearRetryPolicy(6, time.Second, 4*time.Second)
+	)
+
+	// The backoff increases by the step each retry, up to the max backoff.
+	for _, expected := range []time.Duration{
+		1 * time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second, 4 * time.Second,
+	} {
+		retry, backoff := lrp.Get(tx, errRPCUnavailable)
+		require.True(t, retry)
+		require.Equal(t, expected, backoff)
+	}
+
+	// The attempt history carries over to a replaced tx, until max retries is reached.
+	replaced := newTestTx(1)
+	lrp.UpdateTxModified(tx.Hash(), replaced.Hash())
+	retry, backoff := lrp.Get(replaced, errRPCUnavailable)
+	require.True(t, retry)
+	require.Equal(t, 4*time.Second, backoff)
+	retry, _ = lrp.Get(replaced, errRPCUnavailable)
+	require.False(t, retry)
+}
+
+func TestDeadlineRetryPolicy(t *testing.T) {
+	var (
+		tx      = newTestTx(0)
+		drp     = NewDeadlineRetryPolicy(2 * time.Minute)
+		now     = time.Unix(0, 0)
+		retries int
+	)
+	drp.now = func() time.Time { return now }
+
+	// Retries continue (regardless of the number of retries) until the budget is exceeded, with
+	// the last backoff ending at the deadline.
+	for {
+		retry, backoff := drp.Get(tx, errRPCUnavailable)
+		if !retry {
+			break
+		}
+		require.LessOrEqual(t, backoff, maxBackoff)
+		now = now.Add(backoff)
+		retries++
+	}
+	require.Equal(t, time.Unix(0, 0).Add(2*time.Minute), now)
+	require.Greater(t, retries, maxRetriesPerTx)
+
+	// The budget restarts for the next send of the same tx.
+	retry, backoff := drp.Get(tx, errRPCUnavailable)
+	require.True(t, retry)
+	require.Equal(t, backoffStart, backoff)
+
+	// The budget carries over to a replaced tx.
+	replaced := newTestTx(1)
+	drp.UpdateTxModified(tx.Hash(), replaced.Hash())
+	now = now.Add(2 * time.Minute)
+	retry, _ = drp.Get(replaced, errRPCUnavailable)
+	require.False(t, retry)
+}
+
+func TestDeadlineRetryPolicyMaxBackoff(t *testing.T) {
+	drp := NewDeadlineRetryPolicy(time.Hour)
+	drp.SetMaxBackoff(2 * time.Second)
+	drp.now = func() time.Time { return time.Unix(0, 0) }
+
+	// The backoff doubles up to the configured cap instead of the default one.
+	tx := newTestTx(0)
+	var backoffs []time.Duration
+	for i := 0; i < 5; i++ {
+		retry, backoff := drp.Get(tx, errRPCUnavailable)
+		require.True(t, retry)
+		backoffs = append(backoffs, backoff)
+	}
+	require.Equal(t, []time.Duration{
+		backoffStart, time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second,
+	}, backoffs)
+}
+
+func TestExpoRetryPolicyJitterFraction(t *testing.T) {
+	tx := newTestTx(0)
+
+	for _, fraction := range []float64{0.5, 1} {
+		var (
+			erp     = NewExpoRetryPolicy(0, time.Second)
+			backoff = time.Second
+			seen    = make(map[time.Duration]struct{})
+		)
+		erp.SetJitterFraction(fraction)
+
+		for i := 0; i < 3; i++ {
+			retry, waitTime := erp.Get(tx, errRPCUnavailable)
+			require.True(t, retry)
+			require.LessOrEqual(t, waitTime, backoff)
+			require.GreaterOrEqual(t, waitTime, time.Duration((1-fraction)*float64(backoff)))
+			seen[waitTime] = struct{}{}
+			backoff = min(2*backoff, maxBackoff)
+		}
+		require.Greater(t, len(seen), 1)
+	}
+}
+
+func TestExpoRetryPolicyMaxBackoff(t *testing.T) {
+	for _, tc := range []struct {
+		baseBackoff, maxBackoff time.Duration
+	}{
+		{baseBackoff: time.Millisecond, maxBackoff: 30 * time.Second},
+		{baseBackoff: time.Hour, maxBackoff: 30 * time.Second},
+		// Doubling the backoff would overflow before reaching the cap.
+		{baseBackoff: time.Nanosecond, maxBackoff: math.MaxInt64},
+		{baseBackoff: math.MaxInt64 / 3, maxBackoff: math.MaxInt64},
+	} {
+		erp := NewExpoRetryPolicy(0, tc.baseBackoff)
+		erp.SetMaxBackoff(tc.maxBackoff)
+		tx := newTestTx(0)
+
+		var last time.Duration
+		for i := 0; i < 200; i++ {
+			retry, waitTime := erp.Get(tx, errRPCUnavailable)
+			require.True(t, retry)
+			require.Positive(t, waitTime)
+			require.LessOrEqual(t, waitTime, tc.maxBackoff) // the jitter is within the cap
+			last = waitTime
+		}
+		require.Equal(t, tc.maxBackoff, last)
+	}
+
+	// With a jitter fraction, the backoff never exceeds the cap.
+	erp := NewExpoRetryPolicy(0, time.Second)
+	erp.SetMaxBackoff(5 * time.Second)
+	erp.SetJitterFraction(0.5)
+	tx := newTestTx(0)
+	for i := 0; i < 200; i++ {
+		_, waitTime := erp.Get(tx, errRPCUnavailable)
+		require.Positive(t, waitTime)
+		require.LessOrEqual(t, waitTime, 5*time.Second)
+	}
+}
+
+func TestLinearRetryPolicyStepAboveMax(t *testing.T) {
+	// A step larger than the cap never waits longer than the cap, starting with the first retry.
+	lrp := NewLinearRetryPolicy(0, 5*time.Second, 2*time.Second)
+	tx := newTestTx(0)
+	for i := 0; i < 3; i++ {
+		retry, waitTime := lrp.Get(tx, errRPCUnavailable)
+		require.True(t, retry)
+		require.Equal(t, 2*time.Second, waitTime)
+	}
+}
+
+func TestLinearRetryPolicyLargeStep(t *testing.T) {
+	lrp := NewLinearRetryPolicy(0, math.MaxInt64/3, math.MaxInt64)
+	tx := newTestTx(0)
+	for i := 0; i < 10; i++ {
+		_, waitTime := lrp.Get(tx, errRPCUnavailable)
+		require.Positive(t, waitTime)
+	}
+}
+
+func TestRetryPolicyBackoffFunc(t *testing.T) {
+	var attempts []int
+	backoff := func(attempt int) time.Duration {
+		attempts = append(attempts, attempt)
+		return time.Duration(attempt) * time.Millisecond
+	}
+	erp := NewExpoRetryPolicy(3, time.Second)
+	lrp := NewLinearRetryPolicy(3, time.Second, time.Minute)
+	drp := NewDeadlineRetryPolicy(time.Hour)
+
+	for _, policy := range []interface {
+		RetryPolicy
+		retryHooks
+		SetBackoffFunc(BackoffFunc)
+	}{erp, lrp, drp} {
+		attempts = nil
+		policy.SetBackoffFunc(backoff)
+
+		// The backoff func is called with the number of each failed attempt, including across
+		// replacements of the tx.
+		tx := newTestTx(0)
+		for attempt := 1; attempt <= 3; attempt++ {
+			retry, waitTime := policy.Get(tx, errRPCUnavailable)
+			require.True(t, retry)
+			require.Equal(t, time.Duration(attempt)*time.Millisecond, waitTime)
+
+			replaced := newTestTx(uint64(attempt))
+			policy.UpdateTxModified(tx.Hash(), replaced.Hash())
+			tx = replaced
+		}
+		require.Equal(t, []int{1, 2, 3}, attempts)
+
+		// Once the tx is sent, the attempts start over for the next tx.
+		policy.done(tx.Hash())
+		_, _ = policy.Get(newTestTx(10), errRPCUnavailable)
+		require.Equal(t, []int{1, 2, 3, 1}, attempts)
+	}
+
+	// The policy still decides whether to retry.
+	attempts = nil
+	tx := newTestTx(0)
+	for i := 0; i < 3; i++ {
+		_, _ = erp.Get(tx, errRPCUnavailable)
+	}
+	retry, _ := erp.Get(tx, errRPCUnavailable)
+	require.False(t, retry)
+	require.Equal(t, []int{1, 2, 3}, attempts)
+}
+
+func TestBlockAlignedBackoff(t *testing.T) {
+	s, err := NewFromConfig(&mockFactory{}, &mockNoncer{}, Config{
+		RetryPolicy: RetryPolicyLinear, MaxRetries: 5, Backoff: BlockAlignedBackoff(12 * time.Second),
+	})
+	require.NoError(t, err)
+
+	tx := newTestTx(0)
+	for i := 0; i < 5; i++ {
+		retry, waitTime := s.retryPolicy.Get(tx, errRPCUnavailable)
+		require.True(t, retry)
+		require.Equal(t, 12*time.Second, waitTime)
+	}
+}
+
+func TestRetryHistory(t *testing.T) {
+	var (
+		hashes []common.Hash
+		sends  int
+		lrp    = NewLinearRetryPolicy(0, time.Millisecond, time.Millisecond)
+	)
+	s := newTestSender(lrp, func(_ context.Context, tx *coretypes.Transaction) error {
+		hashes = append(hashes, tx.Hash())
+		if sends++; sends <= 2 {
+			return txpool.ErrReplaceUnderpriced
+		}
+
+		// While the tx is sending, its history is the chain of replaced hashes.
+		require.Equal(t, hashes, lrp.History(tx.Hash()))
+		require.Equal(t, 2, lrp.NumRetries(tx.Hash()))
+		return nil
+	})
+
+	hash, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+	require.NoError(t, err)
+	require.Len(t, hashes, 3)
+
+	// Once the send completes, the tx is no longer tracked but its history is kept.
+	require.Zero(t, lrp.NumRetries(hash))
+	require.Equal(t, hashes, lrp.History(hash))
+	require.Equal(t, hashes, s.History(hash))
+
+	// The history is also kept when a send fails.
+	s.txReplacementPolicy = &defaultTxReplacementPolicy{
+		noncer: &mockNoncer{}, bumpPercent: defaultBumpPercent, maxGasPrice: big.NewInt(1),
+	}
+	sends, hashes = 0, nil
+	_, err = s.SendTransaction(context.Background(), newTestTx(1), nil)
+	require.ErrorIs(t, err, ErrGasPriceCeiling)
+	lrp.retries.Range(func(any, any) bool {
+		t.Fatal("retry info was not dropped")
+		return false
+	})
+	var sendErr *SendError
+	require.ErrorAs(t, err, &sendErr)
+	require.Equal(t, hashes, s.History(sendErr.Hash))
+}
+
+func TestRetryHistoryEviction(t *testing.T) {
+	lrp := NewLinearRetryPolicy(0, time.Millisecond, time.Millisecond)
+	retry := func(nonce uint64) common.Hash {
+		tx := newTestTx(nonce)
+		_, _ = lrp.Get(tx, errRPCUnavailable)
+		lrp.done(tx.Hash())
+		return tx.Hash()
+	}
+
+	// The histories of the most recently done txs are kept.
+	first := retry(0)
+	for nonce := uint64(1); nonce < historySize; nonce++ {
+		retry(nonce)
+	}
+	require.Equal(t, []common.Hash{first}, lrp.History(first))
+
+	// Older histories are evicted.
+	retry(historySize)
+	require.Nil(t, lrp.History(first))
+}
@@ -2,32 +2,122 @@ package sender
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/berachain/offchain-sdk/client/eth"
 	"github.com/berachain/offchain-sdk/core/transactor/types"
 	"github.com/berachain/offchain-sdk/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/ethereum/go-ethereum/common"
 	coretypes "github.com/ethereum/go-ethereum/core/types"
 )
 
+// defaultBatchConcurrency is the default max number of txs sent concurrently by SendTransactions.
+const (
+	defaultBatchConcurrency = 10
+	// maxBuildAttempts is the number of attempts to build a replacement tx before giving up.
+	maxBuildAttempts = 3
+)
+
 // Sender is a component that sends (and retries) transactions to the chain.
 type Sender struct {
 	factory             Factory             // used to rebuild transactions, if necessary
-	txReplacementPolicy txReplacementPolicy // policy to replace transactions
-	retryPolicy         retryPolicy         // policy to retry transactions
+	policyMu            sync.RWMutex        // protects swapping the policies
+	txReplacementPolicy TxReplacementPolicy // policy to replace transactions
+	retryPolicy         RetryPolicy         // policy to retry transactions
+	metrics             Metrics             // hooks to observe send outcomes
+	batchConcurrency    int                 // max concurrent sends in a SendTransactions call
+	perAttemptTimeout   time.Duration       // timeout for each send attempt, 0 means none
+	sendSlots           chan struct{}       // limits concurrent sends, nil means unlimited
+	onPermanentFailure  FailureHook         // called when a tx permanently fails, may be nil
+	onRetry             RetryHook           // called before each retry of a tx, may be nil
+	beforeSend          BeforeSendHook      // called before each broadcast of a tx, may be nil
+	privateSender       PrivateSender       // sends txs privately instead of publicly, may be nil
+	privateFallback     bool                // whether to send publicly if privately fails
+	dryRun              bool                // whether to pass txs to dryRunSink, not broadcast
+	dryRunSink          DryRunSink          // receives txs instead of the chain, may be nil
+	breaker             *circuitBreaker     // fails sends fast while the chain is down, may be nil
+	events              chan TxEvent        // lifecycle events of txs, dropped while full
+	terminalStates      *terminalStates     // retained sent/failed states of msgIDs
+	tracer              trace.Tracer        // traces sends, no-op by default
+	classifier          ErrorClassifier     // classifies send errors
+	receipts            *receiptCache       // receipts of recently mined txs
+	clock               Clock               // tells the time and times the backoffs
+
+	sendingTxs        sync.Map       // msgID -> chan closed once its tx is done sending
+	sendingNonces     sync.Map       // accountNonce -> *sendingTx, the tx sending at the nonce
+	waitForDuplicates bool           // whether to wait for, rather than reject, duplicate sends
+	stateStore        SendStateStore // persists sending msgIDs, may be nil
 
-	chain  eth.Client
-	logger log.Logger
+	mu       sync.Mutex     // protects draining and adding to inFlight
+	draining bool           // whether new sends are rejected
+	inFlight sync.WaitGroup // sends accepted and not yet done
+	counters sendCounters   // counts the outcomes of sends, for Status
+
+	chain                eth.Client
+	logger               log.Logger
+	expectedRetryLevel   LogLevel // level of logs for expected retried send errors
+	unexpectedRetryLevel LogLevel // level of logs for other retried send errors
 }
 
 // New creates a new Sender with default replacement and exponential retry policies.
-func New(factory Factory, noncer Noncer) *Sender {
-	return &Sender{
-		factory:             factory,
-		txReplacementPolicy: &defaultTxReplacementPolicy{noncer: noncer},
-		retryPolicy:         &expoRetryPolicy{}, // TODO: choose from config.
+func New(factory Factory, noncer Noncer, opts ...Option) *Sender {
+	s := &Sender{
+		factory: factory,
+		txReplacementPolicy: &defaultTxReplacementPolicy{
+			noncer: noncer, bumpPercent: defaultBumpPercent,
+			gasLimitMarginPercent: defaultGasLimitMarginPercent,
+		},
+		retryPolicy:          NewExpoRetryPolicy(maxRetriesPerTx, backoffStart),
+		metrics:              noopMetrics{},
+		batchConcurrency:     defaultBatchConcurrency,
+		events:               make(chan TxEvent, defaultEventBufferSize),
+		terminalStates:       newTerminalStates(defaultStateTTL),
+		tracer:               defaultTracer,
+		classifier:           DefaultErrorClassifier,
+		receipts:             newReceiptCache(),
+		clock:                realClock{},
+		expectedRetryLevel:   defaultExpectedRetryLogLevel,
+		unexpectedRetryLevel: defaultUnexpectedRetryLogLevel,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.useClock(s.retryPolicy)
+	return s
+}
+
+// NewFromConfig creates a new Sender with the replacement and retry policies selected by the
+// given config.
+func NewFromConfig(factory Factory, noncer Noncer, cfg Config, opts ...Option) (*Sender, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := New(factory, noncer, opts...)
+	s.txReplacementPolicy = &defaultTxReplacementPolicy{
+		noncer: noncer, bumpPercent: cfg.bumpPercent(),
+		underpricedBumpPercent: cfg.UnderpricedBumpPercent, minBump: cfg.minBump(),
+		maxGasPrice: cfg.maxGasPrice(), gasLimitMarginPercent: cfg.gasLimitMarginPercent(),
+	}
+	s.retryPolicy = cfg.retryPolicy()
+	s.useClock(s.retryPolicy)
+	if cfg.BatchConcurrency > 0 {
+		s.batchConcurrency = cfg.BatchConcurrency
+	}
+	s.perAttemptTimeout = cfg.PerAttemptTimeout
+	if cfg.CircuitBreakerThreshold > 0 {
+		s.breaker = newCircuitBreaker(
+			cfg.CircuitBreakerThreshold, cfg.CircuitBreakerWindow, cfg.CircuitBreakerCooldown,
+		)
+		s.breaker.now = s.clock.Now
+	}
+	return s, nil
 }
 
 func (s *Sender) Setup(chain eth.Client, logger log.Logger) {
@@ -35,53 +125,568 @@ func (s *Sender) Setup(chain eth.Client, logger log.Logger) {
 	s.logger = logger
 }
 
-// SendTransaction sends a transaction using the Ethereum client. If the transaction fails to send,
-// it retries based on the configured retry policy.
-func (s *Sender) SendTransaction(ctx context.Context, tx *coretypes.Transaction) error {
-	return s.retryTxWithPolicy(ctx, tx)
+// SetRetryPolicy replaces the retry policy, e.g. to tune retries to the network conditions. Txs
+// that are already sending keep retrying with the policy they started with; new sends use the new
+// policy. A policy that tells the time (e.g. a DeadlineRetryPolicy) is made to tell it with the
+// Sender's clock.
+func (s *Sender) SetRetryPolicy(p RetryPolicy) {
+	s.useClock(p)
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+	s.retryPolicy = p
+}
+
+// useClock makes the retry policy tell the time with the Sender's clock, if it tells the time.
+func (s *Sender) useClock(p RetryPolicy) {
+	if cp, ok := p.(clockedPolicy); ok {
+		cp.setClock(s.clock)
+	}
+}
+
+// SetReplacementPolicy replaces the tx replacement policy. Like SetRetryPolicy, it only applies
+// to new sends.
+func (s *Sender) SetReplacementPolicy(p TxReplacementPolicy) {
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+	s.txReplacementPolicy = p
+}
+
+// policies returns the current retry and replacement policies.
+func (s *Sender) policies() (RetryPolicy, TxReplacementPolicy) {
+	s.policyMu.RLock()
+	defer s.policyMu.RUnlock()
+	return s.retryPolicy, s.txReplacementPolicy
+}
+
+// IsSending returns true if the tx containing the given message ID is currently sending.
+func (s *Sender) IsSending(msgID string) bool {
+	_, ok := s.sendingTxs.Load(msgID)
+	return ok
+}
+
+// SendingSnapshot returns the message IDs whose txs are currently sending, in no particular order.
+func (s *Sender) SendingSnapshot() []string {
+	var msgIDs []string
+	s.sendingTxs.Range(func(key, _ any) bool {
+		msgIDs = append(msgIDs, key.(string))
+		return true
+	})
+	return msgIDs
+}
+
+// SendTransaction is like Send, but returns the hash of the tx that was last successfully
+// broadcast.
+func (s *Sender) SendTransaction(
+	ctx context.Context, tx *coretypes.Transaction, msgIDs []string,
+) (common.Hash, error) {
+	sentTx, err := s.Send(ctx, tx, msgIDs)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return sentTx.Hash(), nil
+}
+
+// History returns the hashes that the tx with the given hash has been sent as, in order, ending
+// with the given hash, e.g. a tx and its replacements. Pass the hash returned by SendTransaction,
+// or of a *SendError. Returns nil if the tx was never retried or its history is no longer kept by
+// the retry policy (see RetryPolicy).
+func (s *Sender) History(hash common.Hash) []common.Hash {
+	retryPolicy, _ := s.policies()
+	return retryPolicy.History(hash)
+}
+
+// Send sends a transaction using the Ethereum client. If the transaction fails to send, it retries
+// based on the configured retry policy. Since the tx may be replaced while retrying (e.g. with
+// bumped gas or a new nonce), the tx that was last successfully broadcast is returned. The given
+// message IDs are marked as sending until this returns, and then as sent or failed (see State). If
+// the tx fails permanently (i.e. it can't be sent or replaced within the retry policy), the
+// permanent failure hook is called. If the number of concurrent sends is limited, this first
+// blocks until a send slot is free (or the context is done). If the circuit breaker is open, this
+// fails fast with ErrCircuitOpen. If any of the message IDs is already sending, this fails with
+// ErrAlreadySending (or, if configured, waits until the prior send finishes). Once the Sender is
+// draining, this fails with ErrDraining. Once the tx has been attempted, failures are returned as
+// a *SendError wrapping the final error, e.g. ErrTxCancelled if the tx is cancelled (see
+// CancelTransaction). The send is traced as a child span of the context's span (see WithTracer).
+func (s *Sender) Send(
+	ctx context.Context, tx *coretypes.Transaction, msgIDs []string,
+) (_ *coretypes.Transaction, err error) {
+	attrs := append(txAttributes(tx), attribute.StringSlice("msg_ids", msgIDs))
+	label := LabelFromContext(ctx)
+	if label != "" {
+		attrs = append(attrs, attribute.String("label", label))
+	}
+	ctx, span := s.tracer.Start(ctx, spanSendTransaction, trace.WithAttributes(attrs...))
+	defer func() { endSpan(span, err) }()
+
+	// Reject the send if draining.
+	s.mu.Lock()
+	if s.draining {
+		s.mu.Unlock()
+		return nil, ErrDraining
+	}
+	s.inFlight.Add(1)
+	s.counters.inFlight.Add(1)
+	s.mu.Unlock()
+	defer func() {
+		s.counters.inFlight.Add(-1)
+		s.inFlight.Done()
+	}()
+
+	metrics := s.metricsFor(ctx)
+	defer func(start time.Time) {
+		metrics.ObserveSendLatency(s.clock.Now().Sub(start))
+	}(s.clock.Now())
+
+	// Wait for a send slot, if the number of concurrent sends is limited.
+	if s.sendSlots != nil {
+		select {
+		case s.sendSlots <- struct{}{}:
+			defer func() { <-s.sendSlots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// Mark the message IDs as sending, unless any of them is already sending. Then track the tx
+	// by its nonce while sending, so it can be cancelled.
+	ctx, cancel := context.WithCancelCause(ctx)
+	st := &sendingTx{cancel: cancel, done: make(chan struct{})}
+	defer s.untrackTx(st)
+	release, err := s.claimMsgIDs(ctx, msgIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	s.trackTx(st, tx)
+
+	// Derive a logger with the fields common to all logs of the send, used while retrying.
+	fields := []any{"msgIDs", msgIDs, "original-hash", tx.Hash()}
+	if label != "" {
+		fields = append(fields, "label", label)
+	}
+	if cancelled := cancelledTx(ctx); cancelled != nil {
+		fields = append(fields, "cancelled-hash", cancelled.Hash())
+	}
+	ctx = log.NewContext(ctx, s.logger.With(fields...))
+	sentTx, err := s.retryTxWithPolicy(ctx, st, tx, msgIDs)
+	if err != nil {
+		s.terminalStates.set(StateFailed, msgIDs...)
+		s.counters.fail(err, s.clock.Now())
+		if cause := context.Cause(ctx); errors.Is(cause, ErrTxCancelled) {
+			var sendErr *SendError
+			if errors.As(err, &sendErr) {
+				sendErr.Err, sendErr.Class = cause, ErrorClassUnknown
+			} else {
+				err = cause // cancelled before the tx was attempted
+			}
+		}
+
+		// The tx failed permanently, unless the caller gave up on it first.
+		if s.onPermanentFailure != nil && ctx.Err() == nil {
+			s.onPermanentFailure(tx, msgIDs, err)
+		}
+		return nil, err
+	}
+	s.terminalStates.set(StateSent, msgIDs...)
+	s.counters.sent.Add(1)
+	span.SetAttributes(attribute.String("tx.sent_hash", sentTx.Hash().Hex()))
+	return sentTx, nil
+}
+
+// Drain stops the Sender from accepting new sends, which fail with ErrDraining, and blocks until
+// all in-flight sends (including their retries) are done. If the context is done first, its error
+// is returned; the in-flight sends are not cancelled.
+func (s *Sender) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// claimMsgIDs marks the given message IDs as sending, failing with ErrAlreadySending if any of
+// them is already sending (or, if configured, waiting until it isn't). The returned func must be
+// called once the send is done to release the message IDs.
+func (s *Sender) claimMsgIDs(ctx context.Context, msgIDs []string) (func(), error) {
+	done := make(chan struct{})
+	for {
+		prior := s.tryClaimMsgIDs(msgIDs, done)
+		if prior == nil {
+			s.persistMsgIDs(msgIDs, SendStateStore.Put)
+			return func() {
+				s.persistMsgIDs(msgIDs, SendStateStore.Delete)
+				for _, msgID := range msgIDs {
+					s.sendingTxs.Delete(msgID)
+				}
+				close(done)
+			}, nil
+		}
+
+		if !s.waitForDuplicates {
+			return nil, ErrAlreadySending
+		}
+		select {
+		case <-prior:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// persistMsgIDs applies the given store operation to each of the message IDs, if there is a
+// send state store.
+func (s *Sender) persistMsgIDs(msgIDs []string, op func(SendStateStore, string) error) {
+	if s.stateStore == nil {
+		return
+	}
+	for _, msgID := range msgIDs {
+		if err := op(s.stateStore, msgID); err != nil {
+			s.logger.Error("failed to persist send state", "msgID", msgID, "err", err)
+		}
+	}
+}
+
+// tryClaimMsgIDs atomically marks all of the given message IDs as sending until done is closed. If
+// any of them is already sending, none are marked and the done chan of the prior send is returned.
+func (s *Sender) tryClaimMsgIDs(msgIDs []string, done chan struct{}) chan struct{} {
+	for i, msgID := range msgIDs {
+		prior, loaded := s.sendingTxs.LoadOrStore(msgID, done)
+		if !loaded || prior == done {
+			continue
+		}
+		for _, claimed := range msgIDs[:i] {
+			s.sendingTxs.CompareAndDelete(claimed, done)
+		}
+		return prior.(chan struct{})
+	}
+	return nil
+}
+
+// SendTransactions concurrently sends the given transactions, each with the same retry semantics
+// as SendTransaction. At most the configured batch concurrency txs are sent at once. msgIDs[i]
+// are the message IDs of txs[i] (msgIDs may be nil). The returned errors are aligned by index
+// with txs.
+func (s *Sender) SendTransactions(
+	ctx context.Context, txs []*coretypes.Transaction, msgIDs [][]string,
+) []error {
+	var (
+		errs = make([]error, len(txs))
+		sem  = make(chan struct{}, s.batchConcurrency)
+		wg   sync.WaitGroup
+	)
+	for i, tx := range txs {
+		var txMsgIDs []string
+		if i < len(msgIDs) {
+			txMsgIDs = msgIDs[i]
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, tx *coretypes.Transaction, txMsgIDs []string) {
+			defer func() { <-sem; wg.Done() }()
+			_, errs[i] = s.SendTransaction(ctx, tx, txMsgIDs)
+		}(i, tx, txMsgIDs)
+	}
+	wg.Wait()
+
+	return errs
 }
 
 // retryTxWithPolicy (re)tries sending tx according to the retry policy. Specifically handles two
 // common errors on sending a transaction (NonceTooLow, ReplaceUnderpriced) by replacing the tx
-// appropriately.
-func (s *Sender) retryTxWithPolicy(ctx context.Context, tx *coretypes.Transaction) error {
-	for {
-		// (Re)try sending the transaction.
-		err := s.chain.SendTransaction(ctx, tx)
+// appropriately. Returns the tx that was successfully sent, or else a *SendError once the tx has
+// been attempted (and the bare error before that, e.g. if the context is done). The retries are
+// counted for the given send, even if the same tx is sent concurrently. Emits the lifecycle events
+// of the tx, with the given message IDs. Logs with the logger of the context, and applies the
+// retry overrides of the context (see WithRetryOverrides) to the policies.
+func (s *Sender) retryTxWithPolicy(
+	ctx context.Context, st *sendingTx, tx *coretypes.Transaction, msgIDs []string,
+) (_ *coretypes.Transaction, err error) {
+	logger, metrics := log.FromContext(ctx), s.metricsFor(ctx)
+	retryPolicy, replacementPolicy := s.policies()
+	retryPolicy = retryPolicyForSend(retryPolicy, st)
+	overrides := RetryOverridesFromContext(ctx)
+	if overrides != nil {
+		replacementPolicy = replacementPolicyFor(replacementPolicy, overrides.MaxGasPrice)
+	}
+
+	// Ensure the retry policy stops tracking the tx, however sending it ends.
+	var (
+		attempts    int           // number of attempts made to broadcast the tx
+		lastBackoff time.Duration // backoff before the last retry
+	)
+	defer func() {
+		retryDone(retryPolicy, tx.Hash())
+		if err == nil {
+			s.emit(msgIDs, tx.Hash(), TxEventSent, nil)
+			return
+		}
+		s.emit(msgIDs, tx.Hash(), TxEventFailed, err)
+		if attempts == 0 {
+			return // e.g. the context was done before the tx was first attempted
+		}
+		err = &SendError{
+			Err: err, Class: s.classifier.Classify(err), Attempts: attempts, Hash: tx.Hash(),
+			Nonce: tx.Nonce(),
+		}
+	}()
 
-		// Check the policy to see if we should retry this transaction.
-		retry, backoff := s.retryPolicy.Get(tx, err)
+	for attempt := 1; ; attempt++ {
+		// Stop before (re)trying if the caller gave up on the tx, e.g. while it was backing off.
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// (Re)try sending the transaction. If the tx is already known by the node, it's already
+		// in flight, so it was sent successfully.
+		s.emit(msgIDs, tx.Hash(), TxEventSending, nil)
+		if s.beforeSend != nil {
+			if err = s.beforeSend(ctx, tx); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrSendAborted, err)
+			}
+		}
+		attempts++
+		attemptCtx, attemptSpan := s.startSendAttempt(ctx, tx, attempt)
+		class, sendErr := s.sendOnce(attemptCtx, tx)
+		endSpan(attemptSpan, sendErr)
+		if class == ErrorClassAlreadyKnown || class == ErrorClassNonceTooLow {
+			// The tx may already be mined (e.g. by an attempt that timed out but went through),
+			// in which case it was sent successfully and must not be retried or replaced.
+			if receipt := s.minedReceipt(ctx, tx.Hash()); receipt != nil {
+				logger.Debug("tx already mined", "hash", tx.Hash(), "block", receipt.BlockNumber)
+				return tx, nil
+			}
+
+			// A cancellation never takes a fresh nonce (which would only send a pointless tx to
+			// self), so once the nonce was used, e.g. by the cancelled tx being mined, there's
+			// nothing left to cancel.
+			if cancelled := cancelledTx(ctx); cancelled != nil &&
+				(class == ErrorClassNonceTooLow || s.minedReceipt(ctx, cancelled.Hash()) != nil) {
+				return nil, ErrNothingToCancel
+			}
+		}
+		if class == ErrorClassAlreadyKnown {
+			sendErr = nil
+		} else if errors.Is(sendErr, ErrCircuitOpen) {
+			return nil, sendErr
+		}
+
+		// Fail without retrying if the error can't be resolved by retrying, e.g. insufficient
+		// funds. Otherwise check the policy to see if we should retry this transaction.
+		if sendErr != nil && retryTerminal(retryPolicy, class) {
+			return nil, sendErr
+		}
+		retry, backoff := retryPolicy.Get(tx, sendErr)
+		if sendErr != nil {
+			retry, backoff = s.retryDecision(overrides, attempts, retry, backoff, lastBackoff)
+		}
 		if !retry {
-			return err
+			if sendErr != nil {
+				return nil, sendErr
+			}
+			return tx, nil
+		}
+		metrics.IncRetry(retryReason(class))
+		trace.SpanFromContext(ctx).AddEvent(eventRetrying, trace.WithAttributes(
+			attribute.Int("attempt", attempt), attribute.String("reason", retryReason(class)),
+		))
+		s.emit(msgIDs, tx.Hash(), TxEventRetrying, sendErr)
+		if s.onRetry != nil {
+			s.onRetry(attempt, tx, sendErr)
+		}
+
+		// Retry after recommended backoff, unless the context is done first.
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.clock.After(backoff):
 		}
-		time.Sleep(backoff) // Retry after recommended backoff.
+		lastBackoff = backoff
 
-		// Log relevant details about retrying the transaction.
-		currTx, currGasPrice, currNonce := tx.Hash(), tx.GasPrice(), tx.Nonce()
-		s.logger.Error("failed to send tx, retrying...", "hash", currTx, "err", err)
+		// Log relevant details about retrying the transaction, at a lower level if the error is
+		// expected while sending txs.
+		currTx := tx.Hash()
+		level := s.unexpectedRetryLevel
+		if retryExpected(retryPolicy, class) {
+			level = s.expectedRetryLevel
+		}
+		logAt(logger, level, "failed to send tx, retrying...", "hash", currTx, "err", sendErr)
+
+		// Get the replacement tx if necessary, which is built as sent from the same account. If
+		// the account can't be recovered (i.e. the tx isn't signed), the factory's default is used.
+		from, _ := txFrom(tx)
+		var newTx *coretypes.Transaction
+		newTx, err = replacementPolicy.GetNew(tx, sendErr, class)
+		if err == nil && newTx == nil {
+			err = ErrNilTransaction
+		}
+		if err != nil {
+			logger.Error("failed to get replacement tx", "err", err)
+			return nil, err
+		}
 
-		// Get the replacement tx if necessary.
-		if tx, err = s.txReplacementPolicy.GetNew(tx, err); err != nil {
-			s.logger.Error("failed to get replacement tx", "err", err)
-			return err
+		// If the gas limit was too low, the gas estimate may be stale, so re-estimate it.
+		if class == ErrorClassIntrinsicGasTooLow {
+			if newTx, err = s.reestimateGas(ctx, from, newTx); err != nil {
+				logger.Error("failed to re-estimate gas", "err", err)
+				return nil, err
+			}
 		}
 
-		// Update the retry policy if the transaction has been changed and log.
-		if newTx := tx.Hash(); newTx != currTx {
-			s.logger.Debug(
+		// Log if the transaction has been changed.
+		if newTx.Hash() != currTx {
+			logger.Debug(
 				"retrying with diff gas and/or nonce",
-				"old-gas", currGasPrice, "new-gas", tx.GasPrice(),
-				"old-nonce", currNonce, "new-nonce", tx.Nonce(),
+				"old-gas", tx.GasPrice(), "new-gas", newTx.GasPrice(),
+				"old-nonce", tx.Nonce(), "new-nonce", newTx.Nonce(),
 			)
-			s.retryPolicy.UpdateTxModified(currTx, newTx)
+			metrics.IncReplacement()
 		}
 
 		// Use the factory to build and sign the new transaction.
-		if tx, err = s.factory.RebuildTransactionFromRequest(
-			ctx, types.CallMsgFromTx(tx), tx.Nonce(),
-		); err != nil {
-			s.logger.Error("failed to build replacement transaction", "err", err)
-			return err
+		s.emit(msgIDs, currTx, TxEventBuilding, nil)
+		if newTx, err = s.buildReplacement(ctx, from, newTx, backoff); err != nil {
+			return nil, err
 		}
+
+		// Update the retry policy with the hash of the (signed) tx that will be sent next.
+		if newTx.Hash() != currTx {
+			retryPolicy.UpdateTxModified(currTx, newTx.Hash())
+			s.emit(msgIDs, newTx.Hash(), TxEventReplaced, nil)
+			trace.SpanFromContext(ctx).AddEvent(eventReplaced, trace.WithAttributes(
+				attribute.String("old_hash", currTx.Hex()),
+				attribute.String("new_hash", newTx.Hash().Hex()),
+			))
+		}
+		s.updateTrackedTx(tx, newTx)
+		tx = newTx
+	}
+}
+
+// buildReplacement builds and signs the replacement tx (as sent from the given account) through
+// the factory. Blob txs can't be rebuilt from a call msg without losing the blobs, and
+// cancellations must keep their nonce, so they are signed as-is. A failed build (including a nil
+// tx) is retried after the given backoff, up to maxBuildAttempts times in total, after which
+// ErrBuildFailed is returned.
+func (s *Sender) buildReplacement(
+	ctx context.Context, from common.Address, tx *coretypes.Transaction, backoff time.Duration,
+) (*coretypes.Transaction, error) {
+	logger := log.FromContext(ctx)
+	for attempt := 1; ; attempt++ {
+		var (
+			built *coretypes.Transaction
+			err   error
+		)
+		if tx.Type() == coretypes.BlobTxType || cancelledTx(ctx) != nil {
+			built, err = s.factory.SignTransaction(ctx, from, tx)
+		} else {
+			msg := types.CallMsgFromTx(tx)
+			msg.From = from
+			built, err = s.factory.RebuildTransactionFromRequest(ctx, msg, tx.Nonce())
+		}
+		if err == nil && built == nil {
+			err = ErrNilTransaction
+		}
+		if err == nil {
+			return built, nil
+		}
+
+		logger.Error("failed to build replacement transaction", "attempt", attempt, "err", err)
+		if attempt == maxBuildAttempts {
+			return nil, fmt.Errorf("%w: %w", ErrBuildFailed, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.clock.After(backoff):
+		}
+	}
+}
+
+// reestimateGas estimates the gas limit of the tx (as sent from the given account) through the
+// factory, raising the tx's gas limit to the estimate if it is higher.
+func (s *Sender) reestimateGas(
+	ctx context.Context, from common.Address, tx *coretypes.Transaction,
+) (*coretypes.Transaction, error) {
+	msg := types.CallMsgFromTx(tx)
+	msg.From = from
+	gas, err := s.factory.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if gas <= tx.Gas() {
+		return tx, nil
+	}
+	return setGasLimit(tx, gas), nil
+}
+
+// sendOnce makes a single attempt to send the tx, bounded by the per-attempt timeout (if set),
+// returning the class of the send error and the error. A timed out attempt returns
+// context.DeadlineExceeded, which may be retried. If the circuit breaker is open, ErrCircuitOpen
+// is returned without attempting to send.
+func (s *Sender) sendOnce(ctx context.Context, tx *coretypes.Transaction) (ErrorClass, error) {
+	if s.breaker != nil {
+		if err := s.breaker.allow(); err != nil {
+			return ErrorClassUnknown, err
+		}
+	}
+
+	attemptCtx := ctx
+	if s.perAttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, s.perAttemptTimeout)
+		defer cancel()
+	}
+	err := s.broadcast(attemptCtx, tx)
+	class := ErrorClassUnknown
+	if err != nil {
+		class = s.classifier.Classify(err)
+	}
+
+	if s.breaker != nil {
+		switch {
+		case ctx.Err() != nil:
+			s.breaker.abort()
+		case err == nil || class != ErrorClassUnknown:
+			// The node responded to the tx, even if it rejected it.
+			s.breaker.success()
+		default:
+			s.breaker.failure()
+		}
+	}
+	return class, err
+}
+
+// broadcast sends the tx through the private sender if set, or else to the public mempool through
+// the chain. If configured, the tx is sent publicly if the private sender can't be reached. In
+// dry-run mode, the tx is passed to the dry-run sink instead.
+func (s *Sender) broadcast(ctx context.Context, tx *coretypes.Transaction) error {
+	if s.dryRun {
+		log.FromContext(ctx).Info("dry run, not broadcasting tx", "hash", tx.Hash())
+		if s.dryRunSink != nil {
+			s.dryRunSink(tx)
+		}
+		return nil
+	}
+	if s.privateSender == nil {
+		return s.chain.SendTransaction(ctx, tx)
+	}
+
+	err := s.privateSender.SendPrivateTransaction(ctx, tx)
+	if !s.privateFallback || ctx.Err() != nil || !eth.IsConnectivityError(err) {
+		return err
 	}
+	log.FromContext(ctx).Warn("failed to send tx privately, sending publicly", "err", err)
+	return s.chain.SendTransaction(ctx, tx)
 }
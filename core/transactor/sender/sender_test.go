@@ -0,0 +1,1050 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/client/eth"
+	"github.com/berachain/offchain-sdk/core/transactor/types"
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+var errRPCUnavailable = errors.New("rpc unavailable")
+
+// mockClient is an eth.Client that only implements sending txs and fetching receipts.
+type mockClient struct {
+	eth.Client
+	sendFn func(context.Context, *coretypes.Transaction) error
+
+	mu           sync.Mutex
+	receipts     map[common.Hash]*coretypes.Receipt
+	receiptCalls int
+	blockNumber  uint64
+}
+
+func (m *mockClient) SendTransaction(ctx context.Context, tx *coretypes.Transaction) error {
+	return m.sendFn(ctx, tx)
+}
+
+func (m *mockClient) TransactionReceipt(
+	_ context.Context, hash common.Hash,
+) (*coretypes.Receipt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.receiptCalls++
+	if receipt, ok := m.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (m *mockClient) BlockNumber(context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.blockNumber, nil
+}
+
+// mine includes the tx with the given hash in the given block.
+func (m *mockClient) mine(hash common.Hash, block uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.receipts == nil {
+		m.receipts = make(map[common.Hash]*coretypes.Receipt)
+	}
+	number := new(big.Int).SetUint64(block)
+	m.receipts[hash] = &coretypes.Receipt{
+		TxHash: hash, BlockNumber: number, BlockHash: common.BigToHash(number),
+	}
+	m.blockNumber = block
+}
+
+// mockFactory rebuilds txs directly from the call msg, without signing.
+type mockFactory struct {
+	gasEstimate uint64
+}
+
+func (*mockFactory) RebuildTransactionFromRequest(
+	_ context.Context, msg *ethereum.CallMsg, nonce uint64,
+) (*coretypes.Transaction, error) {
+	return coretypes.NewTx(&coretypes.DynamicFeeTx{
+		ChainID:    big.NewInt(1),
+		Nonce:      nonce,
+		GasTipCap:  msg.GasTipCap,
+		GasFeeCap:  msg.GasFeeCap,
+		Gas:        msg.Gas,
+		To:         msg.To,
+		Value:      msg.Value,
+		Data:       msg.Data,
+		AccessList: msg.AccessList,
+	}), nil
+}
+
+func (*mockFactory) SignTransaction(
+	_ context.Context, _ common.Address, tx *coretypes.Transaction,
+) (*coretypes.Transaction, error) {
+	return tx, nil
+}
+
+func (m *mockFactory) EstimateGas(context.Context, *ethereum.CallMsg) (uint64, error) {
+	return m.gasEstimate, nil
+}
+
+// mockNoncer hands out increasing nonces (to any account), or fails with err if set.
+type mockNoncer struct {
+	nonce uint64
+	err   error
+}
+
+func (m *mockNoncer) Acquire(common.Address) (uint64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.nonce++
+	return m.nonce, nil
+}
+
+// fixedRetryPolicy always retries errored txs after the same backoff. Like a policy implemented
+// outside of this package, it doesn't implement retryHooks.
+type fixedRetryPolicy struct {
+	backoff time.Duration
+}
+
+func (f *fixedRetryPolicy) Get(_ *coretypes.Transaction, err error) (bool, time.Duration) {
+	return err != nil, f.backoff
+}
+
+func (*fixedRetryPolicy) UpdateTxModified(common.Hash, common.Hash) {}
+
+func (*fixedRetryPolicy) History(common.Hash) []common.Hash { return nil }
+
+// newTestSender returns a Sender that sends txs through sendFn.
+func newTestSender(
+	retry RetryPolicy, sendFn func(context.Context, *coretypes.Transaction) error,
+	opts ...Option,
+) *Sender {
+	s := New(&mockFactory{}, &mockNoncer{}, opts...)
+	s.SetRetryPolicy(retry)
+	s.Setup(&mockClient{sendFn: sendFn}, log.NewBlankLogger(io.Discard))
+	return s
+}
+
+func newTestTx(nonce uint64) *coretypes.Transaction {
+	to := common.HexToAddress("0x1")
+	return coretypes.NewTx(&coretypes.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(2e9),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(0),
+	})
+}
+
+func TestSendTransactionCancelDuringBackoff(t *testing.T) {
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Minute},
+		func(context.Context, *coretypes.Transaction) error { return errRPCUnavailable },
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := s.SendTransaction(ctx, newTestTx(0), nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestSendTransactionCancelledBeforeAttempt(t *testing.T) {
+	var (
+		sends  int
+		failed bool
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{},
+		func(context.Context, *coretypes.Transaction) error {
+			sends++
+			return nil
+		},
+		WithOnPermanentFailure(func(*coretypes.Transaction, []string, error) { failed = true }),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.SendTransaction(ctx, newTestTx(0), []string{"msg"})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The tx was never broadcast (so the error isn't a SendError), and its msg was released
+	// without being a permanent failure.
+	var sendErr *SendError
+	require.False(t, errors.As(err, &sendErr))
+	require.Zero(t, sends)
+	require.False(t, failed)
+	require.False(t, s.IsSending("msg"))
+	require.Equal(t, StateFailed, s.State("msg"))
+}
+
+// recordingMetrics records the calls made to the Metrics hooks.
+type recordingMetrics struct {
+	retries      []string
+	replacements int
+	latencies    []time.Duration
+}
+
+func (r *recordingMetrics) IncRetry(reason string) { r.retries = append(r.retries, reason) }
+
+func (r *recordingMetrics) IncReplacement() { r.replacements++ }
+
+func (r *recordingMetrics) ObserveSendLatency(d time.Duration) {
+	r.latencies = append(r.latencies, d)
+}
+
+func TestSendTransactionMetrics(t *testing.T) {
+	var (
+		metrics = &recordingMetrics{}
+		errs    = []error{errRPCUnavailable, txpool.ErrReplaceUnderpriced, nil}
+		sends   int
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(context.Context, *coretypes.Transaction) error {
+			sends++
+			return errs[sends-1]
+		},
+		WithMetrics(metrics),
+	)
+
+	_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{RetryReasonOther, RetryReasonReplaceUnderpriced}, metrics.retries)
+	require.Equal(t, 1, metrics.replacements)
+	require.Len(t, metrics.latencies, 1)
+}
+
+func TestSendTransactionReturnsFinalHash(t *testing.T) {
+	var lastSent *coretypes.Transaction
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(_ context.Context, tx *coretypes.Transaction) error {
+			if lastSent = tx; tx.GasTipCap().Cmp(big.NewInt(1e9)) == 0 {
+				return txpool.ErrReplaceUnderpriced
+			}
+			return nil
+		},
+	)
+
+	tx := newTestTx(0)
+	hash, err := s.SendTransaction(context.Background(), tx, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, tx.Hash(), hash)
+	require.Equal(t, lastSent.Hash(), hash)
+}
+
+func TestSendAndWait(t *testing.T) {
+	var (
+		tx     = newTestTx(0)
+		client = &mockClient{}
+	)
+	client.sendFn = func(_ context.Context, sent *coretypes.Transaction) error {
+		client.mine(sent.Hash(), 10)
+		return nil
+	}
+	s := newTestSender(&fixedRetryPolicy{}, nil)
+	s.Setup(client, s.logger)
+
+	// With 1 confirmation, the receipt is returned as soon as the tx is mined.
+	receipt, err := s.SendAndWait(context.Background(), tx, nil, 1)
+	require.NoError(t, err)
+	require.Equal(t, tx.Hash(), receipt.TxHash)
+
+	// With 3 confirmations, waiting is bounded by the context.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.SendAndWait(ctx, tx, nil, 3)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSendTransactions(t *testing.T) {
+	var (
+		mu                sync.Mutex
+		active, maxActive int
+		release           = make(chan struct{})
+		txs               = []*coretypes.Transaction{newTestTx(0), newTestTx(1), newTestTx(2)}
+		msgIDs            = [][]string{{"a", "b"}, {"c"}, {"d"}}
+	)
+	s := newTestSender(&noRetryPolicy{}, func(_ context.Context, tx *coretypes.Transaction) error {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		defer func() { mu.Lock(); active--; mu.Unlock() }()
+
+		<-release
+		if tx.Nonce() == 1 {
+			return errRPCUnavailable
+		}
+		return nil
+	})
+	s.batchConcurrency = 2
+
+	done := make(chan []error)
+	go func() { done <- s.SendTransactions(context.Background(), txs, msgIDs) }()
+
+	// All msgs of the admitted txs are tracked as sending.
+	require.Eventually(t, func() bool {
+		return s.IsSending("a") && s.IsSending("b") && s.IsSending("c")
+	}, time.Second, time.Millisecond)
+	require.False(t, s.IsSending("d"))
+	close(release)
+
+	errs := <-done
+	require.Len(t, errs, 3)
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], errRPCUnavailable)
+	require.NoError(t, errs[2])
+	require.Equal(t, 2, maxActive)
+	for _, msgID := range []string{"a", "b", "c", "d"} {
+		require.False(t, s.IsSending(msgID))
+	}
+}
+
+func TestSendTransactionReplacesBlobTx(t *testing.T) {
+	var lastSent *coretypes.Transaction
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(_ context.Context, tx *coretypes.Transaction) error {
+			if lastSent = tx; tx.GasTipCap().Cmp(big.NewInt(1e9)) == 0 {
+				return txpool.ErrReplaceUnderpriced
+			}
+			return nil
+		},
+	)
+
+	tx := newTestBlobTx()
+	hash, err := s.SendTransaction(context.Background(), tx, nil)
+	require.NoError(t, err)
+	require.Equal(t, lastSent.Hash(), hash)
+	require.Equal(t, coretypes.BlobTxType, int(lastSent.Type()))
+	require.Equal(t, tx.BlobTxSidecar(), lastSent.BlobTxSidecar())
+}
+
+func TestSendTransactionAlreadyKnown(t *testing.T) {
+	for _, sendErr := range []error{txpool.ErrAlreadyKnown, errors.New("already known")} {
+		var sends int
+		s := newTestSender(
+			NewExpoRetryPolicy(maxRetriesPerTx, time.Millisecond),
+			func(context.Context, *coretypes.Transaction) error {
+				sends++
+				return sendErr
+			},
+		)
+
+		tx := newTestTx(0)
+		hash, err := s.SendTransaction(context.Background(), tx, nil)
+		require.NoError(t, err)
+		require.Equal(t, tx.Hash(), hash)
+		require.Equal(t, 1, sends)
+	}
+}
+
+func TestSendTransactionPerAttemptTimeout(t *testing.T) {
+	var attempts int
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(ctx context.Context, _ *coretypes.Transaction) error {
+			// The first attempt hangs until it times out.
+			if attempts++; attempts == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		},
+	)
+	s.perAttemptTimeout = 10 * time.Millisecond
+
+	_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+}
+
+func TestSendTransactionMaxConcurrentSends(t *testing.T) {
+	var (
+		mu                sync.Mutex
+		active, maxActive int
+		wg                sync.WaitGroup
+	)
+	s := newTestSender(
+		&noRetryPolicy{},
+		func(context.Context, *coretypes.Transaction) error {
+			mu.Lock()
+			active++
+			maxActive = max(maxActive, active)
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return nil
+		},
+		WithMaxConcurrentSends(3),
+	)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.SendTransaction(context.Background(), newTestTx(uint64(i)), nil)
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, 3, maxActive)
+
+	// A caller waiting for a slot gives up when its context is done.
+	s.sendSlots <- struct{}{}
+	s.sendSlots <- struct{}{}
+	s.sendSlots <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.SendTransaction(ctx, newTestTx(0), []string{"a"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, s.IsSending("a"))
+}
+
+func TestSendingSnapshot(t *testing.T) {
+	var (
+		release = make(chan struct{})
+		started sync.WaitGroup
+		done    sync.WaitGroup
+	)
+	s := newTestSender(&noRetryPolicy{}, func(context.Context, *coretypes.Transaction) error {
+		started.Done()
+		<-release
+		return nil
+	})
+	require.Empty(t, s.SendingSnapshot())
+
+	msgIDs := [][]string{{"a", "b"}, {"c"}, {"d"}}
+	for i, ids := range msgIDs {
+		started.Add(1)
+		done.Add(1)
+		go func(i int, ids []string) {
+			defer done.Done()
+			_, err := s.SendTransaction(context.Background(), newTestTx(uint64(i)), ids)
+			require.NoError(t, err)
+		}(i, ids)
+	}
+	started.Wait()
+	require.ElementsMatch(t, []string{"a", "b", "c", "d"}, s.SendingSnapshot())
+
+	close(release)
+	done.Wait()
+	require.Empty(t, s.SendingSnapshot())
+}
+
+func TestSendTransactionOnPermanentFailure(t *testing.T) {
+	var (
+		failedTx     *coretypes.Transaction
+		failedMsgIDs []string
+		failedErr    error
+		calls        int
+	)
+	s := newTestSender(
+		NewLinearRetryPolicy(2, time.Millisecond, time.Millisecond),
+		func(context.Context, *coretypes.Transaction) error { return errRPCUnavailable },
+		WithOnPermanentFailure(func(tx *coretypes.Transaction, msgIDs []string, err error) {
+			failedTx, failedMsgIDs, failedErr = tx, msgIDs, err
+			calls++
+		}),
+	)
+
+	tx := newTestTx(0)
+	_, err := s.SendTransaction(context.Background(), tx, []string{"a", "b"})
+	require.ErrorIs(t, err, errRPCUnavailable)
+	require.Equal(t, 1, calls)
+	require.Equal(t, tx.Hash(), failedTx.Hash())
+	require.Equal(t, []string{"a", "b"}, failedMsgIDs)
+	require.ErrorIs(t, failedErr, errRPCUnavailable)
+
+	// Cancelled sends aren't permanent failures.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.retryPolicy = &fixedRetryPolicy{backoff: time.Minute}
+	_, err = s.SendTransaction(ctx, newTestTx(1), []string{"c"})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, calls)
+}
+
+func TestSendTransactionInsufficientFunds(t *testing.T) {
+	var (
+		metrics   = &recordingMetrics{}
+		sends     int
+		failedErr error
+	)
+	s := newTestSender(
+		NewLinearRetryPolicy(0, time.Minute, time.Minute),
+		func(context.Context, *coretypes.Transaction) error {
+			sends++
+			return errors.New("insufficient funds for gas * price + value: balance 0, tx cost 1")
+		},
+		WithMetrics(metrics),
+		WithOnPermanentFailure(func(_ *coretypes.Transaction, _ []string, err error) {
+			failedErr = err
+		}),
+	)
+
+	// The tx fails on the first attempt, without retrying (which would wait a minute).
+	_, err := s.SendTransaction(context.Background(), newTestTx(0), []string{"a"})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Equal(t, 1, sends)
+	require.Empty(t, metrics.retries)
+	require.Zero(t, metrics.replacements)
+	require.ErrorIs(t, failedErr, ErrInsufficientFunds)
+
+	var sendErr *SendError
+	require.ErrorAs(t, err, &sendErr)
+	require.Equal(t, ErrorClassInsufficientFunds, sendErr.Class)
+	require.Equal(t, 1, sendErr.Attempts)
+
+	// Other failures don't match ErrInsufficientFunds.
+	require.NotErrorIs(t, &SendError{Err: errRPCUnavailable}, ErrInsufficientFunds)
+}
+
+func TestSendTransactionBeforeSend(t *testing.T) {
+	var (
+		errs      = []error{txpool.ErrReplaceUnderpriced, nil}
+		sent      []common.Hash
+		observed  []common.Hash
+		errReject = errors.New("rejected by relay")
+		reject    bool
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(_ context.Context, tx *coretypes.Transaction) error {
+			sent = append(sent, tx.Hash())
+			return errs[len(sent)-1]
+		},
+		WithBeforeSend(func(_ context.Context, tx *coretypes.Transaction) error {
+			observed = append(observed, tx.Hash())
+			if reject {
+				return errReject
+			}
+			return nil
+		}),
+	)
+
+	// The hook observes the original tx and its replacement, each before it's broadcast.
+	tx := newTestTx(0)
+	sentHash, err := s.SendTransaction(context.Background(), tx, nil)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{tx.Hash(), sentHash}, observed)
+	require.Equal(t, observed, sent)
+
+	// If the hook fails, the tx is not broadcast.
+	reject, observed, sent = true, nil, nil
+	_, err = s.SendTransaction(context.Background(), newTestTx(1), nil)
+	require.ErrorIs(t, err, ErrSendAborted)
+	require.ErrorIs(t, err, errReject)
+	require.Len(t, observed, 1)
+	require.Empty(t, sent)
+}
+
+// mockRelay is a PrivateSender that records the txs sent privately.
+type mockRelay struct {
+	errs []error
+	sent []*coretypes.Transaction
+}
+
+func (m *mockRelay) SendPrivateTransaction(_ context.Context, tx *coretypes.Transaction) error {
+	m.sent = append(m.sent, tx)
+	return m.errs[len(m.sent)-1]
+}
+
+func TestSendTransactionPrivateSender(t *testing.T) {
+	errRelayDown := fmt.Errorf("relay unreachable: %w", syscall.ECONNREFUSED)
+	for _, tc := range []struct {
+		name          string
+		relayErrs     []error
+		fallback      bool
+		relaySends    int
+		publicSends   int
+		expectedError error
+	}{
+		{
+			name: "private", relayErrs: []error{txpool.ErrReplaceUnderpriced, nil},
+			relaySends: 2,
+		},
+		{
+			name: "fallback", relayErrs: []error{errRelayDown}, fallback: true,
+			relaySends: 1, publicSends: 1,
+		},
+		{
+			name: "no fallback", relayErrs: []error{errRelayDown, errRelayDown}, relaySends: 2,
+			expectedError: errRelayDown,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				relay       = &mockRelay{errs: tc.relayErrs}
+				publicSends int
+			)
+			s := newTestSender(
+				NewLinearRetryPolicy(1, time.Millisecond, time.Millisecond),
+				func(context.Context, *coretypes.Transaction) error {
+					publicSends++
+					return nil
+				},
+				WithPrivateSender(relay, tc.fallback),
+			)
+
+			_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, relay.sent, tc.relaySends)
+			require.Equal(t, tc.publicSends, publicSends)
+		})
+	}
+}
+
+func TestSendTransactionDryRun(t *testing.T) {
+	var sunk []*coretypes.Transaction
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(context.Context, *coretypes.Transaction) error {
+			t.Fatal("tx broadcast in dry run")
+			return nil
+		},
+		WithDryRun(func(tx *coretypes.Transaction) { sunk = append(sunk, tx) }),
+	)
+
+	// The tx is passed to the sink instead of being broadcast, and is sent successfully.
+	tx := newTestTx(0)
+	hash, err := s.SendTransaction(context.Background(), tx, []string{"msg"})
+	require.NoError(t, err)
+	require.Equal(t, tx.Hash(), hash)
+	require.Len(t, sunk, 1)
+	require.Equal(t, tx.Hash(), sunk[0].Hash())
+	require.Equal(t, StateSent, s.State("msg"))
+}
+
+func TestSendTransactionDuplicateMsgID(t *testing.T) {
+	for _, wait := range []bool{false, true} {
+		var opts []Option
+		if wait {
+			opts = append(opts, WithWaitForDuplicateSends())
+		}
+
+		var (
+			mu             sync.Mutex
+			active, sends  int
+			concurrentSend bool
+			release        = make(chan struct{})
+			errs           = make(chan error, 2)
+		)
+		s := newTestSender(&noRetryPolicy{}, func(context.Context, *coretypes.Transaction) error {
+			mu.Lock()
+			active++
+			sends++
+			concurrentSend = concurrentSend || active > 1
+			mu.Unlock()
+
+			<-release
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return nil
+		}, opts...)
+
+		for i := 0; i < 2; i++ {
+			go func(i int) {
+				_, err := s.SendTransaction(
+					context.Background(), newTestTx(uint64(i)), []string{"a", "b"},
+				)
+				errs <- err
+			}(i)
+		}
+
+		if wait {
+			close(release)
+			require.NoError(t, <-errs)
+			require.NoError(t, <-errs)
+			require.Equal(t, 2, sends)
+		} else {
+			// The losing send fails without sending; the winning one is still sending.
+			require.ErrorIs(t, <-errs, ErrAlreadySending)
+			require.True(t, s.IsSending("a"))
+			require.True(t, s.IsSending("b"))
+			close(release)
+			require.NoError(t, <-errs)
+			require.Equal(t, 1, sends)
+		}
+		require.False(t, concurrentSend)
+		require.Empty(t, s.SendingSnapshot())
+	}
+}
+
+func TestSendTransactionOnRetry(t *testing.T) {
+	var (
+		attempts []int
+		hashes   []common.Hash
+		sends    int
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{},
+		func(context.Context, *coretypes.Transaction) error {
+			if sends++; sends <= 2 {
+				return txpool.ErrReplaceUnderpriced
+			}
+			return nil
+		},
+		WithOnRetry(func(attempt int, tx *coretypes.Transaction, err error) {
+			require.ErrorIs(t, err, txpool.ErrReplaceUnderpriced)
+			attempts = append(attempts, attempt)
+			hashes = append(hashes, tx.Hash())
+		}),
+	)
+
+	_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, attempts)
+	require.NotEqual(t, hashes[0], hashes[1])
+}
+
+func TestDrain(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		sent    = make(chan error, 1)
+		drained = make(chan error, 1)
+	)
+	s := newTestSender(&noRetryPolicy{}, func(context.Context, *coretypes.Transaction) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	go func() {
+		_, err := s.SendTransaction(context.Background(), newTestTx(0), []string{"a"})
+		sent <- err
+	}()
+	<-started
+	go func() { drained <- s.Drain(context.Background()) }()
+
+	// Drain waits for the in-flight send, while new sends are rejected.
+	require.Eventually(t, func() bool {
+		_, err := s.SendTransaction(context.Background(), newTestTx(1), nil)
+		return errors.Is(err, ErrDraining)
+	}, time.Second, time.Millisecond)
+	select {
+	case <-drained:
+		t.Fatal("drain returned while a send is in flight")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// A drain that times out returns the context's error.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, s.Drain(ctx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, <-sent)
+	require.NoError(t, <-drained)
+	require.Empty(t, s.SendingSnapshot())
+}
+
+func TestSendTransactionIntrinsicGasTooLow(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		gasEstimate uint64
+		expectedGas uint64
+	}{
+		{name: "stale estimate", gasEstimate: 21000, expectedGas: 25200},
+		{name: "higher estimate", gasEstimate: 30000, expectedGas: 30000},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var sent []*coretypes.Transaction
+			s := newTestSender(
+				&fixedRetryPolicy{},
+				func(_ context.Context, tx *coretypes.Transaction) error {
+					if sent = append(sent, tx); len(sent) == 1 {
+						return core.ErrIntrinsicGas
+					}
+					return nil
+				},
+			)
+			s.factory = &mockFactory{gasEstimate: tc.gasEstimate}
+
+			hash, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+			require.NoError(t, err)
+			require.Len(t, sent, 2)
+			require.Equal(t, sent[1].Hash(), hash)
+			require.Equal(t, tc.expectedGas, sent[1].Gas())
+			require.Equal(t, sent[0].GasFeeCap(), sent[1].GasFeeCap())
+		})
+	}
+}
+
+func TestSendTransactionEvents(t *testing.T) {
+	var (
+		errs  = []error{txpool.ErrReplaceUnderpriced, nil}
+		sends int
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(context.Context, *coretypes.Transaction) error {
+			sends++
+			return errs[sends-1]
+		},
+	)
+
+	tx, msgIDs := newTestTx(0), []string{"msg"}
+	sentHash, err := s.SendTransaction(context.Background(), tx, msgIDs)
+	require.NoError(t, err)
+	require.NotEqual(t, tx.Hash(), sentHash)
+
+	// The retried send is replaced with bumped gas, then sent.
+	expected := []TxEvent{
+		{MsgIDs: msgIDs, Hash: tx.Hash(), State: TxEventSending},
+		{MsgIDs: msgIDs, Hash: tx.Hash(), State: TxEventRetrying, Err: txpool.ErrReplaceUnderpriced},
+		{MsgIDs: msgIDs, Hash: tx.Hash(), State: TxEventBuilding},
+		{MsgIDs: msgIDs, Hash: sentHash, State: TxEventReplaced},
+		{MsgIDs: msgIDs, Hash: sentHash, State: TxEventSending},
+		{MsgIDs: msgIDs, Hash: sentHash, State: TxEventSent},
+	}
+	for _, event := range expected {
+		require.Equal(t, event, <-s.Events())
+	}
+	require.Empty(t, s.Events())
+}
+
+func TestSendTransactionEventsDropped(t *testing.T) {
+	s := newTestSender(
+		&fixedRetryPolicy{},
+		func(context.Context, *coretypes.Transaction) error { return nil },
+		WithEventBufferSize(1),
+	)
+
+	// Sends don't block on an absent consumer; events beyond the buffer are dropped.
+	for i := 0; i < 3; i++ {
+		_, err := s.SendTransaction(context.Background(), newTestTx(uint64(i)), nil)
+		require.NoError(t, err)
+	}
+	require.Equal(t, TxEventSending, (<-s.Events()).State)
+	require.Empty(t, s.Events())
+}
+
+func TestSendTransactionSendError(t *testing.T) {
+	var lastSent *coretypes.Transaction
+	s := newTestSender(
+		NewLinearRetryPolicy(2, time.Millisecond, time.Millisecond),
+		func(_ context.Context, tx *coretypes.Transaction) error {
+			lastSent = tx
+			return txpool.ErrReplaceUnderpriced
+		},
+	)
+
+	// The tx is replaced on each of its 2 retries, after which it fails.
+	tx := newTestTx(7)
+	_, err := s.SendTransaction(context.Background(), tx, nil)
+	require.ErrorIs(t, err, txpool.ErrReplaceUnderpriced)
+
+	var sendErr *SendError
+	require.ErrorAs(t, err, &sendErr)
+	require.Equal(t, txpool.ErrReplaceUnderpriced, sendErr.Err)
+	require.Equal(t, ErrorClassReplaceUnderpriced, sendErr.Class)
+	require.Equal(t, 3, sendErr.Attempts)
+	require.NotEqual(t, tx.Hash(), sendErr.Hash)
+	require.Equal(t, lastSent.Hash(), sendErr.Hash)
+	require.Equal(t, uint64(7), sendErr.Nonce)
+}
+
+// countingRetryPolicy is a fixedRetryPolicy that counts the retries it was asked about.
+type countingRetryPolicy struct {
+	fixedRetryPolicy
+	gets atomic.Int32
+}
+
+func (c *countingRetryPolicy) Get(tx *coretypes.Transaction, err error) (bool, time.Duration) {
+	c.gets.Add(1)
+	return c.fixedRetryPolicy.Get(tx, err)
+}
+
+// countingReplacementPolicy returns the same tx, counting the replacements it was asked for.
+type countingReplacementPolicy struct {
+	replacements atomic.Int32
+}
+
+func (c *countingReplacementPolicy) GetNew(
+	tx *coretypes.Transaction, _ error, _ ErrorClass,
+) (*coretypes.Transaction, error) {
+	c.replacements.Add(1)
+	return tx, nil
+}
+
+func TestSetPolicies(t *testing.T) {
+	var (
+		retry          = fixedRetryPolicy{backoff: time.Millisecond}
+		oldRetry       = &countingRetryPolicy{fixedRetryPolicy: retry}
+		oldReplacement = &countingReplacementPolicy{}
+		newRetry       = &countingRetryPolicy{fixedRetryPolicy: retry}
+		newReplacement = &countingReplacementPolicy{}
+		started        = make(chan struct{})
+		release        = make(chan struct{})
+		attempts       sync.Map // nonce -> number of attempts
+	)
+	s := newTestSender(oldRetry, func(_ context.Context, tx *coretypes.Transaction) error {
+		n, _ := attempts.LoadOrStore(tx.Nonce(), new(atomic.Int32))
+		switch n.(*atomic.Int32).Add(1) {
+		case 1:
+			if tx.Nonce() == 0 {
+				close(started)
+				<-release
+			}
+			return errRPCUnavailable
+		default:
+			return nil
+		}
+	})
+	s.SetReplacementPolicy(oldReplacement)
+
+	// Swap the policies while a send is retrying with the old ones.
+	sent := make(chan error, 1)
+	go func() {
+		_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+		sent <- err
+	}()
+	<-started
+	s.SetRetryPolicy(newRetry)
+	s.SetReplacementPolicy(newReplacement)
+	close(release)
+	require.NoError(t, <-sent)
+
+	// The in-flight send finished with the old policies; a new send uses the new ones.
+	require.EqualValues(t, 2, oldRetry.gets.Load())
+	require.EqualValues(t, 1, oldReplacement.replacements.Load())
+	require.Zero(t, newRetry.gets.Load())
+
+	_, err := s.SendTransaction(context.Background(), newTestTx(1), nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, oldRetry.gets.Load())
+	require.EqualValues(t, 2, newRetry.gets.Load())
+	require.EqualValues(t, 1, newReplacement.replacements.Load())
+}
+
+// nilReplacementPolicy returns a nil replacement tx without error.
+type nilReplacementPolicy struct{}
+
+func (nilReplacementPolicy) GetNew(
+	*coretypes.Transaction, error, ErrorClass,
+) (*coretypes.Transaction, error) {
+	return nil, nil
+}
+
+func TestSendTransactionNilReplacement(t *testing.T) {
+	var sends int
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(context.Context, *coretypes.Transaction) error {
+			sends++
+			return txpool.ErrReplaceUnderpriced
+		},
+	)
+	s.SetReplacementPolicy(nilReplacementPolicy{})
+
+	// The send fails instead of sending (or building) a nil replacement.
+	_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+	require.ErrorIs(t, err, ErrNilTransaction)
+	require.Equal(t, 1, sends)
+
+	// Likewise when the replacements are capped to a max gas price.
+	ctx := WithRetryOverrides(
+		context.Background(), &types.RetryOverrides{MaxGasPrice: big.NewInt(1e9)},
+	)
+	_, err = s.SendTransaction(ctx, newTestTx(1), nil)
+	require.ErrorIs(t, err, ErrNilTransaction)
+	require.Equal(t, 2, sends)
+}
+
+// flakyFactory fails to rebuild txs the given number of times (or always if negative), alternating
+// between failing with an error and building a nil tx.
+type flakyFactory struct {
+	mockFactory
+	failures int
+	builds   atomic.Int32
+}
+
+func (f *flakyFactory) RebuildTransactionFromRequest(
+	ctx context.Context, msg *ethereum.CallMsg, nonce uint64,
+) (*coretypes.Transaction, error) {
+	build := int(f.builds.Add(1))
+	if f.failures < 0 || build <= f.failures {
+		if build%2 == 0 {
+			return nil, nil
+		}
+		return nil, errRPCUnavailable
+	}
+	return f.mockFactory.RebuildTransactionFromRequest(ctx, msg, nonce)
+}
+
+func TestSendTransactionRebuildFailure(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		failures int
+		builds   int
+		err      error
+	}{
+		{name: "fails once", failures: 1, builds: 2},
+		{name: "nil tx once", failures: 2, builds: 3},
+		{name: "always fails", failures: -1, builds: maxBuildAttempts, err: ErrBuildFailed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var sent []*coretypes.Transaction
+			s := newTestSender(
+				&fixedRetryPolicy{backoff: time.Millisecond},
+				func(_ context.Context, tx *coretypes.Transaction) error {
+					sent = append(sent, tx)
+					if len(sent) == 1 {
+						return txpool.ErrReplaceUnderpriced
+					}
+					return nil
+				},
+			)
+			factory := &flakyFactory{failures: tc.failures}
+			s.factory = factory
+
+			// The replacement is built again until it succeeds, or the send fails without
+			// resending the replaced tx.
+			hash, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+			require.Equal(t, tc.builds, int(factory.builds.Load()))
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.Len(t, sent, 1)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, sent, 2)
+			require.Equal(t, sent[1].Hash(), hash)
+			require.Equal(t, 1, sent[1].GasFeeCap().Cmp(sent[0].GasFeeCap()))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultStateTTL is the default time the terminal state of a message is retained.
+const defaultStateTTL = 5 * time.Minute
+
+// TxState is the state of sending the tx containing a message, as tracked by the Sender.
+type TxState uint8
+
+const (
+	// StateUnknown means the message is not sending and has no retained terminal state.
+	StateUnknown TxState = iota
+	// StateSending means the tx containing the message is sending (or retrying).
+	StateSending
+	// StateSent means the tx containing the message was sent successfully.
+	StateSent
+	// StateFailed means sending the tx containing the message failed.
+	StateFailed
+)
+
+// String returns the name of the state.
+func (s TxState) String() string {
+	switch s {
+	case StateSending:
+		return "sending"
+	case StateSent:
+		return "sent"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// State returns the state of sending the tx containing the given message ID. Terminal states (sent
+// or failed) are retained for the configured TTL (see WithStateTTL), after which the message ID is
+// in StateUnknown.
+func (s *Sender) State(msgID string) TxState {
+	if s.IsSending(msgID) {
+		return StateSending
+	}
+	return s.terminalStates.get(msgID)
+}
+
+// terminalStates retains the terminal states of message IDs until they expire.
+type terminalStates struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	states   map[string]terminalState
+	expiries []expiry // in order of expiry, since the TTL is constant
+}
+
+type terminalState struct {
+	state     TxState
+	expiresAt time.Time
+}
+
+type expiry struct {
+	msgID string
+	at    time.Time
+}
+
+func newTerminalStates(ttl time.Duration) *terminalStates {
+	return &terminalStates{ttl: ttl, now: time.Now, states: make(map[string]terminalState)}
+}
+
+// set records the terminal state of the message IDs, evicting any expired states.
+func (ts *terminalStates) set(state TxState, msgIDs ...string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := ts.now()
+	ts.evict(now)
+	for _, msgID := range msgIDs {
+		expiresAt := now.Add(ts.ttl)
+		ts.states[msgID] = terminalState{state: state, expiresAt: expiresAt}
+		ts.expiries = append(ts.expiries, expiry{msgID: msgID, at: expiresAt})
+	}
+}
+
+// get returns the terminal state of the message ID, or StateUnknown if there is none.
+func (ts *terminalStates) get(msgID string) TxState {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.evict(ts.now())
+	return ts.states[msgID].state
+}
+
+// evict removes the states expired as of now. Requires ts.mu to be held.
+func (ts *terminalStates) evict(now time.Time) {
+	i := 0
+	for ; i < len(ts.expiries) && !now.Before(ts.expiries[i].at); i++ {
+		// The state may have been set again since, in which case it expires later.
+		e := ts.expiries[i]
+		if ts.states[e.msgID].expiresAt.Equal(e.at) {
+			delete(ts.states, e.msgID)
+		}
+	}
+	ts.expiries = ts.expiries[i:]
+}
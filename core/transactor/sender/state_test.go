@@ -0,0 +1,101 @@
+package sender
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestState(t *testing.T) {
+	var (
+		sending = make(chan struct{})
+		unblock = make(chan struct{})
+		sendErr error
+	)
+	clock := NewFakeClock(time.Unix(0, 0))
+	s := newTestSender(
+		NewExpoRetryPolicy(1, time.Millisecond),
+		func(context.Context, *coretypes.Transaction) error {
+			sending <- struct{}{}
+			<-unblock
+			return sendErr
+		},
+		WithClock(clock),
+	)
+
+	// Unknown until sent, then sending while the tx is sending.
+	require.Equal(t, StateUnknown, s.State("a"))
+	done := make(chan error)
+	go func() {
+		_, err := s.SendTransaction(context.Background(), newTestTx(0), []string{"a", "b"})
+		done <- err
+	}()
+	<-sending
+	require.Equal(t, StateSending, s.State("a"))
+	require.Equal(t, StateSending, s.State("b"))
+
+	// Sent once the tx is sent, until the TTL elapses.
+	close(unblock)
+	require.NoError(t, <-done)
+	require.Equal(t, StateSent, s.State("a"))
+	require.Equal(t, StateSent, s.State("b"))
+	clock.Advance(defaultStateTTL - time.Second)
+	require.Equal(t, StateSent, s.State("a"))
+	clock.Advance(time.Second)
+	require.Equal(t, StateUnknown, s.State("a"))
+	require.Equal(t, StateUnknown, s.State("b"))
+
+	// Failed once the tx fails permanently (after its retry's backoff), and sending again if
+	// resent.
+	sendErr = errRPCUnavailable
+	stopDraining := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-sending:
+			case <-stopDraining:
+				return
+			default:
+				if clock.Waiters() > 0 {
+					clock.Advance(time.Second)
+				}
+			}
+		}
+	}()
+	_, err := s.SendTransaction(context.Background(), newTestTx(1), []string{"a"})
+	close(stopDraining)
+	require.ErrorIs(t, err, errRPCUnavailable)
+	require.Equal(t, StateFailed, s.State("a"))
+	sendErr, unblock = nil, make(chan struct{})
+	go func() {
+		_, resendErr := s.SendTransaction(context.Background(), newTestTx(2), []string{"a"})
+		done <- resendErr
+	}()
+	<-sending
+	require.Equal(t, StateSending, s.State("a"))
+	close(unblock)
+	require.NoError(t, <-done)
+	require.Equal(t, StateSent, s.State("a"))
+}
+
+func TestTerminalStatesReset(t *testing.T) {
+	now := time.Unix(0, 0)
+	ts := newTerminalStates(time.Minute)
+	ts.now = func() time.Time { return now }
+
+	// Setting the state again extends its expiry.
+	ts.set(StateFailed, "a")
+	now = now.Add(30 * time.Second)
+	ts.set(StateSent, "a")
+	now = now.Add(30 * time.Second)
+	require.Equal(t, StateSent, ts.get("a"))
+	require.Len(t, ts.expiries, 1)
+	now = now.Add(30 * time.Second)
+	require.Equal(t, StateUnknown, ts.get("a"))
+	require.Empty(t, ts.states)
+	require.Empty(t, ts.expiries)
+}
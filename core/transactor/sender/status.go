@@ -0,0 +1,64 @@
+package sender
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// SenderStatus is a snapshot of the state of a Sender, for ops visibility.
+type SenderStatus struct {
+	// InFlight is the number of sends accepted and not yet done (including their retries).
+	InFlight int64 `json:"inFlight"`
+	// Sent is the number of txs sent successfully since the Sender was created.
+	Sent uint64 `json:"sent"`
+	// Failed is the number of txs that failed to send since the Sender was created.
+	Failed uint64 `json:"failed"`
+	// LastError is the error of the last tx that failed to send, if any.
+	LastError string `json:"lastError,omitempty"`
+	// LastErrorAt is when the last tx failed to send, if any.
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// sendCounters counts the outcomes of sends, lock-free.
+type sendCounters struct {
+	inFlight atomic.Int64
+	sent     atomic.Uint64
+	failed   atomic.Uint64
+	lastErr  atomic.Pointer[sendFailure]
+}
+
+type sendFailure struct {
+	err string
+	at  time.Time
+}
+
+// fail counts a send that failed with the given error at the given time.
+func (sc *sendCounters) fail(err error, at time.Time) {
+	sc.failed.Add(1)
+	sc.lastErr.Store(&sendFailure{err: err.Error(), at: at})
+}
+
+// Status returns a snapshot of the Sender's state. It's cheap to call, and safe to call
+// concurrently with sends.
+func (s *Sender) Status() SenderStatus {
+	status := SenderStatus{
+		InFlight: s.counters.inFlight.Load(),
+		Sent:     s.counters.sent.Load(),
+		Failed:   s.counters.failed.Load(),
+	}
+	if lastErr := s.counters.lastErr.Load(); lastErr != nil {
+		status.LastError, status.LastErrorAt = lastErr.err, &lastErr.at
+	}
+	return status
+}
+
+// StatusHandler returns an HTTP handler that responds with the Sender's status as JSON, e.g. to
+// register on the server with RegisterHandler.
+func (s *Sender) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.Status())
+	})
+}
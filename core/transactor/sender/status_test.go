@@ -0,0 +1,72 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/core"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSenderStatus(t *testing.T) {
+	var (
+		unblock = make(chan struct{})
+		sending = make(chan struct{})
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{},
+		func(_ context.Context, tx *coretypes.Transaction) error {
+			switch tx.Nonce() {
+			case 2:
+				return core.ErrInsufficientFunds
+			case 3:
+				close(sending)
+				<-unblock
+			}
+			return nil
+		},
+	)
+
+	status := func() SenderStatus {
+		rec := httptest.NewRecorder()
+		s.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sender", nil))
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var status SenderStatus
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+		return status
+	}
+	require.Equal(t, SenderStatus{}, status())
+
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		_, _ = s.SendTransaction(context.Background(), newTestTx(nonce), nil)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := s.SendTransaction(context.Background(), newTestTx(3), nil)
+		require.NoError(t, err)
+	}()
+	<-sending
+
+	got := status()
+	require.Equal(t, int64(1), got.InFlight)
+	require.Equal(t, uint64(2), got.Sent)
+	require.Equal(t, uint64(1), got.Failed)
+	require.Equal(t, core.ErrInsufficientFunds.Error(), got.LastError)
+	require.NotNil(t, got.LastErrorAt)
+
+	close(unblock)
+	wg.Wait()
+	got = s.Status()
+	require.Zero(t, got.InFlight)
+	require.Equal(t, uint64(3), got.Sent)
+}
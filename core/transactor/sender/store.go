@@ -0,0 +1,69 @@
+package sender
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var _ SendStateStore = (*FileSendStateStore)(nil)
+
+// FileSendStateStore is a SendStateStore that persists each sending message ID as an empty file in
+// a directory.
+type FileSendStateStore struct {
+	dir string
+}
+
+// NewFileSendStateStore creates a FileSendStateStore in the given directory, creating the
+// directory if necessary.
+func NewFileSendStateStore(dir string) (*FileSendStateStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileSendStateStore{dir: dir}, nil
+}
+
+// Put records that the message ID is sending.
+func (f *FileSendStateStore) Put(msgID string) error {
+	file, err := os.Create(f.path(msgID))
+	if err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Delete records that the message ID is done sending. Deleting an unknown message ID is a no-op.
+func (f *FileSendStateStore) Delete(msgID string) error {
+	if err := os.Remove(f.path(msgID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List returns the message IDs that are recorded as sending.
+func (f *FileSendStateStore) List() ([]string, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	msgIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		msgID, err := base64.RawURLEncoding.DecodeString(entry.Name())
+		if err != nil || entry.IsDir() {
+			continue // not written by this store
+		}
+		msgIDs = append(msgIDs, string(msgID))
+	}
+	return msgIDs, nil
+}
+
+// path returns the path of the file for the message ID, which is encoded to be a valid file name.
+func (f *FileSendStateStore) path(msgID string) string {
+	return filepath.Join(f.dir, base64.RawURLEncoding.EncodeToString([]byte(msgID)))
+}
@@ -0,0 +1,52 @@
+package sender
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestFileSendStateStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileSendStateStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("a"))
+	require.NoError(t, store.Put("b/../c"))
+	require.NoError(t, store.Put("a"))
+	msgIDs, err := store.List()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b/../c"}, msgIDs)
+
+	require.NoError(t, store.Delete("a"))
+	require.NoError(t, store.Delete("unknown"))
+
+	// The state survives reopening the store, as after a crash.
+	store, err = NewFileSendStateStore(dir)
+	require.NoError(t, err)
+	msgIDs, err = store.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"b/../c"}, msgIDs)
+}
+
+func TestSendTransactionSendStateStore(t *testing.T) {
+	store, err := NewFileSendStateStore(t.TempDir())
+	require.NoError(t, err)
+
+	var persisted []string
+	s := newTestSender(&noRetryPolicy{}, func(context.Context, *coretypes.Transaction) error {
+		persisted, err = store.List()
+		return err
+	}, WithSendStateStore(store))
+
+	_, err = s.SendTransaction(context.Background(), newTestTx(0), []string{"a", "b"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b"}, persisted)
+
+	msgIDs, err := store.List()
+	require.NoError(t, err)
+	require.Empty(t, msgIDs)
+}
@@ -0,0 +1,51 @@
+package sender
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Names of the spans and span events of the Sender.
+const (
+	spanSendTransaction   = "sender.SendTransaction"
+	spanSendAttempt       = "sender.sendAttempt"
+	spanCancelTransaction = "sender.CancelTransaction"
+	eventRetrying         = "retrying"
+	eventReplaced         = "replaced"
+)
+
+// defaultTracer is a no-op tracer, used unless a tracer is set with WithTracer.
+var defaultTracer = trace.NewNoopTracerProvider().Tracer("")
+
+// txAttributes returns the span attributes of the tx.
+func txAttributes(tx *coretypes.Transaction) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("tx.hash", tx.Hash().Hex()),
+		attribute.Int64("tx.nonce", int64(tx.Nonce())),
+		attribute.Int64("tx.gas", int64(tx.Gas())),
+		attribute.String("tx.gas_price", tx.GasPrice().String()),
+	}
+}
+
+// startSendAttempt starts a child span of the send span in ctx for an attempt to send the tx.
+func (s *Sender) startSendAttempt(
+	ctx context.Context, tx *coretypes.Transaction, attempt int,
+) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, spanSendAttempt, trace.WithAttributes(
+		append(txAttributes(tx), attribute.Int("attempt", attempt))...,
+	))
+}
+
+// endSpan ends the span, recording the error (if any) as its status.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,100 @@
+package sender
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSendTransactionTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer("test")
+
+	var (
+		errs  = []error{txpool.ErrReplaceUnderpriced, nil}
+		sends int
+	)
+	s := newTestSender(
+		&fixedRetryPolicy{backoff: time.Millisecond},
+		func(context.Context, *coretypes.Transaction) error {
+			sends++
+			return errs[sends-1]
+		},
+		WithTracer(tracer),
+	)
+
+	// Send within a parent span, which the send span should be a child of.
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	tx, msgIDs := newTestTx(3), []string{"msg1", "msg2"}
+	sentHash, err := s.SendTransaction(ctx, tx, msgIDs)
+	require.NoError(t, err)
+	parent.End()
+
+	// The spans are exported in the order they ended: the attempts, the send, then the parent.
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4)
+	attempt1, attempt2, send := spans[0], spans[1], spans[2]
+	require.Equal(t, spanSendAttempt, attempt1.Name)
+	require.Equal(t, spanSendAttempt, attempt2.Name)
+	require.Equal(t, spanSendTransaction, send.Name)
+	require.Equal(t, parent.SpanContext().SpanID(), send.Parent.SpanID())
+	require.Equal(t, send.SpanContext.SpanID(), attempt1.Parent.SpanID())
+	require.Equal(t, send.SpanContext.SpanID(), attempt2.Parent.SpanID())
+
+	require.Subset(t, send.Attributes, []attribute.KeyValue{
+		attribute.StringSlice("msg_ids", msgIDs),
+		attribute.String("tx.hash", tx.Hash().Hex()),
+		attribute.Int64("tx.nonce", 3),
+		attribute.Int64("tx.gas", 21000),
+		attribute.String("tx.sent_hash", sentHash.Hex()),
+	})
+	require.Equal(t, codes.Unset, send.Status.Code)
+
+	// The first attempt failed, so the tx was retried and replaced before the second attempt.
+	require.Subset(t, attempt1.Attributes, []attribute.KeyValue{
+		attribute.String("tx.hash", tx.Hash().Hex()), attribute.Int("attempt", 1),
+	})
+	require.Equal(t, codes.Error, attempt1.Status.Code)
+	require.Subset(t, attempt2.Attributes, []attribute.KeyValue{
+		attribute.String("tx.hash", sentHash.Hex()), attribute.Int("attempt", 2),
+	})
+	require.Equal(t, codes.Unset, attempt2.Status.Code)
+
+	require.Len(t, send.Events, 2)
+	require.Equal(t, eventRetrying, send.Events[0].Name)
+	require.Contains(
+		t, send.Events[0].Attributes,
+		attribute.String("reason", RetryReasonReplaceUnderpriced),
+	)
+	require.Equal(t, eventReplaced, send.Events[1].Name)
+	require.Contains(t, send.Events[1].Attributes, attribute.String("new_hash", sentHash.Hex()))
+}
+
+func TestSendTransactionTracingFailure(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	s := newTestSender(
+		&noRetryPolicy{},
+		func(context.Context, *coretypes.Transaction) error { return errRPCUnavailable },
+		WithTracer(provider.Tracer("test")),
+	)
+
+	_, err := s.SendTransaction(context.Background(), newTestTx(0), nil)
+	require.ErrorIs(t, err, errRPCUnavailable)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, spanSendTransaction, spans[1].Name)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+	require.Equal(t, errRPCUnavailable.Error(), spans[1].Status.Description)
+}
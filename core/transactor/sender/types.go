@@ -10,29 +10,133 @@ import (
 )
 
 type (
-	// Factory is an interface for building transactions, used if retrying.
+	// Factory is an interface for building transactions, used if retrying. Requests and txs are
+	// built as sent from the account of the tx being replaced (the From of requests), or from the
+	// factory's default account if it's the zero address.
 	Factory interface {
 		RebuildTransactionFromRequest(
 			context.Context, *ethereum.CallMsg, uint64,
 		) (*coretypes.Transaction, error)
+
+		// SignTransaction signs the given tx as-is as sent from the given account, used for txs
+		// that can't be rebuilt from a request (i.e. blob txs and cancellations).
+		SignTransaction(
+			context.Context, common.Address, *coretypes.Transaction,
+		) (*coretypes.Transaction, error)
+
+		// EstimateGas estimates the gas limit of the given request, used if the gas limit of a
+		// tx was too low.
+		EstimateGas(context.Context, *ethereum.CallMsg) (uint64, error)
+	}
+
+	// PrivateSender sends txs privately, e.g. through a relay to block builders, instead of
+	// broadcasting them to the public mempool. Implemented by eth.PrivateRelay.
+	PrivateSender interface {
+		SendPrivateTransaction(context.Context, *coretypes.Transaction) error
 	}
 
-	// Noncer is the interface for acquiring fresh nonces, used if retrying.
+	// Noncer is the interface for acquiring fresh nonces of the given account (or of the
+	// factory's default account if it's the zero address), used to replace txs whose nonce was
+	// too low. A fresh nonce never replaces a tx in the mempool, so the replacement's gas isn't
+	// bumped.
 	Noncer interface {
-		Acquire() (uint64, bool)
+		Acquire(from common.Address) (uint64, error)
+	}
+
+	// Metrics is an interface for observing the outcomes of sending transactions.
+	Metrics interface {
+		// IncRetry is called each time a tx is retried, with the reason for retrying.
+		IncRetry(reason string)
+		// IncReplacement is called each time a tx is replaced (i.e. its gas or nonce changed).
+		IncReplacement()
+		// ObserveSendLatency is called with the total time taken by a call to SendTransaction.
+		ObserveSendLatency(time.Duration)
+	}
+
+	// LabeledMetrics is implemented by Metrics that observe the outcomes of sends per label (see
+	// WithLabel). The outcomes of each labeled send are reported to the Metrics for its label.
+	LabeledMetrics interface {
+		Metrics
+		// ForLabel returns the Metrics to report the outcomes of sends with the label to.
+		ForLabel(label string) Metrics
 	}
 )
 
 type (
-	// txReplacementPolicy is a type that takes a tx and returns a replacement tx.
-	txReplacementPolicy interface {
-		GetNew(*coretypes.Transaction, error) (*coretypes.Transaction, error)
+	// TxReplacementPolicy is a type that takes a tx and returns a replacement tx.
+	TxReplacementPolicy interface {
+		// GetNew returns the tx to retry sending in place of the tx that failed to send with the
+		// given error of the given class.
+		GetNew(*coretypes.Transaction, error, ErrorClass) (*coretypes.Transaction, error)
 	}
 
-	// retryPolicy is used to determine if a transaction should be retried and how long to wait
-	// before retrying again.
-	retryPolicy interface {
+	// RetryPolicy is used to determine if a transaction should be retried and how long to wait
+	// before retrying again. It is implemented by the retry policies of this package (e.g.
+	// ExpoRetryPolicy), and may be implemented outside of it.
+	RetryPolicy interface {
+		// Get returns whether to retry the tx that failed to send with the given error, and the
+		// backoff before retrying it. It's called with a nil error once the tx is sent.
 		Get(*coretypes.Transaction, error) (bool, time.Duration)
+		// UpdateTxModified is called with the old and new hashes of a tx that was replaced.
 		UpdateTxModified(common.Hash, common.Hash)
+		// History returns the hashes that the tx with the given (latest) hash has been sent as,
+		// in order, ending with the given hash, or nil if the policy doesn't know the tx.
+		History(common.Hash) []common.Hash
+	}
+
+	// retryHooks are implemented by the retry policies of this package, to be notified of the end
+	// of each send and to classify send errors. A RetryPolicy that doesn't implement them treats
+	// errors as classified by their ErrorClass.
+	retryHooks interface {
+		// done is called once sending the tx with the given hash ends, successfully or not.
+		done(common.Hash)
+		// expected returns true if send errors of the class are expected while sending txs
+		// (e.g. a replacement being underpriced), rather than a sign that something is wrong.
+		expected(ErrorClass) bool
+		// terminal returns true if txs that failed to send with errors of the class must not
+		// be retried, since retrying (or replacing) them can't succeed.
+		terminal(ErrorClass) bool
+	}
+
+	// sendScopedPolicy is implemented by the retry policies of this package, which count the
+	// retries of each send separately, even of the same tx (see retryPolicyForSend).
+	sendScopedPolicy interface {
+		RetryPolicy
+		retryHooks
+		get(*sendingTx, *coretypes.Transaction, error) (bool, time.Duration)
+		updateTxModified(send *sendingTx, oldTx, newTx common.Hash)
+		doneSend(*sendingTx, common.Hash)
+	}
+
+	// clockedPolicy is implemented by the retry policies that tell the time (e.g. the
+	// DeadlineRetryPolicy), which the Sender makes tell it with its clock (see WithClock).
+	clockedPolicy interface {
+		setClock(Clock)
 	}
 )
+
+// FailureHook is called with a tx (as originally given to be sent), its message IDs, and the
+// error that caused it to permanently fail.
+type FailureHook func(tx *coretypes.Transaction, msgIDs []string, err error)
+
+// RetryHook is called with the number of the attempt (starting from 1) that failed to send a tx,
+// the tx that was attempted, and the error that caused it to be retried.
+type RetryHook func(attempt int, tx *coretypes.Transaction, err error)
+
+// BeforeSendHook is called with each signed tx (including replacements) right before it is
+// broadcast. If it returns an error, the tx is not broadcast and sending it fails.
+type BeforeSendHook func(ctx context.Context, tx *coretypes.Transaction) error
+
+// DryRunSink is called with each signed tx that would have been broadcast in dry-run mode.
+type DryRunSink func(tx *coretypes.Transaction)
+
+// SendStateStore persists which message IDs are sending, so that after a crash the message IDs
+// whose txs may have been mid-send can be listed and reconciled.
+type SendStateStore interface {
+	// Put records that the message ID is sending.
+	Put(msgID string) error
+	// Delete records that the message ID is done sending.
+	Delete(msgID string) error
+	// List returns the message IDs that are recorded as sending.
+	List() ([]string, error)
+}
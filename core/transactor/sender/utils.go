@@ -6,33 +6,55 @@ import (
 
 	"github.com/holiman/uint256"
 
+	"github.com/ethereum/go-ethereum/common"
 	coretypes "github.com/ethereum/go-ethereum/core/types"
 )
 
-var (
-	multiplier = big.NewInt(11500) //nolint:gomnd // its okay.
-	quotient   = big.NewInt(10000) //nolint:gomnd // its okay.
+const (
+	// defaultBumpPercent is the default gas bump on a tx (10% is required but add a buffer to be
+	// safe).
+	defaultBumpPercent = 15
+	// minBumpPercent is the minimum gas bump on a tx required by the txpool for a replacement.
+	minBumpPercent = 10
+	// minBlobBumpPercent is the minimum gas bump on a blob tx (for all fee caps) required by the
+	// blobpool for a replacement.
+	minBlobBumpPercent = 100
+	// defaultGasLimitMarginPercent is the default bump on the gas limit of a tx whose gas limit
+	// was too low.
+	defaultGasLimitMarginPercent = 20
 )
 
+var quotient = big.NewInt(100) //nolint:gomnd // its okay.
+
 // BumpGas bumps the gas on a tx by a 15% increase.
 func BumpGas(tx *coretypes.Transaction) *coretypes.Transaction {
-	var innerTx coretypes.TxData
+	return bumpGas(tx, defaultBumpPercent, nil)
+}
+
+// bumpGas bumps the gas on a tx by the given percentage increase, or by the given absolute
+// increase in wei (if not nil) if that is more.
+func bumpGas(tx *coretypes.Transaction, percent int, minBump *big.Int) *coretypes.Transaction {
+	if tx.Type() == coretypes.BlobTxType {
+		percent = max(percent, minBlobBumpPercent)
+	}
+
+	var (
+		innerTx    coretypes.TxData
+		multiplier = big.NewInt(int64(100 + percent)) //nolint:gomnd // its okay.
+	)
 	switch tx.Type() {
 	case coretypes.DynamicFeeTxType, coretypes.BlobTxType:
-		// Bump the existing gas tip cap 15% (10% is required but add a buffer to be safe).
-		bumpedGasTipCap := new(big.Int).Mul(tx.GasTipCap(), multiplier)
-		bumpedGasTipCap = new(big.Int).Quo(bumpedGasTipCap, quotient)
-
-		// Bump the existing gas fee cap 15% (only 10% required but add a buffer to be safe).
-		bumpedGasFeeCap := new(big.Int).Mul(tx.GasFeeCap(), multiplier)
-		bumpedGasFeeCap = new(big.Int).Quo(bumpedGasFeeCap, quotient)
+		// Bump the existing gas tip cap and gas fee cap, both of which must clear the txpool's
+		// minimum bump for the replacement to be accepted.
+		bumpedGasTipCap := bumpFee(tx.GasTipCap(), multiplier, minBump)
+		bumpedGasFeeCap := bumpFee(tx.GasFeeCap(), multiplier, minBump)
 
 		if tx.Type() == coretypes.BlobTxType {
-			// Bump the existing blob gas fee cap 15%. // TODO: verify that this is correct.
-			bumpedBlobGasFeeCap := new(big.Int).Mul(tx.BlobGasFeeCap(), multiplier)
-			bumpedBlobGasFeeCap = new(big.Int).Quo(bumpedBlobGasFeeCap, quotient)
+			// Bump the existing blob gas fee cap, keeping the blob sidecar.
+			bumpedBlobGasFeeCap := bumpFee(tx.BlobGasFeeCap(), multiplier, minBump)
 
 			innerTx = &coretypes.BlobTx{
+				ChainID:    uint256.MustFromBig(tx.ChainId()),
 				Nonce:      tx.Nonce(),
 				To:         *tx.To(),
 				Gas:        tx.Gas(),
@@ -43,23 +65,24 @@ func BumpGas(tx *coretypes.Transaction) *coretypes.Transaction {
 				BlobFeeCap: uint256.MustFromBig(bumpedBlobGasFeeCap),
 				BlobHashes: tx.BlobHashes(),
 				Sidecar:    tx.BlobTxSidecar(),
+				AccessList: tx.AccessList(),
 			}
 		} else {
 			innerTx = &coretypes.DynamicFeeTx{
-				ChainID:   tx.ChainId(),
-				Nonce:     tx.Nonce(),
-				GasTipCap: bumpedGasTipCap,
-				GasFeeCap: bumpedGasFeeCap,
-				Gas:       tx.Gas(),
-				To:        tx.To(),
-				Value:     tx.Value(),
-				Data:      tx.Data(),
+				ChainID:    tx.ChainId(),
+				Nonce:      tx.Nonce(),
+				GasTipCap:  bumpedGasTipCap,
+				GasFeeCap:  bumpedGasFeeCap,
+				Gas:        tx.Gas(),
+				To:         tx.To(),
+				Value:      tx.Value(),
+				Data:       tx.Data(),
+				AccessList: tx.AccessList(),
 			}
 		}
 	case coretypes.LegacyTxType, coretypes.AccessListTxType:
-		// Bump the gas price by 15% (10% is required but we add a buffer to be safe).
-		bumpedGasPrice := new(big.Int).Mul(tx.GasPrice(), multiplier)
-		bumpedGasPrice = new(big.Int).Quo(bumpedGasPrice, quotient)
+		// Bump the gas price, which must clear the txpool's minimum bump.
+		bumpedGasPrice := bumpFee(tx.GasPrice(), multiplier, minBump)
 
 		if tx.Type() == coretypes.AccessListTxType {
 			innerTx = &coretypes.AccessListTx{
@@ -89,20 +112,151 @@ func BumpGas(tx *coretypes.Transaction) *coretypes.Transaction {
 	return coretypes.NewTx(innerTx)
 }
 
+// bumpFee bumps the fee by the multiplier (as a percentage), or by minBump (if not nil) if that is
+// more, but at least by the minimum bump required by the txpool to replace a tx: strictly greater
+// than the fee and no less than minBumpPercent more than the fee, rounded up.
+func bumpFee(fee, multiplier, minBump *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, multiplier)
+	bumped.Quo(bumped, quotient)
+
+	minBumped := new(big.Int).Mul(fee, big.NewInt(100+minBumpPercent)) //nolint:gomnd // its okay.
+	minBumped.Add(minBumped, new(big.Int).Sub(quotient, common.Big1))
+	minBumped.Quo(minBumped, quotient)
+	if minBumped.Cmp(fee) <= 0 {
+		minBumped.Add(fee, common.Big1)
+	}
+
+	if minBump != nil {
+		if absBumped := new(big.Int).Add(fee, minBump); absBumped.Cmp(bumped) > 0 {
+			bumped = absBumped
+		}
+	}
+
+	if bumped.Cmp(minBumped) < 0 {
+		return minBumped
+	}
+	return bumped
+}
+
+// clearsMinBump returns true if the fees of newTx are high enough to replace oldTx in the txpool
+// (or blobpool, for blob txs).
+func clearsMinBump(oldTx, newTx *coretypes.Transaction) bool {
+	percent := minBumpPercent
+	if oldTx.Type() == coretypes.BlobTxType {
+		percent = minBlobBumpPercent
+		if !feeClearsMinBump(oldTx.BlobGasFeeCap(), newTx.BlobGasFeeCap(), percent) {
+			return false
+		}
+	}
+	// For legacy and access list txs, the gas tip cap and gas fee cap are both the gas price.
+	return feeClearsMinBump(oldTx.GasTipCap(), newTx.GasTipCap(), percent) &&
+		feeClearsMinBump(oldTx.GasFeeCap(), newTx.GasFeeCap(), percent)
+}
+
+// feeClearsMinBump returns true if newFee is strictly greater than oldFee and at least the given
+// percentage more than oldFee, rounded up.
+func feeClearsMinBump(oldFee, newFee *big.Int, percent int) bool {
+	threshold := new(big.Int).Mul(oldFee, big.NewInt(int64(100+percent))) //nolint:gomnd // okay.
+	threshold.Add(threshold, new(big.Int).Sub(quotient, common.Big1))
+	threshold.Quo(threshold, quotient)
+	return newFee.Cmp(oldFee) > 0 && newFee.Cmp(threshold) >= 0
+}
+
+// capGasPrice caps the gas price (or gas fee cap and gas tip cap) on a tx at the given max gas
+// price. The blob gas fee cap is not capped.
+func capGasPrice(tx *coretypes.Transaction, maxGasPrice *big.Int) *coretypes.Transaction {
+	if tx.GasFeeCap().Cmp(maxGasPrice) <= 0 {
+		return tx
+	}
+
+	var innerTx coretypes.TxData
+	switch tx.Type() {
+	case coretypes.DynamicFeeTxType:
+		innerTx = &coretypes.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  bigMin(tx.GasTipCap(), maxGasPrice),
+			GasFeeCap:  maxGasPrice,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	case coretypes.LegacyTxType:
+		innerTx = &coretypes.LegacyTx{
+			Nonce:    tx.Nonce(),
+			To:       tx.To(),
+			Gas:      tx.Gas(),
+			GasPrice: maxGasPrice,
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	case coretypes.AccessListTxType:
+		innerTx = &coretypes.AccessListTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasPrice:   maxGasPrice,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	case coretypes.BlobTxType:
+		innerTx = &coretypes.BlobTx{
+			ChainID:    uint256.MustFromBig(tx.ChainId()),
+			Nonce:      tx.Nonce(),
+			To:         *tx.To(),
+			Gas:        tx.Gas(),
+			Value:      uint256.MustFromBig(tx.Value()),
+			Data:       tx.Data(),
+			GasTipCap:  uint256.MustFromBig(bigMin(tx.GasTipCap(), maxGasPrice)),
+			GasFeeCap:  uint256.MustFromBig(maxGasPrice),
+			BlobFeeCap: uint256.MustFromBig(tx.BlobGasFeeCap()),
+			BlobHashes: tx.BlobHashes(),
+			Sidecar:    tx.BlobTxSidecar(),
+			AccessList: tx.AccessList(),
+		}
+	default:
+		panic(fmt.Sprintf("trying to cap gas price on unknown tx type (%d)", tx.Type()))
+	}
+
+	return coretypes.NewTx(innerTx)
+}
+
+// txFrom returns the address of the account that signed the tx.
+func txFrom(tx *coretypes.Transaction) (common.Address, error) {
+	from, err := coretypes.Sender(coretypes.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover the sender of the tx: %w", err)
+	}
+	return from, nil
+}
+
+// bigMin returns the smaller of a and b.
+func bigMin(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+	return b
+}
+
 // SetNonce sets the given nonce on a tx.
 func SetNonce(tx *coretypes.Transaction, nonce uint64) *coretypes.Transaction {
 	var innerTx coretypes.TxData
 	switch tx.Type() {
 	case coretypes.DynamicFeeTxType:
 		innerTx = &coretypes.DynamicFeeTx{
-			ChainID:   tx.ChainId(),
-			Nonce:     nonce,
-			GasTipCap: tx.GasTipCap(),
-			GasFeeCap: tx.GasFeeCap(),
-			Gas:       tx.Gas(),
-			To:        tx.To(),
-			Value:     tx.Value(),
-			Data:      tx.Data(),
+			ChainID:    tx.ChainId(),
+			Nonce:      nonce,
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
 		}
 	case coretypes.LegacyTxType:
 		innerTx = &coretypes.LegacyTx{
@@ -126,6 +280,7 @@ func SetNonce(tx *coretypes.Transaction, nonce uint64) *coretypes.Transaction {
 		}
 	case coretypes.BlobTxType:
 		innerTx = &coretypes.BlobTx{
+			ChainID:    uint256.MustFromBig(tx.ChainId()),
 			Nonce:      nonce,
 			To:         *tx.To(),
 			Gas:        tx.Gas(),
@@ -136,6 +291,7 @@ func SetNonce(tx *coretypes.Transaction, nonce uint64) *coretypes.Transaction {
 			BlobFeeCap: uint256.MustFromBig(tx.BlobGasFeeCap()),
 			BlobHashes: tx.BlobHashes(),
 			Sidecar:    tx.BlobTxSidecar(),
+			AccessList: tx.AccessList(),
 		}
 	default:
 		panic(fmt.Sprintf("trying to set nonce on unknown tx type (%d)", tx.Type()))
@@ -143,3 +299,67 @@ func SetNonce(tx *coretypes.Transaction, nonce uint64) *coretypes.Transaction {
 
 	return coretypes.NewTx(innerTx)
 }
+
+// bumpGasLimit bumps the gas limit on a tx by the given percentage increase, and at least by 1.
+func bumpGasLimit(tx *coretypes.Transaction, percent int) *coretypes.Transaction {
+	gas := tx.Gas() * uint64(100+percent) / 100 //nolint:gomnd // its okay.
+	return setGasLimit(tx, max(gas, tx.Gas()+1))
+}
+
+// setGasLimit sets the given gas limit on a tx.
+func setGasLimit(tx *coretypes.Transaction, gas uint64) *coretypes.Transaction {
+	var innerTx coretypes.TxData
+	switch tx.Type() {
+	case coretypes.DynamicFeeTxType:
+		innerTx = &coretypes.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        gas,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	case coretypes.LegacyTxType:
+		innerTx = &coretypes.LegacyTx{
+			Nonce:    tx.Nonce(),
+			To:       tx.To(),
+			Gas:      gas,
+			GasPrice: tx.GasPrice(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	case coretypes.AccessListTxType:
+		innerTx = &coretypes.AccessListTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasPrice:   tx.GasPrice(),
+			Gas:        gas,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	case coretypes.BlobTxType:
+		innerTx = &coretypes.BlobTx{
+			ChainID:    uint256.MustFromBig(tx.ChainId()),
+			Nonce:      tx.Nonce(),
+			To:         *tx.To(),
+			Gas:        gas,
+			Value:      uint256.MustFromBig(tx.Value()),
+			Data:       tx.Data(),
+			GasTipCap:  uint256.MustFromBig(tx.GasTipCap()),
+			GasFeeCap:  uint256.MustFromBig(tx.GasFeeCap()),
+			BlobFeeCap: uint256.MustFromBig(tx.BlobGasFeeCap()),
+			BlobHashes: tx.BlobHashes(),
+			Sidecar:    tx.BlobTxSidecar(),
+			AccessList: tx.AccessList(),
+		}
+	default:
+		panic(fmt.Sprintf("trying to set gas limit on unknown tx type (%d)", tx.Type()))
+	}
+
+	return coretypes.NewTx(innerTx)
+}
@@ -0,0 +1,68 @@
+package sender
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// receiptPollInterval is how often to poll the chain for a tx receipt (ideally 1 block time).
+const receiptPollInterval = 500 * time.Millisecond
+
+// SendAndWait sends a transaction (retrying according to the configured policies) and then waits
+// until its receipt has the given number of confirmations. If the tx was replaced while sending,
+// the receipt of the replacement tx is waited on. Waiting is bounded only by the context.
+func (s *Sender) SendAndWait(
+	ctx context.Context, tx *coretypes.Transaction, msgIDs []string, confirmations uint64,
+) (*coretypes.Receipt, error) {
+	hash, err := s.SendTransaction(ctx, tx, msgIDs)
+	if err != nil {
+		return nil, err
+	}
+	return s.waitConfirmed(ctx, hash, confirmations)
+}
+
+// waitConfirmed polls for the receipt of the given tx hash until it has the given number of
+// confirmations or the context is done. A tx included in the latest block has 1 confirmation.
+// Polls on the Sender's clock, fetching the receipt from the chain (not the cache) on every poll
+// so that a reorg of the tx into another block is noticed.
+func (s *Sender) waitConfirmed(
+	ctx context.Context, hash common.Hash, confirmations uint64,
+) (*coretypes.Receipt, error) {
+	for {
+		if receipt := s.confirmedReceipt(ctx, hash, confirmations); receipt != nil {
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.clock.After(receiptPollInterval):
+		}
+	}
+}
+
+// confirmedReceipt returns the receipt of the given tx hash if it has the given number of
+// confirmations, or nil if it doesn't (or it can't be looked up). Once confirmed, the receipt is
+// fetched again to check that the tx wasn't reorged into another block meanwhile.
+func (s *Sender) confirmedReceipt(
+	ctx context.Context, hash common.Hash, confirmations uint64,
+) *coretypes.Receipt {
+	receipt, err := s.fetchReceipt(ctx, hash)
+	if err != nil {
+		return nil
+	}
+	latest, err := s.chain.BlockNumber(ctx)
+	if err != nil || latest+1 < receipt.BlockNumber.Uint64()+confirmations {
+		return nil
+	}
+
+	// The tx may have been reorged into another block since its receipt was fetched.
+	recheck, err := s.fetchReceipt(ctx, hash)
+	if err != nil || recheck.BlockHash != receipt.BlockHash {
+		return nil
+	}
+	return recheck
+}
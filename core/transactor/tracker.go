@@ -13,7 +13,6 @@ import (
 
 // OnError is called when a transaction request fails to build or send.
 func (t *TxrV2) OnError(_ context.Context, resp *tracker.Response) error {
-	t.noncer.RemoveAcquired(resp.Nonce())
 	t.removeStateTracking(resp.MsgIDs...)
 	t.logger.Error("❌ error sending transaction", "err", resp.Error, "msgs", resp.MsgIDs)
 
@@ -76,7 +75,12 @@ func (t *TxrV2) OnStale(ctx context.Context, resp *tracker.Response, isPending b
 		// by bumping gas. Resend it (same tx data, same nonce) with a bumped gas.
 		resp.Transaction = sender.BumpGas(resp.Transaction)
 		t.fire(ctx, resp, false, types.CallMsgFromTx(resp.Transaction))
-	} else if t.cfg.ResendStaleTxs {
+		return nil
+	}
+
+	// The tx was dropped, leaving a gap at its nonce, so release the nonce to be reused.
+	t.releaseNonce(resp.Transaction, resp.Nonce())
+	if t.cfg.ResendStaleTxs {
 		// Try resending the tx to the chain if configured to do so. Rebuild it (same tx data, new
 		// nonce) and resend.
 		t.fire(ctx, resp, true, types.CallMsgFromTx(resp.Transaction))
@@ -0,0 +1,352 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/berachain/offchain-sdk/client/eth"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// defaultWatchPollInterval is the default interval between polls of the watched txs (ideally 1
+	// block time).
+	defaultWatchPollInterval = time.Second
+	// minWatchPollInterval bounds the rate at which the watched txs are polled.
+	minWatchPollInterval = 100 * time.Millisecond
+	// watchResultsSize is the number of results buffered for the consumer.
+	watchResultsSize = 64
+)
+
+// Confirmation is the final outcome of a tx watched by a ConfirmationWatcher.
+type Confirmation uint8
+
+const (
+	// Confirmed means the tx's receipt has the required number of confirmations.
+	Confirmed Confirmation = iota
+	// Dropped means the tx is neither mined nor in the mempool, and its nonce is unused.
+	Dropped
+	// Replaced means another tx with the same sender and nonce was mined instead.
+	Replaced
+)
+
+// String returns the name of the outcome.
+func (c Confirmation) String() string {
+	switch c {
+	case Confirmed:
+		return "confirmed"
+	case Dropped:
+		return "dropped"
+	case Replaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// Rebroadcaster re-sends a tx (e.g. through the retry path of the transactor's Sender), returning
+// the hash of the tx that was sent, which differs if the tx was replaced while sending.
+type Rebroadcaster func(
+	ctx context.Context, tx *coretypes.Transaction, msgIDs []string,
+) (common.Hash, error)
+
+// WatchedTx is a sent tx to watch, with the IDs of the messages it contains.
+type WatchedTx struct {
+	Hash   common.Hash
+	MsgIDs []string
+}
+
+// ConfirmationResult is the outcome of a watched tx.
+type ConfirmationResult struct {
+	Hash    common.Hash
+	MsgIDs  []string
+	Outcome Confirmation
+	Receipt *coretypes.Receipt // only set if Confirmed
+	Reorged bool               // whether the tx was mined in a block that was reorged out
+}
+
+// ConfirmationWatcher watches many sent txs in the background, polling the chain for their
+// receipts (in one batch request per poll) until each has the required number of confirmations,
+// or is dropped or replaced. Each outcome is emitted once on the Results channel.
+//
+// If a rebroadcaster is set, a tx whose receipt disappears (i.e. its block was reorged out) is
+// re-sent, and the tx that was sent is watched in its place.
+type ConfirmationWatcher struct {
+	ethClient     eth.Client
+	confirmations uint64
+	pollInterval  time.Duration
+	rebroadcast   Rebroadcaster
+	results       chan ConfirmationResult
+
+	mu      sync.Mutex
+	watched map[common.Hash]*watchedTx
+}
+
+// watchedTx is the state of a watched tx, as of the last poll.
+type watchedTx struct {
+	msgIDs  []string
+	tx      *coretypes.Transaction // set once the tx is found by hash
+	from    common.Address
+	block   common.Hash // hash of the block the tx was last mined in, if any
+	reorged bool
+
+	rebroadcasting atomic.Bool // whether the tx is being re-sent after a reorg
+}
+
+// NewConfirmationWatcher creates a ConfirmationWatcher that waits for the given number of
+// confirmations (a tx included in the latest block has 1). The watched txs are polled every
+// pollInterval (1s if 0), which is at least 100ms. The watcher must be started to poll.
+func NewConfirmationWatcher(
+	ethClient eth.Client, confirmations uint64, pollInterval time.Duration,
+) *ConfirmationWatcher {
+	if pollInterval == 0 {
+		pollInterval = defaultWatchPollInterval
+	}
+	return &ConfirmationWatcher{
+		ethClient:     ethClient,
+		confirmations: confirmations,
+		pollInterval:  max(pollInterval, minWatchPollInterval),
+		results:       make(chan ConfirmationResult, watchResultsSize),
+		watched:       make(map[common.Hash]*watchedTx),
+	}
+}
+
+// SetRebroadcaster sets the rebroadcaster of the txs that are reorged out. It must be set before
+// the watcher is started.
+func (w *ConfirmationWatcher) SetRebroadcaster(rebroadcast Rebroadcaster) {
+	w.rebroadcast = rebroadcast
+}
+
+// Watch starts watching the txs. Watching a tx that is already watched adds its new message IDs.
+func (w *ConfirmationWatcher) Watch(txs ...WatchedTx) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, tx := range txs {
+		wtx, ok := w.watched[tx.Hash]
+		if !ok {
+			w.watched[tx.Hash] = &watchedTx{msgIDs: append([]string(nil), tx.MsgIDs...)}
+			continue
+		}
+		for _, msgID := range tx.MsgIDs {
+			if !contains(wtx.msgIDs, msgID) {
+				wtx.msgIDs = append(wtx.msgIDs, msgID)
+			}
+		}
+	}
+}
+
+// Watching returns the number of txs that are watched (i.e. without an outcome yet).
+func (w *ConfirmationWatcher) Watching() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.watched)
+}
+
+// Results returns the channel of outcomes, which is closed once the watcher stops.
+func (w *ConfirmationWatcher) Results() <-chan ConfirmationResult {
+	return w.results
+}
+
+// Start polls the watched txs in the background until the context is done. Emitting results
+// blocks while the results channel is full.
+func (w *ConfirmationWatcher) Start(ctx context.Context) {
+	go func() {
+		defer close(w.results)
+
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.poll(ctx)
+			}
+		}
+	}()
+}
+
+// poll checks each watched tx once and emits the outcomes of the txs that have one.
+func (w *ConfirmationWatcher) poll(ctx context.Context) {
+	w.mu.Lock()
+	hashes := make([]common.Hash, 0, len(w.watched))
+	for hash := range w.watched {
+		hashes = append(hashes, hash)
+	}
+	w.mu.Unlock()
+	if len(hashes) == 0 {
+		return
+	}
+
+	latest, err := w.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+	// Fetch all the receipts in one batch, falling back to fetching each if batching fails (e.g.
+	// it's unsupported by the endpoint).
+	receipts, batchErr := eth.BatchReceipts(ctx, w.ethClient, hashes)
+	for i, hash := range hashes {
+		w.mu.Lock()
+		wtx := w.watched[hash]
+		w.mu.Unlock()
+
+		var receipt *coretypes.Receipt
+		switch {
+		case batchErr != nil:
+			receipt, err = w.ethClient.TransactionReceipt(ctx, hash)
+		case receipts[i] == nil:
+			err = ethereum.NotFound
+		default:
+			receipt, err = receipts[i], nil
+		}
+		result, done := w.check(ctx, hash, wtx, latest, receipt, err)
+		if !done {
+			continue
+		}
+
+		// The tx may have been replaced by its rebroadcast since, which is watched instead.
+		w.mu.Lock()
+		if w.watched[hash] != wtx {
+			w.mu.Unlock()
+			continue
+		}
+		delete(w.watched, hash)
+		result.MsgIDs = wtx.msgIDs
+		w.mu.Unlock()
+
+		select {
+		case w.results <- result:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check returns the outcome of the tx as of the latest block, given the result of fetching its
+// receipt, or false if it has none yet. Only the poll loop accesses the fields of wtx other than
+// msgIDs and rebroadcasting.
+func (w *ConfirmationWatcher) check(
+	ctx context.Context, hash common.Hash, wtx *watchedTx, latest uint64,
+	receipt *coretypes.Receipt, err error,
+) (ConfirmationResult, bool) {
+	result := ConfirmationResult{Hash: hash}
+
+	switch {
+	case err == nil:
+		// A tx mined in a different block than before was reorged out of the earlier one.
+		if wtx.block != (common.Hash{}) && wtx.block != receipt.BlockHash {
+			wtx.reorged = true
+		}
+		wtx.block = receipt.BlockHash
+		if wtx.tx == nil && w.rebroadcast != nil {
+			_ = w.loadTx(ctx, hash, wtx)
+		}
+		// Confirmations are counted from the block the tx is currently mined in, so blocks built
+		// on a reorged out block never count.
+		if latest+1 < receipt.BlockNumber.Uint64()+w.confirmations {
+			return result, false
+		}
+		result.Outcome, result.Receipt, result.Reorged = Confirmed, receipt, wtx.reorged
+		return result, true
+	case !errors.Is(err, ethereum.NotFound):
+		return result, false
+	case wtx.block != (common.Hash{}):
+		// The tx was mined but its receipt is gone, so its block was reorged out.
+		wtx.block, wtx.reorged = common.Hash{}, true
+		if w.rebroadcast != nil && wtx.tx != nil && wtx.rebroadcasting.CompareAndSwap(false, true) {
+			go w.rebroadcastTx(ctx, hash, wtx)
+		}
+	}
+	result.Reorged = wtx.reorged
+	if wtx.rebroadcasting.Load() {
+		// Wait for the rebroadcast, which may be dropping or replacing the tx itself.
+		return result, false
+	}
+
+	// Not mined, so check whether the tx is still in the mempool.
+	switch err = w.loadTx(ctx, hash, wtx); {
+	case err == nil:
+		return result, false
+	case !errors.Is(err, ethereum.NotFound):
+		return result, false
+	case wtx.tx == nil:
+		// Never seen, so the sender and nonce of the tx are unknown.
+		result.Outcome = Dropped
+		return result, true
+	}
+
+	// The tx is gone, so it was replaced if its nonce has since been used.
+	nonce, err := w.ethClient.NonceAt(ctx, wtx.from, nil)
+	if err != nil {
+		return result, false
+	}
+	result.Outcome = Dropped
+	if nonce > wtx.tx.Nonce() {
+		result.Outcome = Replaced
+	}
+	return result, true
+}
+
+// loadTx looks up the tx by hash, recording it the first time it's found.
+func (w *ConfirmationWatcher) loadTx(ctx context.Context, hash common.Hash, wtx *watchedTx) error {
+	tx, _, err := w.ethClient.TransactionByHash(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if wtx.tx == nil {
+		wtx.tx = tx
+		wtx.from, _ = coretypes.Sender(coretypes.LatestSignerForChainID(tx.ChainId()), tx)
+	}
+	return nil
+}
+
+// rebroadcastTx re-sends the reorged out tx. If a different tx is sent (i.e. the tx was replaced
+// while sending), it's watched in place of the tx. If sending fails, the tx is watched until it's
+// dropped or replaced as usual.
+func (w *ConfirmationWatcher) rebroadcastTx(
+	ctx context.Context, hash common.Hash, wtx *watchedTx,
+) {
+	defer wtx.rebroadcasting.Store(false)
+
+	w.mu.Lock()
+	msgIDs := append([]string(nil), wtx.msgIDs...)
+	w.mu.Unlock()
+
+	sent, err := w.rebroadcast(ctx, wtx.tx, msgIDs)
+	if err != nil || sent == hash {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watched[hash] != wtx {
+		return
+	}
+	delete(w.watched, hash)
+	if other, ok := w.watched[sent]; ok {
+		for _, msgID := range wtx.msgIDs {
+			if !contains(other.msgIDs, msgID) {
+				other.msgIDs = append(other.msgIDs, msgID)
+			}
+		}
+		return
+	}
+	w.watched[sent] = &watchedTx{msgIDs: wtx.msgIDs, reorged: true}
+}
+
+// contains returns whether the message IDs contain the message ID.
+func contains(msgIDs []string, msgID string) bool {
+	for _, id := range msgIDs {
+		if id == msgID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package tracker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/client/eth/ethmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestConfirmationWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	client := ethmock.New()
+	client.SetBlockNumber(10)
+	watcher := NewConfirmationWatcher(client, 3, minWatchPollInterval)
+	watcher.Start(ctx)
+
+	// Send a tx to be confirmed, one to be replaced and one to be dropped after a reorg.
+	confirmed := sendTx(t, client, key, 0, 1)
+	replaced := sendTx(t, client, key, 1, 1)
+	dropped := sendTx(t, client, key, 2, 1)
+	watcher.Watch(
+		WatchedTx{Hash: confirmed.Hash(), MsgIDs: []string{"a"}},
+		WatchedTx{Hash: replaced.Hash(), MsgIDs: []string{"b"}},
+		WatchedTx{Hash: dropped.Hash(), MsgIDs: []string{"c"}},
+		WatchedTx{Hash: confirmed.Hash(), MsgIDs: []string{"a", "d"}}, // deduplicated
+	)
+	require.Equal(t, 3, watcher.Watching())
+
+	// Mine the first and last txs, with too few confirmations yet.
+	mine(client, confirmed, 9)
+	mine(client, dropped, 9)
+	require.Never(t, func() bool { return len(watcher.Results()) > 0 },
+		3*minWatchPollInterval, minWatchPollInterval)
+
+	// The last tx's block is reorged out and the tx is dropped from the mempool.
+	client.RemoveReceipt(dropped.Hash())
+	client.DropTransaction(dropped.Hash())
+	client.SetNonce(from, 2)
+	result := nextResult(t, watcher)
+	require.Equal(t, Dropped, result.Outcome)
+	require.Equal(t, dropped.Hash(), result.Hash)
+	require.Equal(t, []string{"c"}, result.MsgIDs)
+	require.True(t, result.Reorged)
+
+	// The first tx reaches 3 confirmations.
+	client.SetBlockNumber(11)
+	result = nextResult(t, watcher)
+	require.Equal(t, Confirmed, result.Outcome)
+	require.Equal(t, []string{"a", "d"}, result.MsgIDs)
+	require.Equal(t, uint64(9), result.Receipt.BlockNumber.Uint64())
+	require.False(t, result.Reorged)
+
+	// The second tx is replaced by a tx with the same nonce and a higher tip.
+	client.DropTransaction(replaced.Hash())
+	mine(client, sendTx(t, client, key, 1, 2), 11)
+	result = nextResult(t, watcher)
+	require.Equal(t, Replaced, result.Outcome)
+	require.Equal(t, replaced.Hash(), result.Hash)
+	require.Equal(t, []string{"b"}, result.MsgIDs)
+	require.Zero(t, watcher.Watching())
+	// The receipts are fetched in batches.
+	require.Zero(t, client.Calls(ethmock.MethodTransactionReceipt))
+
+	// A tx that was never seen is dropped.
+	watcher.Watch(WatchedTx{Hash: common.HexToHash("0x1")})
+	require.Equal(t, Dropped, nextResult(t, watcher).Outcome)
+
+	// The results channel is closed once the watcher stops.
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-watcher.Results()
+		return !ok
+	}, time.Second, minWatchPollInterval)
+}
+
+// sendTx signs and sends a tx with the given nonce and tip (in gwei) through the client.
+func sendTx(
+	t *testing.T, client *ethmock.Client, key *ecdsa.PrivateKey, nonce, tip int64,
+) *coretypes.Transaction {
+	t.Helper()
+	to := common.HexToAddress("0x2")
+	tx, err := coretypes.SignNewTx(key, coretypes.LatestSignerForChainID(big.NewInt(1)),
+		&coretypes.DynamicFeeTx{
+			ChainID: big.NewInt(1), Nonce: uint64(nonce), GasTipCap: big.NewInt(tip * 1e9),
+			GasFeeCap: big.NewInt(tip * 2e9), Gas: 21000, To: &to,
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, client.SendTransaction(context.Background(), tx))
+	return tx
+}
+
+// mine sets the receipt of the tx in the given block.
+func mine(client *ethmock.Client, tx *coretypes.Transaction, block int64) {
+	client.SetReceipt(&coretypes.Receipt{
+		TxHash: tx.Hash(), Status: coretypes.ReceiptStatusSuccessful,
+		BlockNumber: big.NewInt(block), BlockHash: common.BigToHash(big.NewInt(block)),
+	})
+}
+
+// nextResult waits for the next result of the watcher.
+func nextResult(t *testing.T, watcher *ConfirmationWatcher) ConfirmationResult {
+	t.Helper()
+	select {
+	case result := <-watcher.Results():
+		return result
+	case <-time.After(time.Second):
+		require.FailNow(t, "no result")
+		return ConfirmationResult{}
+	}
+}
+
+func TestConfirmationWatcherRebroadcast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	client := ethmock.New()
+	client.SetBlockNumber(10)
+	watcher := NewConfirmationWatcher(client, 3, minWatchPollInterval)
+	rebroadcasts := make(chan *coretypes.Transaction, 2)
+	replacements := make(chan *coretypes.Transaction, 1)
+	watcher.SetRebroadcaster(func(
+		ctx context.Context, tx *coretypes.Transaction, msgIDs []string,
+	) (common.Hash, error) {
+		require.Equal(t, []string{"a"}, msgIDs)
+		rebroadcasts <- tx
+		if tx.Nonce() == 1 {
+			// Replace the second tx while sending it.
+			tx = sendTx(t, client, key, 1, 2)
+			replacements <- tx
+			return tx.Hash(), nil
+		}
+		return tx.Hash(), client.SendTransaction(ctx, tx)
+	})
+	watcher.Start(ctx)
+
+	// Mine a tx, then reorg out its block before it has 3 confirmations.
+	tx := sendTx(t, client, key, 0, 1)
+	watcher.Watch(WatchedTx{Hash: tx.Hash(), MsgIDs: []string{"a"}})
+	mine(client, tx, 9)
+	require.Never(t, func() bool { return len(rebroadcasts) > 0 },
+		3*minWatchPollInterval, minWatchPollInterval)
+	client.RemoveReceipt(tx.Hash())
+
+	// The tx is rebroadcast and mined again, and its confirmations are counted from its new block.
+	require.Equal(t, tx.Hash(), (<-rebroadcasts).Hash())
+	mine(client, tx, 11)
+	client.SetBlockNumber(12)
+	require.Never(t, func() bool { return len(watcher.Results()) > 0 },
+		3*minWatchPollInterval, minWatchPollInterval)
+	client.SetBlockNumber(13)
+	result := nextResult(t, watcher)
+	require.Equal(t, Confirmed, result.Outcome)
+	require.Equal(t, uint64(11), result.Receipt.BlockNumber.Uint64())
+	require.True(t, result.Reorged)
+	require.Empty(t, rebroadcasts)
+
+	// A rebroadcast tx that is replaced while sending is watched in place of the tx.
+	tx = sendTx(t, client, key, 1, 1)
+	watcher.Watch(WatchedTx{Hash: tx.Hash(), MsgIDs: []string{"a"}})
+	mine(client, tx, 13)
+	require.Never(t, func() bool { return len(rebroadcasts) > 0 },
+		3*minWatchPollInterval, minWatchPollInterval)
+	client.RemoveReceipt(tx.Hash())
+	client.DropTransaction(tx.Hash())
+	require.Equal(t, tx.Hash(), (<-rebroadcasts).Hash())
+
+	replacement := <-replacements
+	mine(client, replacement, 14)
+	client.SetBlockNumber(16)
+	result = nextResult(t, watcher)
+	require.Equal(t, Confirmed, result.Outcome)
+	require.Equal(t, replacement.Hash(), result.Hash)
+	require.Equal(t, []string{"a"}, result.MsgIDs)
+	require.True(t, result.Reorged)
+}
@@ -0,0 +1,176 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/berachain/offchain-sdk/client/eth"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// reconcileTimeout bounds reconciling with the chain while acquiring a nonce for a
+// ReconcilingNoncer.
+const reconcileTimeout = 5 * time.Second
+
+// NonceManager hands out nonces for any number of accounts locally, so that transactions built
+// concurrently for the same account never collide on a nonce. The nonces of an account start at
+// the chain's pending nonce, and increase monotonically from there. Nonces that end up unused are
+// released to be handed out again first, so the nonces used stay gap-free.
+type NonceManager struct {
+	ethClient eth.Client
+
+	mu       sync.Mutex
+	accounts map[common.Address]*accountNonces
+}
+
+// accountNonces is the nonce state of an account.
+type accountNonces struct {
+	mu       sync.Mutex          // held while reconciling with the chain
+	synced   bool                // whether next was reconciled with the chain
+	next     uint64              // next nonce to hand out, unless any are released
+	released map[uint64]struct{} // nonces handed out but unused, below next
+}
+
+// NewNonceManager creates a new NonceManager, which reads pending nonces from the given client.
+func NewNonceManager(ethClient eth.Client) *NonceManager {
+	return &NonceManager{
+		ethClient: ethClient,
+		accounts:  make(map[common.Address]*accountNonces),
+	}
+}
+
+// SetClient sets the client to read pending nonces from, e.g. once the chain is set up.
+func (m *NonceManager) SetClient(ethClient eth.Client) {
+	m.ethClient = ethClient
+}
+
+// Acquire returns the next nonce to use for the account. On the first call for the account (or
+// after a Reset), the nonce is reconciled with the chain's pending nonce.
+func (m *NonceManager) Acquire(ctx context.Context, account common.Address) (uint64, error) {
+	an := m.account(account)
+	an.mu.Lock()
+	defer an.mu.Unlock()
+
+	if !an.synced {
+		if err := m.reconcile(ctx, account, an); err != nil {
+			return 0, err
+		}
+	}
+
+	// Reuse the lowest released nonce, if any, to fill the gap.
+	if len(an.released) > 0 {
+		lowest := an.next
+		for nonce := range an.released {
+			lowest = min(lowest, nonce)
+		}
+		delete(an.released, lowest)
+		return lowest, nil
+	}
+
+	nonce := an.next
+	an.next++
+	return nonce, nil
+}
+
+// Release returns a nonce that was acquired but won't be used (e.g. the transaction failed to
+// build or send), so that it is handed out again.
+func (m *NonceManager) Release(account common.Address, nonce uint64) {
+	an := m.account(account)
+	an.mu.Lock()
+	defer an.mu.Unlock()
+
+	switch {
+	case nonce >= an.next:
+		return // not handed out
+	case nonce == an.next-1:
+		an.next--
+	default:
+		an.released[nonce] = struct{}{}
+	}
+}
+
+// Reconcile reconciles the nonces of the account with the chain's pending nonce, e.g. after
+// transactions were sent for the account elsewhere. Nonces below the pending nonce are never
+// handed out again.
+func (m *NonceManager) Reconcile(ctx context.Context, account common.Address) error {
+	an := m.account(account)
+	an.mu.Lock()
+	defer an.mu.Unlock()
+
+	return m.reconcile(ctx, account, an)
+}
+
+// Reset drops the local nonce state of the account, so that its nonces restart from the chain's
+// pending nonce on the next Acquire, e.g. after transactions with handed out nonces were dropped
+// (leaving a gap that would block all later transactions).
+func (m *NonceManager) Reset(account common.Address) {
+	an := m.account(account)
+	an.mu.Lock()
+	defer an.mu.Unlock()
+
+	an.synced, an.next, an.released = false, 0, make(map[uint64]struct{})
+}
+
+// account returns the nonce state of the account, creating it if necessary.
+func (m *NonceManager) account(account common.Address) *accountNonces {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	an, ok := m.accounts[account]
+	if !ok {
+		an = &accountNonces{released: make(map[uint64]struct{})}
+		m.accounts[account] = an
+	}
+	return an
+}
+
+// reconcile moves the next nonce of the account up to the chain's pending nonce. Requires an.mu
+// to be held.
+func (m *NonceManager) reconcile(
+	ctx context.Context, account common.Address, an *accountNonces,
+) error {
+	pending, err := m.ethClient.PendingNonceAt(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	an.next, an.synced = max(an.next, pending), true
+	for nonce := range an.released {
+		if nonce < pending {
+			delete(an.released, nonce)
+		}
+	}
+	return nil
+}
+
+// ReconcilingNoncer acquires the nonces of any account from a NonceManager, for replacing txs
+// whose nonce was too low (see sender.Noncer). A too-low nonce means the local nonces fell behind
+// the chain (e.g. a gap was filled by txs sent elsewhere), so each nonce is acquired after
+// reconciling the nonces with the chain.
+type ReconcilingNoncer struct {
+	manager        *NonceManager
+	defaultAccount common.Address
+}
+
+// Reconciling returns a ReconcilingNoncer, which acquires the nonces of the given default account
+// for txs whose account is unknown (i.e. the zero address).
+func (m *NonceManager) Reconciling(defaultAccount common.Address) *ReconcilingNoncer {
+	return &ReconcilingNoncer{manager: m, defaultAccount: defaultAccount}
+}
+
+// Acquire reconciles the nonces of the account with the chain and returns the next nonce to use,
+// which never replaces a tx in the mempool. Returns an error if no nonce can be acquired (i.e. the
+// local nonces were reset and the chain can't be reached).
+func (r *ReconcilingNoncer) Acquire(account common.Address) (uint64, error) {
+	if account == (common.Address{}) {
+		account = r.defaultAccount
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	defer cancel()
+
+	_ = r.manager.Reconcile(ctx, account) // best effort, the local nonces are still valid
+	return r.manager.Acquire(ctx, account)
+}
@@ -0,0 +1,121 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/berachain/offchain-sdk/client/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// pendingNonceClient is an eth.Client that only implements fetching pending nonces.
+type pendingNonceClient struct {
+	eth.Client
+	pending map[common.Address]uint64
+	err     error
+}
+
+func (c *pendingNonceClient) PendingNonceAt(
+	_ context.Context, account common.Address,
+) (uint64, error) {
+	return c.pending[account], c.err
+}
+
+func TestNonceManager(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		alice  = common.HexToAddress("0xa")
+		bob    = common.HexToAddress("0xb")
+		client = &pendingNonceClient{pending: map[common.Address]uint64{alice: 5, bob: 0}}
+		m      = NewNonceManager(client)
+	)
+	acquire := func(account common.Address) uint64 {
+		nonce, err := m.Acquire(ctx, account)
+		require.NoError(t, err)
+		return nonce
+	}
+
+	// Nonces start at the pending nonce of each account and increase monotonically.
+	require.EqualValues(t, 5, acquire(alice))
+	require.EqualValues(t, 6, acquire(alice))
+	require.EqualValues(t, 7, acquire(alice))
+	require.EqualValues(t, 0, acquire(bob))
+
+	// Released nonces are reused first, so there are no gaps.
+	m.Release(alice, 6)
+	m.Release(alice, 7)
+	require.EqualValues(t, 6, acquire(alice))
+	require.EqualValues(t, 7, acquire(alice))
+	require.EqualValues(t, 8, acquire(alice))
+
+	// Reconciling skips nonces used elsewhere.
+	client.pending[alice] = 20
+	m.Release(alice, 6)
+	require.NoError(t, m.Reconcile(ctx, alice))
+	require.EqualValues(t, 20, acquire(alice))
+
+	// Resetting restarts from the pending nonce.
+	client.pending[alice] = 10
+	m.Reset(alice)
+	require.EqualValues(t, 10, acquire(alice))
+}
+
+func TestNonceManagerSyncError(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		alice  = common.HexToAddress("0xa")
+		client = &pendingNonceClient{err: errors.New("unreachable")}
+		m      = NewNonceManager(client)
+	)
+	_, err := m.Acquire(ctx, alice)
+	require.ErrorIs(t, err, client.err)
+
+	// The first nonce is synced once the chain is reachable.
+	client.err, client.pending = nil, map[common.Address]uint64{alice: 3}
+	nonce, err := m.Acquire(ctx, alice)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, nonce)
+}
+
+func TestReconcilingNoncer(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		alice  = common.HexToAddress("0xa")
+		bob    = common.HexToAddress("0xb")
+		client = &pendingNonceClient{pending: map[common.Address]uint64{alice: 5, bob: 2}}
+		m      = NewNonceManager(nil)
+		noncer = m.Reconciling(alice)
+	)
+	m.SetClient(client)
+	nonce, err := m.Acquire(ctx, alice)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, nonce)
+
+	// Each nonce is acquired after reconciling with the chain, skipping nonces used elsewhere.
+	client.pending[alice] = 9
+	nonce, err = noncer.Acquire(alice)
+	require.NoError(t, err)
+	require.EqualValues(t, 9, nonce)
+
+	// The nonces of each account are acquired separately, and the zero address is the default
+	// account.
+	nonce, err = noncer.Acquire(bob)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, nonce)
+	nonce, err = noncer.Acquire(common.Address{})
+	require.NoError(t, err)
+	require.EqualValues(t, 10, nonce)
+
+	// Without the chain, the local nonces are still handed out.
+	client.err = errors.New("unreachable")
+	nonce, err = noncer.Acquire(alice)
+	require.NoError(t, err)
+	require.EqualValues(t, 11, nonce)
+
+	// Until they are reset, in which case no nonce can be acquired.
+	m.Reset(alice)
+	_, err = noncer.Acquire(alice)
+	require.ErrorIs(t, err, client.err)
+}
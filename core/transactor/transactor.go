@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/berachain/offchain-sdk/client/eth"
 	"github.com/berachain/offchain-sdk/core/transactor/event"
 	"github.com/berachain/offchain-sdk/core/transactor/factory"
 	"github.com/berachain/offchain-sdk/core/transactor/sender"
@@ -19,6 +20,7 @@ import (
 	queuetypes "github.com/berachain/offchain-sdk/types/queue/types"
 
 	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
 )
 
 // TxrV2 is the main transactor object. TODO: deprecate off being a job.
@@ -27,13 +29,14 @@ type TxrV2 struct {
 	logger     log.Logger
 	signerAddr common.Address
 
-	requests   queuetypes.Queue[*types.Request]
-	factory    *factory.Factory
-	noncer     *tracker.Noncer
-	sender     *sender.Sender
-	senderMu   sync.Mutex
-	dispatcher *event.Dispatcher[*tracker.Response]
-	tracker    *tracker.Tracker
+	requests     queuetypes.Queue[*types.Request]
+	factory      *factory.Factory
+	noncer       *tracker.Noncer
+	nonceManager *tracker.NonceManager
+	sender       *sender.Sender
+	senderMu     sync.Mutex
+	dispatcher   *event.Dispatcher[*tracker.Response]
+	tracker      *tracker.Tracker
 
 	preconfirmedStates map[string]types.PreconfirmedState
 	preconfirmedMu     sync.RWMutex
@@ -57,13 +60,32 @@ func NewTransactor(cfg Config, signer kmstypes.TxSigner, batcher factory.Batcher
 		return nil, errors.New("batcher must be provided when tx batch size is greater than 1")
 	}
 
-	// Build the transactor components.
+	// Build the transactor components. Nonces are handed out by the nonce manager, which the
+	// sender also acquires fresh nonces from to replace txs whose nonce was too low.
 	noncer := tracker.NewNoncer(signer.Address(), cfg.PendingNonceInterval)
+	nonceManager := tracker.NewNonceManager(nil)
 	factory := factory.New(noncer, batcher, signer, cfg.SignTxTimeout)
+	factory.SetNonceManager(nonceManager)
+	factory.SetSimulateBeforeSend(cfg.SimulateBeforeSend)
+	factory.SetGasTipConfig(cfg.GasTip)
 	dispatcher := event.NewDispatcher[*tracker.Response]()
 	tracker := tracker.New(
 		noncer, dispatcher, signer.Address(), cfg.InMempoolTimeout, cfg.TxReceiptTimeout,
 	)
+	var senderOpts []sender.Option
+	if cfg.PrivateRelayURL != "" {
+		relay, err := eth.DialPrivateRelay(context.Background(), cfg.PrivateRelayURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		senderOpts = append(senderOpts, sender.WithPrivateSender(relay, cfg.PrivateRelayFallback))
+	}
+	sender, err := sender.NewFromConfig(
+		factory, nonceManager.Reconciling(signer.Address()), cfg.Sender, senderOpts...,
+	)
+	if err != nil {
+		return nil, err
+	}
 
 	return &TxrV2{
 		cfg:                cfg,
@@ -71,7 +93,8 @@ func NewTransactor(cfg Config, signer kmstypes.TxSigner, batcher factory.Batcher
 		signerAddr:         signer.Address(),
 		factory:            factory,
 		noncer:             noncer,
-		sender:             sender.New(factory, noncer),
+		nonceManager:       nonceManager,
+		sender:             sender,
 		dispatcher:         dispatcher,
 		tracker:            tracker,
 		preconfirmedStates: make(map[string]types.PreconfirmedState),
@@ -102,7 +125,13 @@ func (t *TxrV2) Setup(ctx context.Context) error {
 	t.factory.SetClient(chain)
 	t.sender.Setup(chain, t.logger)
 	t.tracker.SetClient(chain)
-	t.noncer.Start(ctx, chain)
+	t.nonceManager.SetClient(chain)
+
+	// Txs may have been sent from the signer while the transactor was down, so reconcile its
+	// nonces with the chain.
+	if err := t.nonceManager.Reconcile(ctx, t.signerAddr); err != nil {
+		return err
+	}
 
 	// If there are any pending txns at startup, they are likely to be stuck in the mempool.
 	// Resend them.
@@ -117,10 +146,11 @@ func (t *TxrV2) Setup(ctx context.Context) error {
 
 // Execute implements job.Basic.
 func (t *TxrV2) Execute(_ context.Context, _ any) (any, error) {
-	acquired, inFlight := t.noncer.Stats()
+	// Txs are waiting while the sender is sending them (including their retries).
+	_, inFlight := t.noncer.Stats()
 	t.logger.Info(
-		"🧠 system status",
-		"waiting-tx", acquired, "in-flight-tx", inFlight, "pending-requests", t.requests.Len(),
+		"🧠 system status", "waiting-tx", t.sender.Status().InFlight, "in-flight-tx", inFlight,
+		"pending-requests", t.requests.Len(),
 	)
 	return nil, nil //nolint:nilnil // its okay.
 }
@@ -179,6 +209,14 @@ func (t *TxrV2) markState(state types.PreconfirmedState, msgIDs ...string) {
 	}
 }
 
+// releaseNonce releases the nonce of the tx (built by the factory) to be reused, e.g. because
+// sending the tx failed, which would otherwise leave a gap in the nonces of its sender.
+func (t *TxrV2) releaseNonce(tx *coretypes.Transaction, nonce uint64) {
+	if from, err := t.factory.From(tx); err == nil {
+		t.nonceManager.Release(from, nonce)
+	}
+}
+
 // removeStateTracking removes preconfirmed state tracking of the given message IDs, equivalent to
 // marking the state as StateUnknown.
 func (t *TxrV2) removeStateTracking(msgIDs ...string) {
@@ -203,7 +241,7 @@ func (t *TxrV2) resendStaleTxns(ctx context.Context) error {
 
 	for _, txn := range content["pending"] {
 		bumpedTxn := sender.BumpGas(txn)
-		if err = t.sender.SendTransaction(ctx, bumpedTxn); err != nil {
+		if _, err = t.sender.SendTransaction(ctx, bumpedTxn, nil); err != nil {
 			t.logger.Error("failed to resend stale transaction", "err", err)
 			return err
 		}
@@ -22,6 +22,10 @@ type Request struct {
 	// MsgID is the (optional) user-provided string id for this tx request.
 	MsgID string
 
+	// RetryOverrides (optional) override the Sender's retry policy for sending this request's tx,
+	// e.g. to retry a high-priority request harder.
+	RetryOverrides *RetryOverrides
+
 	// initialTime is the time at which this tx was initially requested; filled in automatically.
 	initialTime time.Time
 }
@@ -49,6 +53,18 @@ func NewRequest(
 	}
 }
 
+// RetryOverrides override the Sender's retry policy for sending a tx. The zero value of each
+// field falls back to the policy.
+type RetryOverrides struct {
+	// MaxAttempts is the max number of attempts to send the tx (including the first), which
+	// replaces the policy's max number of retries.
+	MaxAttempts int
+	// MaxGasPrice caps the gas price of the tx's replacements, replacing the configured ceiling.
+	MaxGasPrice *big.Int
+	// Deadline is the time after which the tx is no longer retried.
+	Deadline time.Time
+}
+
 // Validate ensures that the initialTime is set on the tx request.
 func (r *Request) Validate() error {
 	if r.initialTime.Equal(time.Time{}) || (r.initialTime == time.Time{}) {
@@ -102,6 +118,31 @@ func (rs Requests) MsgIDs() []string {
 	return ids
 }
 
+// RetryOverrides returns the retry overrides of the tx batching the requests, or nil if none of
+// the requests has any. Since the requests are sent together, the most permissive of the overrides
+// set on the requests applies to all of them: the most attempts, the highest max gas price, and
+// the latest deadline.
+func (rs Requests) RetryOverrides() *RetryOverrides {
+	var merged *RetryOverrides
+	for _, r := range rs {
+		if r.RetryOverrides == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &RetryOverrides{}
+		}
+		merged.MaxAttempts = max(merged.MaxAttempts, r.RetryOverrides.MaxAttempts)
+		if gp := r.RetryOverrides.MaxGasPrice; gp != nil &&
+			(merged.MaxGasPrice == nil || gp.Cmp(merged.MaxGasPrice) > 0) {
+			merged.MaxGasPrice = gp
+		}
+		if r.RetryOverrides.Deadline.After(merged.Deadline) {
+			merged.Deadline = r.RetryOverrides.Deadline
+		}
+	}
+	return merged
+}
+
 func (rs Requests) Times() []time.Time {
 	times := make([]time.Time, len(rs))
 	for i, r := range rs {
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestRequestsRetryOverrides(t *testing.T) {
+	newRequest := func(overrides *RetryOverrides) *Request {
+		r := NewRequest(common.HexToAddress("0x1"), 0, nil, nil, nil, nil)
+		r.RetryOverrides = overrides
+		return r
+	}
+	deadline := time.Unix(100, 0)
+
+	require.Nil(t, Requests{newRequest(nil), newRequest(nil)}.RetryOverrides())
+
+	// The most permissive override set on the requests applies to the batch.
+	require.Equal(t, &RetryOverrides{
+		MaxAttempts: 5, MaxGasPrice: big.NewInt(3), Deadline: deadline,
+	}, Requests{
+		newRequest(&RetryOverrides{MaxAttempts: 5, MaxGasPrice: big.NewInt(2)}),
+		newRequest(nil),
+		newRequest(&RetryOverrides{
+			MaxAttempts: 2, MaxGasPrice: big.NewInt(3), Deadline: deadline,
+		}),
+		newRequest(&RetryOverrides{Deadline: deadline.Add(-time.Second)}),
+	}.RetryOverrides())
+}
@@ -0,0 +1,26 @@
+package log
+
+import "context"
+
+// loggerKey is the context key of the logger.
+type loggerKey struct{}
+
+// NewContext returns a copy of the context that carries the logger, which is returned by
+// FromContext.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// With returns a copy of the context whose logger has the additional fields, so that they are
+// included in every log made with FromContext.
+func With(ctx context.Context, keyVals ...any) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(keyVals...))
+}
+
+// FromContext returns the logger carried by the context, or a no-op logger if there is none.
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		return logger
+	}
+	return Noop()
+}
@@ -0,0 +1,71 @@
+package log
+
+// Level is the level of a log message, ordered by increasing severity.
+type Level int8
+
+// Levels of log messages, in order of increasing severity.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the name of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+var _ Logger = (*levelFilter)(nil)
+
+// levelFilter is a Logger that drops messages below its min level.
+type levelFilter struct {
+	Logger
+	min Level
+}
+
+// LevelFilter returns a logger that forwards messages at or above the min level to the given
+// logger and drops the rest, e.g. to suppress debug logs in production.
+func LevelFilter(l Logger, min Level) Logger {
+	return &levelFilter{Logger: l, min: min}
+}
+
+func (lf *levelFilter) Debug(msg string, keyVals ...any) {
+	if lf.min <= LevelDebug {
+		lf.Logger.Debug(msg, keyVals...)
+	}
+}
+
+func (lf *levelFilter) Info(msg string, keyVals ...any) {
+	if lf.min <= LevelInfo {
+		lf.Logger.Info(msg, keyVals...)
+	}
+}
+
+func (lf *levelFilter) Warn(msg string, keyVals ...any) {
+	if lf.min <= LevelWarn {
+		lf.Logger.Warn(msg, keyVals...)
+	}
+}
+
+func (lf *levelFilter) Error(msg string, keyVals ...any) {
+	if lf.min <= LevelError {
+		lf.Logger.Error(msg, keyVals...)
+	}
+}
+
+// With returns a level filter of the logger with the additional context, at the same min level.
+func (lf *levelFilter) With(keyVals ...any) Logger {
+	return LevelFilter(lf.Logger.With(keyVals...), lf.min)
+}
@@ -2,6 +2,7 @@ package log_test
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"github.com/berachain/offchain-sdk/log"
@@ -39,3 +40,110 @@ func contains(s, substr string) bool {
 	y := []byte(substr)
 	return bytes.Contains(x, y)
 }
+
+func TestNoop(t *testing.T) {
+	logger := log.Noop()
+
+	// Logging and adding context are safe, and do nothing.
+	logger.Info("Info message", "key", "value")
+	logger.Warn("Warn message")
+	logger.Error("Error message", "odd")
+	logger.Debug("Debug message")
+	logger.With("key", "value").Info("Info message")
+	if logger.Impl() == nil {
+		t.Error("Expected the no-op logger to have an implementation")
+	}
+}
+
+func TestMultiLogger(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	logger := log.MultiLogger(
+		log.NewLogger(&buf1, "runner-1"), log.NewJSONLogger(&buf2, "runner-2"), log.Noop(),
+	)
+
+	// Each call is forwarded to all of the loggers, with the added context.
+	logger.Info("Info message")
+	logger.Warn("Warn message")
+	logger.With("request", "abc").Error("Error message")
+	for _, output := range []string{buf1.String(), buf2.String()} {
+		for _, expected := range []string{"Info message", "Warn message", "Error message", "abc"} {
+			if !contains(output, expected) {
+				t.Errorf("Expected log output to contain '%s', got: %s", expected, output)
+			}
+		}
+	}
+
+	loggers, ok := logger.Impl().([]log.Logger)
+	if !ok || len(loggers) != 3 {
+		t.Errorf("Expected 3 underlying loggers, got: %v", logger.Impl())
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.LevelFilter(log.NewLogger(&buf, "test-runner"), log.LevelWarn)
+
+	// Messages below the min level are dropped, including with added context.
+	logger.Debug("Debug message")
+	logger.Info("Info message")
+	logger.Warn("Warn message")
+	logger.With("key", "value").Error("Error message")
+	logger.With("key", "value").Info("Other info message")
+
+	output := buf.String()
+	for _, expected := range []string{"Warn message", "Error message"} {
+		if !contains(output, expected) {
+			t.Errorf("Expected log output to contain '%s', got: %s", expected, output)
+		}
+	}
+	for _, unexpected := range []string{"Debug message", "Info message", "Other info message"} {
+		if contains(output, unexpected) {
+			t.Errorf("Expected log output not to contain '%s', got: %s", unexpected, output)
+		}
+	}
+}
+
+func TestLevelOrdering(t *testing.T) {
+	levels := []log.Level{log.LevelDebug, log.LevelInfo, log.LevelWarn, log.LevelError}
+	names := []string{"debug", "info", "warn", "error"}
+	for i, level := range levels {
+		if level.String() != names[i] {
+			t.Errorf("Expected level %d to be named '%s', got: %s", i, names[i], level)
+		}
+		if i > 0 && levels[i-1] >= level {
+			t.Errorf("Expected level %s to be more severe than %s", level, levels[i-1])
+		}
+	}
+}
+
+func TestContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	// A context without a logger logs nothing.
+	log.FromContext(context.Background()).Info("Dropped message")
+	ctx := log.With(context.Background(), "dropped", "field")
+
+	// The fields added to the context are included in every log made with its logger.
+	ctx = log.NewContext(ctx, log.NewLogger(&buf, "test-runner"))
+	ctx = log.With(ctx, "msgID", "msg-1")
+	ctx = log.With(ctx, "hash", "0xabc")
+	logger := log.FromContext(ctx)
+	logger.Info("Info message")
+	logger.Warn("Warn message", "attempt", 2)
+	logger.Error("Error message")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 log lines, got: %s", buf.String())
+	}
+	for _, line := range lines {
+		for _, expected := range []string{"msg-1", "0xabc"} {
+			if !contains(string(line), expected) {
+				t.Errorf("Expected log line to contain '%s', got: %s", expected, line)
+			}
+		}
+		if contains(string(line), "Dropped") || contains(string(line), "dropped") {
+			t.Errorf("Expected log line not to contain the dropped fields, got: %s", line)
+		}
+	}
+}
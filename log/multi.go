@@ -0,0 +1,50 @@
+package log
+
+var _ Logger = multiLogger(nil)
+
+// multiLogger is a Logger that forwards each call to all of its loggers, in order.
+type multiLogger []Logger
+
+// MultiLogger returns a logger that forwards each call to all of the given loggers, e.g. to send
+// logs to multiple sinks.
+func MultiLogger(loggers ...Logger) Logger {
+	return multiLogger(append([]Logger(nil), loggers...))
+}
+
+func (ml multiLogger) Info(msg string, keyVals ...any) {
+	for _, l := range ml {
+		l.Info(msg, keyVals...)
+	}
+}
+
+func (ml multiLogger) Warn(msg string, keyVals ...any) {
+	for _, l := range ml {
+		l.Warn(msg, keyVals...)
+	}
+}
+
+func (ml multiLogger) Error(msg string, keyVals ...any) {
+	for _, l := range ml {
+		l.Error(msg, keyVals...)
+	}
+}
+
+func (ml multiLogger) Debug(msg string, keyVals ...any) {
+	for _, l := range ml {
+		l.Debug(msg, keyVals...)
+	}
+}
+
+// With returns a multi logger of each of the loggers with the additional context.
+func (ml multiLogger) With(keyVals ...any) Logger {
+	loggers := make(multiLogger, len(ml))
+	for i, l := range ml {
+		loggers[i] = l.With(keyVals...)
+	}
+	return loggers
+}
+
+// Impl returns the underlying loggers, as a []Logger.
+func (ml multiLogger) Impl() any {
+	return []Logger(ml)
+}
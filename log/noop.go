@@ -0,0 +1,22 @@
+package log
+
+var _ Logger = noopLogger{}
+
+// noopLogger is a Logger that discards everything.
+type noopLogger struct{}
+
+// Noop returns a logger that discards everything, e.g. for tests.
+func Noop() Logger {
+	return noopLogger{}
+}
+
+func (noopLogger) Info(string, ...any)  {}
+func (noopLogger) Warn(string, ...any)  {}
+func (noopLogger) Error(string, ...any) {}
+func (noopLogger) Debug(string, ...any) {}
+
+// With returns the no-op logger, since there is nothing to add context to.
+func (l noopLogger) With(...any) Logger { return l }
+
+// Impl returns the no-op logger itself, as there is no underlying logger.
+func (l noopLogger) Impl() any { return l }
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// TokenVerifier verifies the token of a request, returning the context to serve the request with
+// (e.g. carrying the caller's identity) or an error if the token is rejected. A nil context means
+// the request context is used as is.
+type TokenVerifier func(ctx context.Context, token string) (context.Context, error)
+
+// AuthMiddleware returns a middleware that authenticates requests with the token in their
+// Authorization header, which may be prefixed with the "Bearer" scheme. Requests without a token
+// or with a token rejected by verify are responded to with 401 Unauthorized. Since verify is
+// pluggable, any kind of token (e.g. JWT, HMAC or static API keys) can be used.
+func AuthMiddleware(verify TokenVerifier) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := tokenFromRequest(r)
+			if token == "" {
+				unauthorized(w)
+				return
+			}
+
+			ctx, err := verify(r.Context(), token)
+			if err != nil {
+				unauthorized(w)
+				return
+			}
+			if ctx != nil {
+				r = r.WithContext(ctx)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tokenFromRequest returns the token in the request's Authorization header, stripped of the
+// "Bearer" scheme if present.
+func tokenFromRequest(r *http.Request) string {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	if strings.EqualFold(auth, "Bearer") {
+		return ""
+	}
+	return auth
+}
+
+// unauthorized responds with 401 Unauthorized.
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
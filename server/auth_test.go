@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type callerKey struct{}
+
+func TestAuthMiddleware(t *testing.T) {
+	verify := func(ctx context.Context, token string) (context.Context, error) {
+		if token != "secret" {
+			return nil, errors.New("invalid token")
+		}
+		return context.WithValue(ctx, callerKey{}, "alice"), nil
+	}
+	h := AuthMiddleware(verify)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, _ := r.Context().Value(callerKey{}).(string)
+		_, _ = w.Write([]byte(caller))
+	}))
+	serve := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		for _, auth := range []string{"Bearer secret", "bearer secret", "secret"} {
+			rec := serve(auth)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, "alice", rec.Body.String())
+		}
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		for _, auth := range []string{"", "Bearer "} {
+			rec := serve(auth)
+			require.Equal(t, http.StatusUnauthorized, rec.Code)
+			require.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+		}
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		rec := serve("Bearer wrong")
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		require.NotContains(t, rec.Body.String(), "alice")
+	})
+}
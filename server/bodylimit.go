@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// DefaultMaxBodyBytes is a sane limit on request bodies (see HTTP.MaxBodyBytes): 10 MiB
+// comfortably fits typical JSON payloads.
+const DefaultMaxBodyBytes = 10 << 20
+
+// MaxBodyBytesMiddleware returns a middleware that limits request bodies to maxBytes, so that
+// handlers can't be forced to buffer arbitrarily large bodies. Requests whose Content-Length
+// exceeds the limit are responded to with 413 Request Entity Too Large. Otherwise (e.g. for
+// chunked bodies), reading past the limit fails with a *http.MaxBytesError and, unless the handler
+// has already responded, the request is responded to with 413 as well, discarding the handler's
+// response. If maxBytes is 0 (or negative), bodies are unlimited.
+func MaxBodyBytesMiddleware(maxBytes int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		if maxBytes <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				tooLarge(w)
+				return
+			}
+			lw := &bodyLimitWriter{ResponseWriter: w}
+			r.Body = &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, maxBytes), w: lw}
+			next.ServeHTTP(lw, r)
+		})
+	}
+}
+
+// tooLarge responds with 413 Request Entity Too Large.
+func tooLarge(w http.ResponseWriter) {
+	http.Error(
+		w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge,
+	)
+}
+
+// limitedBody is a request body limited by http.MaxBytesReader, which rejects the request once
+// it is read past the limit.
+type limitedBody struct {
+	io.ReadCloser
+	w *bodyLimitWriter
+}
+
+// Read reads the body, rejecting the request if it is read past the limit.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		b.w.reject()
+	}
+	return n, err
+}
+
+// bodyLimitWriter is a http.ResponseWriter that discards the handler's response once the request
+// has been rejected for its body being too large.
+type bodyLimitWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	rejected    bool
+}
+
+// reject responds with 413 Request Entity Too Large, unless a response was already written.
+func (lw *bodyLimitWriter) reject() {
+	if lw.wroteHeader {
+		return
+	}
+	lw.wroteHeader, lw.rejected = true, true
+	tooLarge(lw.ResponseWriter)
+}
+
+// WriteHeader sends the headers, unless the request was rejected.
+func (lw *bodyLimitWriter) WriteHeader(statusCode int) {
+	if lw.rejected {
+		return
+	}
+	lw.wroteHeader = true
+	lw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the data, unless the request was rejected.
+func (lw *bodyLimitWriter) Write(b []byte) (int, error) {
+	if lw.rejected {
+		return len(b), nil
+	}
+	lw.wroteHeader = true
+	return lw.ResponseWriter.Write(b)
+}
+
+// Flush flushes the data written so far to the client, for streaming responses.
+func (lw *bodyLimitWriter) Flush() {
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		lw.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, e.g. for WebSockets.
+func (lw *bodyLimitWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := lw.ResponseWriter.(http.Hijacker); ok {
+		lw.wroteHeader = true
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by http.ResponseController.
+func (lw *bodyLimitWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxBodyBytes(t *testing.T) {
+	// The handler responds with 400 if it fails to read the body, like a typical JSON handler.
+	echo := &Handler{Path: "/echo", Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write(body)
+		},
+	)}
+	svr := newTestServer(&Config{HTTP: HTTP{MaxBodyBytes: 8}}, echo)
+	url := "http://" + startTestServer(t, svr) + "/echo"
+
+	//nolint:noctx // test request.
+	resp, err := http.Post(url, "text/plain", strings.NewReader("small"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// An oversized body is rejected by its Content-Length.
+	//nolint:noctx // test request.
+	resp, err = http.Post(url, "text/plain", bytes.NewReader(make([]byte, 9)))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// An oversized body without a Content-Length (i.e. chunked) is rejected once read past the
+	// limit, discarding the handler's response.
+	//nolint:noctx // test request.
+	resp, err = http.Post(url, "text/plain", io.MultiReader(strings.NewReader("0123456789")))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	require.Equal(t, http.StatusText(http.StatusRequestEntityTooLarge)+"\n", string(body))
+}
+
+func TestMaxBodyBytesUnlimited(t *testing.T) {
+	echo := &Handler{Path: "/echo", Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(w, r.Body)
+		},
+	)}
+	svr := newTestServer(&Config{}, echo)
+	url := "http://" + startTestServer(t, svr) + "/echo"
+
+	// Without a limit, bodies larger than DefaultMaxBodyBytes are accepted.
+	//nolint:noctx // test request.
+	resp, err := http.Post(url, "text/plain", bytes.NewReader(make([]byte, DefaultMaxBodyBytes+1)))
+	require.NoError(t, err)
+	n, err := io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.EqualValues(t, DefaultMaxBodyBytes+1, n)
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const (
+	// ForwardedForHeader is the header that carries the chain of client and proxy IPs that a
+	// request was forwarded through.
+	ForwardedForHeader = "X-Forwarded-For"
+	// RealIPHeader is the header that carries the client IP of a request forwarded by a proxy.
+	RealIPHeader = "X-Real-IP"
+)
+
+// clientIPKey is the context key of the client IP.
+type clientIPKey struct{}
+
+// ClientIPMiddleware returns a middleware that resolves the IP of the client that made each
+// request, which is the remote address unless the request was forwarded by a trusted proxy. The
+// trusted proxies are given as CIDRs (e.g. "10.0.0.0/8") or single IPs.
+//
+// For requests from a trusted proxy, the X-Forwarded-For header is read from right to left,
+// skipping trusted proxies, and the first IP that isn't a trusted proxy is the client IP; if the
+// header is not set, the X-Real-IP header is the client IP. The headers of requests from other
+// addresses are ignored, since clients can set them to spoof their IP.
+//
+// The client IP is stored in the request context (see ClientIPFromContext), and is used by the
+// rate limiting middleware if it runs after this middleware. Returns an error if a trusted proxy
+// is not a valid CIDR or IP.
+func ClientIPMiddleware(trustedProxies []string) (Middleware, error) {
+	trusted := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		trusted = append(trusted, prefix)
+	}
+	isTrusted := func(ip netip.Addr) bool {
+		for _, prefix := range trusted {
+			if prefix.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientIP := resolveClientIP(r, isTrusted)
+			next.ServeHTTP(w, r.WithContext(ContextWithClientIP(r.Context(), clientIP)))
+		})
+	}, nil
+}
+
+// resolveClientIP returns the IP of the client that made the request, trusting the forwarding
+// headers set by trusted proxies only.
+func resolveClientIP(r *http.Request, isTrusted func(netip.Addr) bool) string {
+	remoteIP := remoteIP(r)
+	ip, err := netip.ParseAddr(remoteIP)
+	if err != nil || !isTrusted(ip.Unmap()) {
+		return remoteIP
+	}
+
+	if forwardedFor := r.Header.Values(ForwardedForHeader); len(forwardedFor) > 0 {
+		// The header may be repeated, with each proxy appending to the last one.
+		hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, hopErr := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if hopErr != nil {
+				// Anything further left can't be trusted.
+				break
+			}
+			if ip = hop.Unmap(); !isTrusted(ip) {
+				break
+			}
+		}
+		// If all the hops are trusted proxies, the leftmost one is the client.
+		return ip.String()
+	}
+
+	if ip, err = netip.ParseAddr(strings.TrimSpace(r.Header.Get(RealIPHeader))); err == nil {
+		return ip.Unmap().String()
+	}
+	return remoteIP
+}
+
+// remoteIP returns the IP of the remote address of the request.
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// parsePrefix parses a CIDR, or a single IP as the CIDR containing only it.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		ip, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		ip = ip.Unmap()
+		return netip.PrefixFrom(ip, ip.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return prefix.Masked(), nil
+}
+
+// ContextWithClientIP returns a copy of the context that carries the client IP.
+func ContextWithClientIP(ctx context.Context, clientIP string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, clientIP)
+}
+
+// ClientIPFromContext returns the client IP carried by the context, or an empty string if there
+// is none.
+func ClientIPFromContext(ctx context.Context) string {
+	clientIP, _ := ctx.Value(clientIPKey{}).(string)
+	return clientIP
+}
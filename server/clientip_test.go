@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPMiddleware(t *testing.T) {
+	mw, err := ClientIPMiddleware([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+	var seen string
+	h := mw(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen = ClientIPFromContext(r.Context())
+	}))
+	clientIP := func(remoteAddr string, headers map[string][]string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		for name, values := range headers {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		return seen
+	}
+
+	// Direct requests are from their remote address.
+	require.Equal(t, "1.2.3.4", clientIP("1.2.3.4:1000", nil))
+	require.Equal(t, "::1", clientIP("[::1]:1000", nil))
+
+	// Requests forwarded by trusted proxies are from the first untrusted hop from the right.
+	require.Equal(t, "1.2.3.4", clientIP("10.0.0.1:1000", map[string][]string{
+		ForwardedForHeader: {"1.2.3.4"},
+	}))
+	require.Equal(t, "1.2.3.4", clientIP("10.0.0.1:1000", map[string][]string{
+		ForwardedForHeader: {"5.6.7.8, 1.2.3.4, 192.168.1.1", "10.1.1.1"},
+	}))
+	require.Equal(t, "10.2.2.2", clientIP("10.0.0.1:1000", map[string][]string{
+		ForwardedForHeader: {"10.2.2.2, 10.1.1.1"},
+	}))
+	require.Equal(t, "1.2.3.4", clientIP("192.168.1.1:1000", map[string][]string{
+		RealIPHeader: {"1.2.3.4"},
+	}))
+
+	// Spoofed headers of untrusted clients are ignored.
+	require.Equal(t, "1.2.3.4", clientIP("1.2.3.4:1000", map[string][]string{
+		ForwardedForHeader: {"5.6.7.8"},
+		RealIPHeader:       {"5.6.7.8"},
+	}))
+	require.Equal(t, "192.168.1.2", clientIP("192.168.1.2:1000", map[string][]string{
+		ForwardedForHeader: {"5.6.7.8"},
+	}))
+
+	// Hops left of an invalid hop are ignored, since its proxy can't be trusted.
+	require.Equal(t, "10.0.0.1", clientIP("10.0.0.1:1000", map[string][]string{
+		ForwardedForHeader: {"5.6.7.8, garbage"},
+	}))
+
+	_, err = ClientIPMiddleware([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+	_, err = ClientIPMiddleware([]string{"proxy"})
+	require.Error(t, err)
+}
+
+func TestRateLimitMiddlewareByClientIP(t *testing.T) {
+	clientIP, err := ClientIPMiddleware([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	h := clientIP(RateLimitMiddleware(1, 1)(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+	))
+	serve := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1000"
+		req.Header.Set(ForwardedForHeader, forwardedFor)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Clients behind the same proxy are limited separately.
+	require.Equal(t, http.StatusOK, serve("1.2.3.4"))
+	require.Equal(t, http.StatusTooManyRequests, serve("1.2.3.4"))
+	require.Equal(t, http.StatusOK, serve("5.6.7.8"))
+}
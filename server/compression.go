@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Supported content encodings, in order of preference.
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// compressedContentTypes are the prefixes of content types that are already compressed.
+var compressedContentTypes = []string{
+	"image/", "video/", "audio/", "font/woff", "application/zip", "application/gzip",
+	"application/x-gzip", "application/x-7z-compressed", "application/x-rar-compressed",
+}
+
+// CompressionMiddleware returns a middleware that compresses responses with gzip or deflate (at
+// the given compression level, e.g. gzip.DefaultCompression), as negotiated by the request's
+// Accept-Encoding header. Responses whose content is already compressed or encoded are not
+// compressed. An invalid level falls back to the default compression level.
+func CompressionMiddleware(level int) Middleware {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, level: level}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding returns the supported encoding most preferred by the Accept-Encoding header,
+// or an empty string if none is acceptable.
+func negotiateEncoding(acceptEncoding string) string {
+	accepted := make(map[string]bool) // coding -> whether it's acceptable (q > 0)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if key, value, _ := strings.Cut(strings.TrimSpace(param), "="); key == "q" {
+				q, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(coding))] = q > 0
+	}
+
+	for _, encoding := range []string{encodingGzip, encodingDeflate} {
+		if ok, listed := accepted[encoding]; ok || (!listed && accepted["*"]) {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressWriter is a http.ResponseWriter that compresses the response, if appropriate. Whether
+// to compress is decided when the response headers are sent, which is deferred until the first
+// write so that the content type can be detected.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	level    int
+
+	code        int            // status code set by the handler, 0 if not set yet
+	wroteHeader bool           // whether the headers were sent
+	writer      io.WriteCloser // nil if not compressing
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	// Informational responses are followed by the actual response.
+	if code >= http.StatusContinue && code < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if cw.code == 0 {
+		cw.code = code
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	cw.writeHeader(b)
+	if cw.writer != nil {
+		return cw.writer.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush flushes the compressed data written so far to the client, for streaming responses.
+func (cw *compressWriter) Flush() {
+	cw.writeHeader(nil)
+	if f, ok := cw.writer.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, e.g. for WebSockets.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// writeHeader decides whether to compress the response, given its first bytes (if any), and
+// sends the headers, if not done yet.
+func (cw *compressWriter) writeHeader(b []byte) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	if cw.code == 0 {
+		cw.code = http.StatusOK
+	}
+
+	cw.decide(b)
+	cw.ResponseWriter.WriteHeader(cw.code)
+}
+
+// decide decides whether to compress the response, given its first bytes (if any), and sets the
+// headers accordingly.
+func (cw *compressWriter) decide(b []byte) {
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" || cw.code == http.StatusNoContent ||
+		cw.code == http.StatusNotModified {
+		return
+	}
+
+	// Detect the content type from the uncompressed bytes, as net/http would otherwise detect it
+	// from the compressed ones.
+	contentType := h.Get("Content-Type")
+	if contentType == "" && len(b) > 0 {
+		contentType = http.DetectContentType(b)
+		h.Set("Content-Type", contentType)
+	}
+	for _, compressed := range compressedContentTypes {
+		if strings.HasPrefix(contentType, compressed) {
+			return
+		}
+	}
+
+	h.Set("Content-Encoding", cw.encoding)
+	h.Del("Content-Length")
+	if cw.encoding == encodingGzip {
+		cw.writer, _ = gzip.NewWriterLevel(cw.ResponseWriter, cw.level)
+	} else {
+		cw.writer, _ = flate.NewWriter(cw.ResponseWriter, cw.level)
+	}
+}
+
+// close sends the headers if the handler didn't write a body, and flushes any buffered compressed
+// data to the client.
+func (cw *compressWriter) close() {
+	if !cw.wroteHeader && cw.code != 0 {
+		cw.wroteHeader = true
+		cw.ResponseWriter.WriteHeader(cw.code)
+		return
+	}
+	if cw.writer != nil {
+		_ = cw.writer.Close()
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"hello":"world"}`, 100)
+	handler := func(contentType string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, body)
+		})
+	}
+
+	for _, tc := range []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		wantEncoding   string
+		wantType       string
+	}{
+		{name: "gzip", acceptEncoding: "deflate, gzip", wantEncoding: "gzip"},
+		{name: "deflate", acceptEncoding: "gzip;q=0, deflate", wantEncoding: "deflate"},
+		{name: "wildcard", acceptEncoding: "*", wantEncoding: "gzip"},
+		{name: "not accepted", acceptEncoding: "br"},
+		{name: "no accept encoding"},
+		{name: "already compressed", acceptEncoding: "gzip", contentType: "image/png"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			CompressionMiddleware(gzip.BestSpeed)(handler(tc.contentType)).ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusCreated, rec.Code)
+			require.Equal(t, tc.wantEncoding, rec.Header().Get("Content-Encoding"))
+			require.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+			var r io.Reader = rec.Body
+			switch tc.wantEncoding {
+			case "gzip":
+				gr, err := gzip.NewReader(rec.Body)
+				require.NoError(t, err)
+				r = gr
+			case "deflate":
+				r = flate.NewReader(rec.Body)
+			}
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, body, string(got))
+
+			// The content type is detected from the uncompressed body.
+			if tc.wantEncoding != "" {
+				require.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestCompressionMiddlewareStreaming(t *testing.T) {
+	var (
+		flushed = make(chan struct{})
+		resume  = make(chan struct{})
+	)
+	svr := newTestServer(&Config{}, &Handler{Path: "/stream", Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, "first")
+			w.(http.Flusher).Flush()
+			close(flushed)
+			<-resume
+			_, _ = io.WriteString(w, " second")
+		},
+	)})
+	svr.RegisterMiddleware(CompressionMiddleware(gzip.DefaultCompression))
+	url := "http://" + startTestServer(t, svr)
+
+	// The transport transparently decompresses the gzipped response.
+	resp, err := http.Get(url + "/stream") //nolint:noctx // test request.
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.True(t, resp.Uncompressed)
+
+	// The flushed data is readable before the handler finishes.
+	<-flushed
+	first := make([]byte, len("first"))
+	_, err = io.ReadFull(resp.Body, first)
+	require.NoError(t, err)
+	require.Equal(t, "first", string(first))
+
+	close(resume)
+	rest, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, " second", string(rest))
+}
+
+func TestCompressionMiddlewareNoBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	CompressionMiddleware(gzip.DefaultCompression)(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) },
+	)).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.Zero(t, rec.Body.Len())
+}
@@ -1,5 +1,11 @@
 package server
 
+import (
+	"crypto/tls"
+	"strings"
+	"time"
+)
+
 // Config represents the config object for the server.
 type Config struct {
 	HTTP HTTP
@@ -9,9 +15,65 @@ type Config struct {
 type HTTP struct {
 	Host string // optional, empty corresponds to "0.0.0.0"
 	Port uint64
+
+	// optional, path of a Unix socket to serve on instead of the host and port
+	UnixSocket string
+
+	// optional, prefix of the paths of all handlers (e.g. "/api"), which is stripped before the
+	// handlers are called
+	BasePath string
+
+	// optional, timeouts and limits applied to the http.Server, 0 corresponds to none (except
+	// for ReadHeaderTimeout, 0 corresponds to 10s)
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int // 0 corresponds to http.DefaultMaxHeaderBytes
+
+	// optional, max size of request bodies (e.g. DefaultMaxBodyBytes), 0 (or a negative value)
+	// corresponds to unlimited
+	MaxBodyBytes int64
+
+	// optional, time to wait for in-flight requests to finish on stop before closing all
+	// connections, 0 corresponds to 10s
+	ShutdownTimeout time.Duration
+
+	// optional, allows registering the pprof endpoints (see Server.RegisterPprof), which are
+	// disabled by default since they expose internals of the service
+	EnablePprof bool
+
+	// optional, serves HTTPS if enabled
+	TLS TLS
 }
 
-// Enabled returns true if the http server is enabled (i.e. the Port is non-zero).
+// Enabled returns true if the http server is enabled (i.e. the Port is non-zero or the
+// UnixSocket is set).
 func (h HTTP) Enabled() bool {
-	return h.Port > 0
+	return h.Port > 0 || h.UnixSocket != ""
+}
+
+// basePath returns the configured base path, with a leading slash and without a trailing slash
+// (i.e. empty if there is none).
+func (h HTTP) basePath() string {
+	basePath := strings.TrimSuffix(h.BasePath, "/")
+	if basePath != "" && !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+	return basePath
+}
+
+// TLS represents the TLS config object for serving HTTPS.
+type TLS struct {
+	// optional, files of the PEM encoded certificate and key, which are reloaded when modified
+	CertFile string
+	KeyFile  string
+
+	// optional, injected base TLS config, e.g. with its own certificates or GetCertificate
+	Config *tls.Config `mapstructure:"-"`
+}
+
+// Enabled returns true if TLS is enabled (i.e. cert and key files or a TLS config are set).
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != "" || t.Config != nil
 }
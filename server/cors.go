@@ -0,0 +1,103 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSOptions configures the CORS middleware.
+type CORSOptions struct {
+	// Origins allowed to make cross-origin requests. An origin may contain a single "*" wildcard,
+	// e.g. "https://*.example.com", and "*" allows all origins.
+	AllowedOrigins []string
+	// Methods allowed for cross-origin requests; if empty, defaults to GET, HEAD and POST.
+	AllowedMethods []string
+	// Headers allowed in cross-origin requests; if empty, the headers requested by a preflight
+	// request are allowed.
+	AllowedHeaders []string
+	// Whether cross-origin requests may include credentials (e.g. cookies).
+	AllowCredentials bool
+	// How long (in seconds) browsers may cache preflight results; if 0, the header is not set.
+	MaxAge int
+}
+
+// CORSMiddleware returns a middleware that handles CORS according to the options. Preflight
+// requests are answered with 204 No Content and are not passed on to the handler.
+func CORSMiddleware(opts CORSOptions) Middleware {
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions &&
+				r.Header.Get("Access-Control-Request-Method") != ""
+			h := w.Header()
+			h.Add("Vary", "Origin")
+
+			if origin != "" && opts.allowsOrigin(origin) {
+				if opts.allowsAllOrigins() && !opts.AllowCredentials {
+					h.Set("Access-Control-Allow-Origin", "*")
+				} else {
+					h.Set("Access-Control-Allow-Origin", origin)
+				}
+				if opts.AllowCredentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+
+				if preflight {
+					h.Set("Access-Control-Allow-Methods", allowMethods)
+					if allowHeaders != "" {
+						h.Set("Access-Control-Allow-Headers", allowHeaders)
+					} else if requested := r.Header.Get(
+						"Access-Control-Request-Headers",
+					); requested != "" {
+						h.Set("Access-Control-Allow-Headers", requested)
+					}
+					if opts.MaxAge > 0 {
+						h.Set("Access-Control-Max-Age", strconv.Itoa(opts.MaxAge))
+					}
+				}
+			}
+
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allowsOrigin returns true if the origin matches any of the allowed origins.
+func (o CORSOptions) allowsOrigin(origin string) bool {
+	for _, allowed := range o.AllowedOrigins {
+		prefix, suffix, wildcard := strings.Cut(allowed, "*")
+		if !wildcard {
+			if strings.EqualFold(origin, allowed) {
+				return true
+			}
+			continue
+		}
+		if len(origin) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsAllOrigins returns true if all origins are allowed.
+func (o CORSOptions) allowsAllOrigins() bool {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	var called bool
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, tc := range []struct {
+		name        string
+		opts        CORSOptions
+		method      string
+		headers     map[string]string
+		wantStatus  int
+		wantCalled  bool
+		wantHeaders map[string]string
+	}{
+		{
+			name:   "preflight",
+			opts:   CORSOptions{AllowedOrigins: []string{"https://*.example.com"}, MaxAge: 600},
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "Content-Type",
+			},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, HEAD, POST",
+				"Access-Control-Allow-Headers": "Content-Type",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:   "preflight from disallowed origin",
+			opts:   CORSOptions{AllowedOrigins: []string{"https://*.example.com"}},
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin": "https://evil.com", "Access-Control-Request-Method": "POST",
+			},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			name:       "actual request",
+			opts:       CORSOptions{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"X-Id"}},
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Methods": "",
+				"Vary":                         "Origin",
+			},
+		},
+		{
+			name: "actual request with credentials",
+			opts: CORSOptions{
+				AllowedOrigins: []string{"*"}, AllowCredentials: true,
+			},
+			method:     http.MethodPost,
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+			},
+		},
+		{
+			name:       "same origin request",
+			opts:       CORSOptions{AllowedOrigins: []string{"https://example.com"}},
+			method:     http.MethodOptions,
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			called = false
+			req := httptest.NewRequest(tc.method, "/", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			CORSMiddleware(tc.opts)(ok).ServeHTTP(rec, req)
+			require.Equal(t, tc.wantStatus, rec.Code)
+			require.Equal(t, tc.wantCalled, called)
+			for k, v := range tc.wantHeaders {
+				require.Equal(t, v, rec.Header().Get(k), k)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/berachain/offchain-sdk/client/eth"
+)
+
+// HealthCheck is a named check of whether a dependency of the service is healthy.
+type HealthCheck struct {
+	Name  string
+	Check func(context.Context) error
+}
+
+// healthResponse is the response body of a health endpoint.
+type healthResponse struct {
+	Status string            `json:"status"`
+	Failed map[string]string `json:"failed,omitempty"` // check name -> error
+}
+
+// RegisterHealthz registers a health endpoint at the given path, e.g. for liveness or readiness
+// probes. The endpoint runs all of the checks concurrently, responding with 200 OK if they all
+// pass and 503 Service Unavailable with the names and errors of the failing checks otherwise.
+func (s *Server) RegisterHealthz(path string, checks ...HealthCheck) error {
+	return s.RegisterHandler(&Handler{Path: path, Handler: healthHandler(checks)})
+}
+
+// healthHandler returns a handler that runs the health checks.
+func healthHandler(checks []HealthCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			resp = healthResponse{Status: "ok", Failed: make(map[string]string)}
+			mu   sync.Mutex
+			wg   sync.WaitGroup
+		)
+		for _, check := range checks {
+			wg.Add(1)
+			go func(check HealthCheck) {
+				defer wg.Done()
+				if err := check.Check(r.Context()); err != nil {
+					mu.Lock()
+					resp.Failed[check.Name] = err.Error()
+					mu.Unlock()
+				}
+			}(check)
+		}
+		wg.Wait()
+
+		status := http.StatusOK
+		if len(resp.Failed) > 0 {
+			resp.Status, status = "unavailable", http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// EthClientHealthCheck returns a health check, named "eth", that pings the eth client's RPC
+// endpoint by fetching the chain ID.
+func EthClientHealthCheck(client eth.Client) HealthCheck {
+	return HealthCheck{
+		Name: "eth",
+		Check: func(ctx context.Context) error {
+			_, err := client.ChainID(ctx)
+			return err
+		},
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/berachain/offchain-sdk/client/eth"
+	"github.com/stretchr/testify/require"
+)
+
+// mockEthClient is an eth.Client that only implements fetching the chain ID.
+type mockEthClient struct {
+	eth.Client
+	err error
+}
+
+func (m *mockEthClient) ChainID(context.Context) (*big.Int, error) {
+	return big.NewInt(1), m.err
+}
+
+func TestRegisterHealthz(t *testing.T) {
+	svr := newTestServer(&Config{})
+	require.NoError(t, svr.RegisterHealthz("/healthz", EthClientHealthCheck(&mockEthClient{})))
+	require.NoError(t, svr.RegisterHealthz("/readyz",
+		EthClientHealthCheck(&mockEthClient{}),
+		HealthCheck{Name: "db", Check: func(context.Context) error {
+			return errors.New("connection refused")
+		}},
+	))
+	url := "http://" + startTestServer(t, svr)
+
+	get := func(path string) (int, healthResponse) {
+		resp, err := http.Get(url + path) //nolint:noctx // test request.
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body healthResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body
+	}
+
+	status, body := get("/healthz")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "ok", body.Status)
+	require.Empty(t, body.Failed)
+
+	status, body = get("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "unavailable", body.Status)
+	require.Equal(t, map[string]string{"db": "connection refused"}, body.Failed)
+}
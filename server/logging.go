@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+)
+
+// LoggingMiddleware returns a middleware that logs each request's method, path, response status
+// code and size, and duration (and its request ID, if any; see RequestIDMiddleware) at the info
+// level, once the request has been served.
+func LoggingMiddleware(logger log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			keyVals := []any{
+				"method", r.Method, "path", r.URL.Path, "status", sw.statusCode(),
+				"size", sw.size, "duration", time.Since(start),
+			}
+			if requestID := RequestIDFromContext(r.Context()); requestID != "" {
+				keyVals = append(keyVals, "request_id", requestID)
+			}
+			logger.Info("HTTP request", keyVals...)
+		})
+	}
+}
+
+// statusWriter is a http.ResponseWriter that captures the status code and size of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+// WriteHeader captures the status code and sends the headers.
+func (sw *statusWriter) WriteHeader(statusCode int) {
+	if sw.status == 0 {
+		sw.status = statusCode
+	}
+	sw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write captures the size of the written data and writes it.
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	n, err := sw.ResponseWriter.Write(b)
+	sw.size += n
+	return n, err
+}
+
+// Flush flushes the data written so far to the client, for streaming responses.
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, e.g. for WebSockets.
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := sw.ResponseWriter.(http.Hijacker); ok {
+		sw.status = http.StatusSwitchingProtocols
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by http.ResponseController.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// statusCode returns the captured status code, which is 200 if the handler wrote nothing.
+func (sw *statusWriter) statusCode() int {
+	if sw.status == 0 {
+		return http.StatusOK
+	}
+	return sw.status
+}
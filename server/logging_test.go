@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/stretchr/testify/require"
+)
+
+// capturingLogger is a log.Logger that captures the key/value pairs of info logs.
+type capturingLogger struct {
+	mu   sync.Mutex
+	logs []map[string]any
+}
+
+func (l *capturingLogger) Info(_ string, keyVals ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fields := make(map[string]any, len(keyVals)/2)
+	for i := 0; i+1 < len(keyVals); i += 2 {
+		key, _ := keyVals[i].(string)
+		fields[key] = keyVals[i+1]
+	}
+	l.logs = append(l.logs, fields)
+}
+
+func (l *capturingLogger) Warn(string, ...any)    {}
+func (l *capturingLogger) Error(string, ...any)   {}
+func (l *capturingLogger) Debug(string, ...any)   {}
+func (l *capturingLogger) With(...any) log.Logger { return l }
+func (l *capturingLogger) Impl() any              { return l }
+
+func TestLoggingMiddleware(t *testing.T) {
+	logger := &capturingLogger{}
+	h := RequestIDMiddleware()(LoggingMiddleware(logger)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte("hello"))
+		},
+	)))
+
+	req := httptest.NewRequest(http.MethodPost, "/hello", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	require.Len(t, logger.logs, 2)
+	fields := logger.logs[0]
+	require.Equal(t, http.MethodPost, fields["method"])
+	require.Equal(t, "/hello", fields["path"])
+	require.Equal(t, http.StatusOK, fields["status"])
+	require.Equal(t, len("hello"), fields["size"])
+	require.IsType(t, time.Duration(0), fields["duration"])
+	require.Equal(t, "req-1", fields["request_id"])
+
+	fields = logger.logs[1]
+	require.Equal(t, http.MethodGet, fields["method"])
+	require.Equal(t, "/missing", fields["path"])
+	require.Equal(t, http.StatusNotFound, fields["status"])
+	require.NotEmpty(t, fields["request_id"])
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPprofPathPrefix is the path prefix of the pprof endpoints used by the pprof tooling.
+const defaultPprofPathPrefix = "/debug/pprof"
+
+const (
+	// defaultCPUProfileDuration is how long the CPU is profiled if the seconds param is not set.
+	defaultCPUProfileDuration = 30 * time.Second
+	// defaultTraceDuration is how long the execution is traced if the seconds param is not set.
+	defaultTraceDuration = time.Second
+)
+
+// ErrPprofDisabled is returned when registering the pprof endpoints while they are disabled.
+var ErrPprofDisabled = errors.New("pprof is disabled, set EnablePprof to enable it")
+
+// RegisterPprof registers the pprof endpoints under the given path prefix (if empty,
+// "/debug/pprof"), for profiling the service. Since profiling exposes internals of the service,
+// the endpoints must be enabled with EnablePprof, and are served behind the middlewares (e.g. for
+// auth) like any other handler. Returns ErrPprofDisabled if EnablePprof is not set.
+//
+// The endpoints are served with runtime/pprof rather than net/http/pprof, since importing the
+// latter registers them on http.DefaultServeMux for every binary, whether enabled or not.
+func (s *Server) RegisterPprof(pathPrefix string) error {
+	if !s.cfg.Load().HTTP.EnablePprof {
+		return ErrPprofDisabled
+	}
+	if pathPrefix = strings.TrimSuffix(pathPrefix, "/"); pathPrefix == "" {
+		pathPrefix = defaultPprofPathPrefix
+	}
+
+	handlers := map[string]http.Handler{
+		"/":        pprofIndex(pathPrefix),
+		"/cmdline": http.HandlerFunc(pprofCmdline),
+		"/profile": http.HandlerFunc(pprofCPUProfile),
+		"/symbol":  http.HandlerFunc(pprofSymbol),
+		"/trace":   http.HandlerFunc(pprofTrace),
+	}
+	for path, handler := range handlers {
+		if err := s.RegisterHandler(&Handler{Path: pathPrefix + path, Handler: handler}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pprofIndex returns the pprof index handler, which lists the named profiles (e.g. goroutine or
+// heap) and serves them under the given path prefix.
+func pprofIndex(pathPrefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name := strings.TrimPrefix(r.URL.Path, pathPrefix+"/"); name != "" {
+			pprofNamed(w, r, name)
+			return
+		}
+
+		profiles := pprof.Profiles()
+		sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name() < profiles[j].Name() })
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "<html><head><title>%s/</title></head><body>\n", pathPrefix)
+		fmt.Fprintln(w, "<table><tr><th>Count</th><th>Profile</th></tr>")
+		for _, p := range profiles {
+			name := html.EscapeString(p.Name())
+			fmt.Fprintf(
+				w, "<tr><td>%d</td><td><a href=\"%s?debug=1\">%s</a></td></tr>\n",
+				p.Count(), name, name,
+			)
+		}
+		fmt.Fprintln(w, "</table>")
+		for _, name := range []string{"cmdline", "profile", "symbol", "trace"} {
+			fmt.Fprintf(w, "<a href=\"%s\">%s</a><br>\n", name, name)
+		}
+		fmt.Fprintln(w, "</body></html>")
+	})
+}
+
+// pprofNamed serves the named profile, in plaintext if the debug param is set and in the binary
+// format expected by the pprof tool otherwise.
+func pprofNamed(w http.ResponseWriter, r *http.Request, name string) {
+	p := pprof.Lookup(name)
+	if p == nil {
+		http.Error(w, "unknown profile: "+name, http.StatusNotFound)
+		return
+	}
+	debug, _ := strconv.Atoi(r.FormValue("debug"))
+	if name == "heap" && r.FormValue("gc") != "" {
+		runtime.GC()
+	}
+	if debug != 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	_ = p.WriteTo(w, debug)
+}
+
+// pprofCmdline serves the command line of the service, with its arguments separated by NUL bytes.
+func pprofCmdline(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, strings.Join(os.Args, "\x00"))
+}
+
+// pprofCPUProfile serves a CPU profile taken for the duration given by the seconds param (30s by
+// default).
+func pprofCPUProfile(w http.ResponseWriter, r *http.Request) {
+	// The profile is buffered, so that a failure to start it can still be responded to.
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		http.Error(
+			w, "could not enable CPU profiling: "+err.Error(), http.StatusInternalServerError,
+		)
+		return
+	}
+	pprofSleep(r, defaultCPUProfileDuration)
+	pprof.StopCPUProfile()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	_, _ = buf.WriteTo(w)
+}
+
+// pprofTrace serves an execution trace taken for the duration given by the seconds param (1s by
+// default).
+func pprofTrace(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		http.Error(w, "could not enable tracing: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	pprofSleep(r, defaultTraceDuration)
+	trace.Stop()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	_, _ = buf.WriteTo(w)
+}
+
+// pprofSleep waits for the duration given by the seconds param (or the given default), or until
+// the request is canceled.
+func pprofSleep(r *http.Request, def time.Duration) {
+	d := def
+	if secs, err := strconv.ParseFloat(r.FormValue("seconds"), 64); err == nil && secs > 0 {
+		d = time.Duration(secs * float64(time.Second))
+	}
+	select {
+	case <-time.After(d):
+	case <-r.Context().Done():
+	}
+}
+
+// pprofSymbol looks up the function names of the program counters given in the request body (or
+// query), separated by "+", for the pprof tool.
+func pprofSymbol(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	var buf bytes.Buffer
+	// The pprof tool only checks whether symbols are available at all.
+	fmt.Fprintln(&buf, "num_symbols: 1")
+
+	var in *bufio.Reader
+	if r.Method == http.MethodPost {
+		in = bufio.NewReader(r.Body)
+	} else {
+		in = bufio.NewReader(strings.NewReader(r.URL.RawQuery))
+	}
+	for {
+		word, err := in.ReadSlice('+')
+		if err == nil {
+			word = word[:len(word)-1] // trim the "+"
+		}
+		if pc, perr := strconv.ParseUint(string(word), 0, 64); perr == nil && pc != 0 {
+			if f := runtime.FuncForPC(uintptr(pc)); f != nil {
+				fmt.Fprintf(&buf, "%#x %s\n", pc, f.Name())
+			}
+		}
+		// io.EOF (or any other error) ends the list of program counters.
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				fmt.Fprintf(&buf, "reading request: %v\n", err)
+			}
+			break
+		}
+	}
+	_, _ = buf.WriteTo(w)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterPprof(t *testing.T) {
+	svr := newTestServer(&Config{})
+	require.ErrorIs(t, svr.RegisterPprof(""), ErrPprofDisabled)
+
+	svr = newTestServer(&Config{HTTP: HTTP{EnablePprof: true}})
+	require.NoError(t, svr.RegisterPprof(""))
+	require.NoError(t, svr.RegisterPprof("/custom/pprof/"))
+	url := "http://" + startTestServer(t, svr)
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(url + path) //nolint:noctx // test request.
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("/debug/pprof/")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "goroutine")
+
+	status, body = get("/custom/pprof/goroutine?debug=1")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "goroutine profile")
+
+	status, _ = get("/debug/pprof/unknown")
+	require.Equal(t, http.StatusNotFound, status)
+
+	status, body = get("/debug/pprof/cmdline")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, strings.Join(os.Args, "\x00"), body)
+
+	status, body = get("/debug/pprof/profile?seconds=0.1")
+	require.Equal(t, http.StatusOK, status)
+	require.NotEmpty(t, body)
+
+	status, body = get("/debug/pprof/symbol")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "num_symbols: 1\n", body)
+}
+
+func TestPprofNotOnDefaultServeMux(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	_, pattern := http.DefaultServeMux.Handler(req)
+	require.Empty(t, pattern)
+}
+
+func TestRegisterPprofMiddlewares(t *testing.T) {
+	svr := newTestServer(&Config{HTTP: HTTP{EnablePprof: true}})
+	require.NoError(t, svr.RegisterPprof(""))
+	svr.RegisterMiddleware(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	})
+	url := "http://" + startTestServer(t, svr)
+
+	resp, err := http.Get(url + "/debug/pprof/") //nolint:noctx // test request.
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// defaultRateLimitMaxClients is the default number of clients whose rate limits are tracked.
+const defaultRateLimitMaxClients = 10000
+
+// RateLimitOption is a functional option for configuring the rate limiting middleware.
+type RateLimitOption func(*rateLimiter)
+
+// RateLimitByHeader rate limits clients by the value of the given request header (e.g. an API
+// key) instead of by IP. Requests without the header are rate limited by IP.
+func RateLimitByHeader(header string) RateLimitOption {
+	return func(rl *rateLimiter) {
+		rl.header = header
+	}
+}
+
+// RateLimitMaxClients bounds the number of clients whose rate limits are tracked, evicting the
+// least recently seen clients (whose rate limits are then reset). Defaults to 10000.
+func RateLimitMaxClients(maxClients int) RateLimitOption {
+	return func(rl *rateLimiter) {
+		rl.maxClients = maxClients
+	}
+}
+
+// RateLimitMiddleware returns a middleware that rate limits each client (by IP, by default) with
+// a token bucket that allows rps requests per second on average, in bursts of up to burst
+// requests. Requests over the limit are rejected with 429 Too Many Requests and a Retry-After
+// header. Clients are identified by the client IP resolved by ClientIPMiddleware if it runs
+// before this middleware (e.g. behind proxies), or by the remote address otherwise.
+func RateLimitMiddleware(rps float64, burst int, opts ...RateLimitOption) Middleware {
+	rl := &rateLimiter{rps: rps, burst: float64(burst), maxClients: defaultRateLimitMaxClients}
+	for _, opt := range opts {
+		opt(rl)
+	}
+	rl.buckets, _ = lru.New[string, *tokenBucket](max(rl.maxClients, 1))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, retryAfter := rl.allow(rl.key(r), time.Now()); !ok {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimiter tracks a token bucket per client.
+type rateLimiter struct {
+	rps        float64
+	burst      float64
+	header     string
+	maxClients int
+
+	mu      sync.Mutex // protects getting or adding buckets
+	buckets *lru.Cache[string, *tokenBucket]
+}
+
+// key returns the key of the client that made the request.
+func (rl *rateLimiter) key(r *http.Request) string {
+	if rl.header != "" {
+		if value := r.Header.Get(rl.header); value != "" {
+			return "header:" + value
+		}
+	}
+
+	ip := ClientIPFromContext(r.Context())
+	if ip == "" {
+		ip = remoteIP(r)
+	}
+	return "ip:" + ip
+}
+
+// allow takes a token from the client's bucket, returning false and the time until a token is
+// available if the bucket is empty.
+func (rl *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	bucket, ok := rl.buckets.Get(key)
+	if !ok {
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets.Add(key, bucket)
+	}
+
+	// Refill the bucket for the time elapsed since it was last used.
+	bucket.tokens = min(rl.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rps)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		if rl.rps <= 0 {
+			return false, time.Duration(math.MaxInt64)
+		}
+		return false, time.Duration((1 - bucket.tokens) / rl.rps * float64(time.Second))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// tokenBucket is the token bucket of a client.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
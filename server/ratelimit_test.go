@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	h := RateLimitMiddleware(1, 2)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A client can burst, then is limited.
+	require.Equal(t, http.StatusOK, serve("10.0.0.1:1000").Code)
+	require.Equal(t, http.StatusOK, serve("10.0.0.1:1001").Code)
+	rec := serve("10.0.0.1:1002")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	// Other clients are limited separately.
+	require.Equal(t, http.StatusOK, serve("10.0.0.2:1000").Code)
+}
+
+func TestRateLimitMiddlewareByHeader(t *testing.T) {
+	h := RateLimitMiddleware(1, 1, RateLimitByHeader("X-API-Key"))(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+	)
+	serve := func(apiKey string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Clients with the same IP but different keys are limited separately.
+	require.Equal(t, http.StatusOK, serve("a"))
+	require.Equal(t, http.StatusTooManyRequests, serve("a"))
+	require.Equal(t, http.StatusOK, serve("b"))
+}
+
+func TestRateLimiter(t *testing.T) {
+	var (
+		now = time.Unix(0, 0)
+		rl  = &rateLimiter{rps: 2, burst: 2}
+	)
+	rl.buckets, _ = lru.New[string, *tokenBucket](1)
+
+	for i := 0; i < 2; i++ {
+		ok, _ := rl.allow("a", now)
+		require.True(t, ok)
+	}
+	ok, retryAfter := rl.allow("a", now)
+	require.False(t, ok)
+	require.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// The bucket refills at the rate.
+	ok, _ = rl.allow("a", now.Add(500*time.Millisecond))
+	require.True(t, ok)
+	ok, _ = rl.allow("a", now.Add(500*time.Millisecond))
+	require.False(t, ok)
+
+	// Tracking another client evicts the least recently seen one, resetting its limit.
+	ok, _ = rl.allow("b", now.Add(500*time.Millisecond))
+	require.True(t, ok)
+	ok, _ = rl.allow("a", now.Add(500*time.Millisecond))
+	require.True(t, ok)
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/berachain/offchain-sdk/log"
+)
+
+// RecoveryMiddleware returns a middleware that recovers from panics in handlers, logging the panic
+// with its stack trace and responding with 500 Internal Server Error. Panics with
+// http.ErrAbortHandler are re-panicked, so that the server aborts the response as intended.
+func RecoveryMiddleware(logger log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(rec)
+				}
+
+				logger.Error(
+					"HTTP handler panicked", "path", r.URL.Path, "panic", rec,
+					"stack", string(debug.Stack()),
+				)
+				http.Error(
+					w, http.StatusText(http.StatusInternalServerError),
+					http.StatusInternalServerError,
+				)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
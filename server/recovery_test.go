@@ -0,0 +1,50 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	svr := newTestServer(&Config{},
+		&Handler{Path: "/panic", Handler: http.HandlerFunc(
+			func(http.ResponseWriter, *http.Request) { panic("boom") },
+		)},
+		&Handler{Path: "/ok", Handler: http.HandlerFunc(
+			func(http.ResponseWriter, *http.Request) {},
+		)},
+	)
+	svr.RegisterMiddleware(RecoveryMiddleware(log.NewBlankLogger(&logs)))
+	url := "http://" + startTestServer(t, svr)
+
+	resp, err := http.Get(url + "/panic") //nolint:noctx // test request.
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.Contains(t, logs.String(), "boom")
+	require.Contains(t, logs.String(), "recovery_test.go")
+
+	// The server is still up.
+	resp, err = http.Get(url + "/ok") //nolint:noctx // test request.
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRecoveryMiddlewareAbortHandler(t *testing.T) {
+	var logs bytes.Buffer
+	h := RecoveryMiddleware(log.NewBlankLogger(&logs))(http.HandlerFunc(
+		func(http.ResponseWriter, *http.Request) { panic(http.ErrAbortHandler) },
+	))
+
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	require.Empty(t, logs.String())
+}
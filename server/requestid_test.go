@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	h := RequestIDMiddleware()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	// An incoming request ID is passed through.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, "abc-123", seen)
+	require.Equal(t, "abc-123", rec.Header().Get(RequestIDHeader))
+
+	// Otherwise, a UUID is generated.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	_, err := uuid.Parse(seen)
+	require.NoError(t, err)
+	require.Equal(t, seen, rec.Header().Get(RequestIDHeader))
+
+	// Each generated ID is unique.
+	first := seen
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NotEqual(t, first, seen)
+
+	require.Empty(t, RequestIDFromContext(req.Context()))
+}
@@ -4,15 +4,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/berachain/offchain-sdk/log"
 )
 
-// 10 seconds is a stable default.
-const defaultReadHeaderTimeout = 10 * time.Second
+const (
+	// 10 seconds is a stable default.
+	defaultReadHeaderTimeout = 10 * time.Second
+	// 10 seconds gives most in-flight requests time to finish.
+	defaultShutdownTimeout = 10 * time.Second
+	// drainLogInterval is how often the remaining connections are logged while stopping.
+	drainLogInterval = time.Second
+)
+
+// ErrDuplicatePath is returned when registering a handler at a path that already has a handler.
+var ErrDuplicatePath = errors.New("a handler is already registered at the path")
 
 // Handler is a handler.
 type Handler struct {
@@ -20,73 +32,324 @@ type Handler struct {
 	Handler http.Handler
 }
 
+// Middleware wraps the server's handler. Middlewares run in the order they're registered: the
+// first middleware is the outermost one, so it sees each request first and each response last.
+// Middlewares wrap all handlers, whether registered before or after them.
 type Middleware func(http.Handler) http.Handler
 
 // Server is a server, that currently only supports HTTP.
 type Server struct {
-	cfg    *Config
+	cfg    atomic.Pointer[Config] // swapped on reload
 	logger log.Logger
 
-	mux    *http.ServeMux
-	srv    *http.Server
-	closer sync.Once
+	mux        atomic.Pointer[http.ServeMux] // rebuilt on each (re)registered handler and reload
+	handlers   map[string]http.Handler       // path -> registered handler
+	handlersMu sync.Mutex                    // protects handlers, middlewares and rebuilding mux
+	handler    atomic.Pointer[http.Handler]  // mux wrapped in the middlewares, set on start
+	srv        *http.Server
+	stopped    bool       // whether Stop was called, so that the server no longer starts
+	srvMu      sync.Mutex // protects srv and stopped
+	closer     sync.Once
+	conns      atomic.Int64 // open connections (excluding hijacked ones)
 
 	middlewares []Middleware
 }
 
 // New creates a new server.
 func New(cfg *Config, logger log.Logger, middlewares ...Middleware) *Server {
-	return &Server{
-		cfg:         cfg,
+	s := &Server{
 		logger:      logger,
-		mux:         http.NewServeMux(),
+		handlers:    make(map[string]http.Handler),
 		middlewares: middlewares,
 	}
+	s.cfg.Store(cfg)
+	s.mux.Store(http.NewServeMux())
+	return s
+}
+
+// RegisterHandler registers a handler, wrapped in the given middlewares which apply only to its
+// route. They run after the server's middlewares, in the order given. Returns ErrDuplicatePath if
+// a handler is already registered at the same path. Handlers may be registered while the server
+// is running.
+func (s *Server) RegisterHandler(h *Handler, mw ...Middleware) error {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+
+	if _, ok := s.handlers[h.Path]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicatePath, h.Path)
+	}
+	handler := h.Handler
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = mw[i](handler)
+	}
+	s.setHandler(&Handler{Path: h.Path, Handler: handler})
+	return nil
+}
+
+// ReplaceHandler registers a handler, replacing the handler already registered at the same path
+// (if any). Handlers may be replaced while the server is running.
+func (s *Server) ReplaceHandler(h *Handler) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+
+	s.setHandler(h)
+}
+
+// setHandler sets the handler at its path and swaps in a mux with the updated handlers, since
+// handlers can't be replaced on a mux. Requires s.handlersMu to be held.
+func (s *Server) setHandler(h *Handler) {
+	s.handlers[h.Path] = h.Handler
+	s.rebuildMux()
+}
+
+// rebuildMux swaps in a mux with the registered handlers. Handlers are served under the
+// configured base path, which is stripped before they're called. Requires s.handlersMu to be
+// held.
+func (s *Server) rebuildMux() {
+	basePath := s.cfg.Load().HTTP.basePath()
+	mux := http.NewServeMux()
+	for path, handler := range s.handlers {
+		if basePath != "" {
+			handler = http.StripPrefix(basePath, handler)
+		}
+		mux.Handle(basePath+path, handler)
+	}
+	s.mux.Store(mux)
 }
 
-// RegisterHandler registers a handler.
-func (s *Server) RegisterHandler(h *Handler) {
-	s.mux.Handle(h.Path, h.Handler)
+// serveHandlers serves the request with the currently registered handlers.
+func (s *Server) serveHandlers(w http.ResponseWriter, r *http.Request) {
+	s.mux.Load().ServeHTTP(w, r)
 }
 
-// RegisterMiddleware registers a middleware.
+// RegisterMiddleware registers a middleware after the already registered ones, so it runs last
+// (innermost, right before the handler). Middlewares registered while the server is running
+// apply once it's reloaded (see Reload).
 func (s *Server) RegisterMiddleware(m Middleware) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+
 	s.middlewares = append(s.middlewares, m)
 }
 
+// PrependMiddleware registers a middleware before the already registered ones (including those
+// passed to New), so it runs first (outermost, e.g. for panic recovery). Middlewares registered
+// while the server is running apply once it's reloaded (see Reload).
+func (s *Server) PrependMiddleware(m Middleware) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+
+	s.middlewares = append([]Middleware{m}, s.middlewares...)
+}
+
 // applyMiddlewares applies the middlewares to the server in reverse order,
-// so that the first middleware is the outermost one.
-func (s *Server) applyMiddlewares() http.Handler {
-	var h http.Handler = s.mux
+// so that the first middleware is the outermost one. The request body limit is applied outside
+// of all middlewares. Requires s.handlersMu to be held.
+func (s *Server) applyMiddlewares() {
+	var h http.Handler = http.HandlerFunc(s.serveHandlers)
 	for i := len(s.middlewares) - 1; i >= 0; i-- {
 		h = s.middlewares[i](h)
 	}
-	return h
+	h = MaxBodyBytesMiddleware(s.cfg.Load().HTTP.MaxBodyBytes)(h)
+	s.handler.Store(&h)
+}
+
+// Reload reconfigures the server with the given config without restarting it, so the listening
+// socket and in-flight requests are kept. The mux is rebuilt (e.g. under a new base path) and the
+// middlewares (including those registered since the server started) and request body limit are
+// reapplied, all swapped in atomically for the next requests. The new ReadTimeout and
+// WriteTimeout apply to the next requests, and the new ShutdownTimeout to the next Stop.
+//
+// Changing the listener (Host, Port or UnixSocket), TLS, ReadHeaderTimeout, IdleTimeout or
+// MaxHeaderBytes requires a full restart, so these settings are ignored until then.
+func (s *Server) Reload(cfg *Config) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+
+	s.cfg.Store(cfg)
+	s.rebuildMux()
+	s.applyMiddlewares()
+}
+
+// serve serves the request with the middlewares and handlers, applying the read and write
+// timeouts of the current config if they were changed by a reload since srv was started.
+func (s *Server) serve(srv *http.Server, w http.ResponseWriter, r *http.Request) {
+	cfg := s.cfg.Load().HTTP
+	if cfg.ReadTimeout != srv.ReadTimeout || cfg.WriteTimeout != srv.WriteTimeout {
+		rc := http.NewResponseController(w)
+		if cfg.ReadTimeout != srv.ReadTimeout {
+			_ = rc.SetReadDeadline(deadline(cfg.ReadTimeout))
+		}
+		if cfg.WriteTimeout != srv.WriteTimeout {
+			_ = rc.SetWriteDeadline(deadline(cfg.WriteTimeout))
+		}
+	}
+	(*s.handler.Load()).ServeHTTP(w, r)
+}
+
+// deadline returns the deadline for the timeout from now, or no deadline if the timeout is 0.
+func deadline(timeout time.Duration) time.Time {
+	if timeout == 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
 }
 
-// Start starts the server. It is blocking so must run in a go-routine.
-func (s *Server) Start(ctx context.Context) {
-	s.srv = &http.Server{
-		Addr:              fmt.Sprintf("%s:%d", s.cfg.HTTP.Host, s.cfg.HTTP.Port),
-		Handler:           s.applyMiddlewares(),
-		ReadHeaderTimeout: defaultReadHeaderTimeout,
+// Start starts the server on the configured Unix socket or host and port, serving HTTPS if TLS is
+// configured. It is blocking so must run in a go-routine. Returns an error if the server fails to
+// start (e.g. its port is already in use) or errors while serving; returns nil once the server
+// is stopped.
+func (s *Server) Start(ctx context.Context) error {
+	l, err := s.Listen()
+	if err != nil {
+		return err
 	}
+	return s.StartWithListener(ctx, l)
+}
+
+// StartWithListener starts the server on the given listener (e.g. on an ephemeral port, whose
+// address can be read from the listener), serving HTTPS if TLS is configured. The configured
+// Unix socket, host and port are ignored. It is blocking so must run in a go-routine. Returns an
+// error if the server fails to start or errors while serving; returns nil once the server is
+// stopped, immediately (closing the listener) if it was stopped before starting.
+func (s *Server) StartWithListener(ctx context.Context, l net.Listener) error {
+	cfg := s.cfg.Load().HTTP
+	tlsConfig, err := cfg.TLS.tlsConfig()
+	if err != nil {
+		_ = l.Close()
+		return fmt.Errorf("HTTP server TLS config error: %w", err)
+	}
+
+	readHeaderTimeout := cfg.ReadHeaderTimeout
+	if readHeaderTimeout == 0 {
+		readHeaderTimeout = defaultReadHeaderTimeout
+	}
+
+	s.handlersMu.Lock()
+	s.applyMiddlewares()
+	s.handlersMu.Unlock()
+
+	srv := &http.Server{
+		Addr:              l.Addr().String(),
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		TLSConfig:         tlsConfig,
+	}
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.serve(srv, w, r)
+	})
+	srv.ConnState = s.trackConn
+	s.srvMu.Lock()
+	if s.stopped {
+		s.srvMu.Unlock()
+		_ = l.Close()
+		s.logger.Info("HTTP server stopped before starting")
+		return nil
+	}
+	s.srv = srv
+	s.srvMu.Unlock()
 
-	if err := s.srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-		s.logger.Error("HTTP server errored", "err", err)
+	if tlsConfig != nil {
+		// The certificates are provided by the TLS config.
+		err = srv.ServeTLS(l, "", "")
 	} else {
-		s.logger.Info("HTTP server closed")
+		err = srv.Serve(l)
 	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("HTTP server errored: %w", err)
+	}
+	s.logger.Info("HTTP server closed")
 
 	<-ctx.Done()
 	s.Stop()
+	return nil
 }
 
-// Stop stops the server.
+// Listen listens on the configured Unix socket if set, or the configured host and port
+// otherwise, for starting the server with StartWithListener. Listening before starting the
+// server lets callers handle bind errors synchronously. A stale socket file (e.g. left by a
+// crashed process) is removed before listening.
+func (s *Server) Listen() (net.Listener, error) {
+	cfg := s.cfg.Load().HTTP
+	socket := cfg.UnixSocket
+	if socket == "" {
+		return net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.Host, cfg.Port))
+	}
+
+	if info, err := os.Stat(socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err = os.Remove(socket); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", socket)
+}
+
+// trackConn counts the open connections as their state changes. Each connection starts new and
+// ends either closed or hijacked (e.g. for WebSockets), after which the server no longer manages
+// it.
+func (s *Server) trackConn(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		s.conns.Add(1)
+	case http.StateClosed, http.StateHijacked:
+		s.conns.Add(-1)
+	case http.StateActive, http.StateIdle:
+	}
+}
+
+// ActiveConns returns the number of open connections to the server, whether serving a request or
+// idle (kept alive). Hijacked connections (e.g. WebSockets) aren't counted.
+func (s *Server) ActiveConns() int {
+	return int(s.conns.Load())
+}
+
+// Stop gracefully stops the server, waiting up to the shutdown timeout for in-flight requests to
+// finish before closing all connections, while logging the number of connections left. If the
+// server hasn't started yet, it won't start. It is safe to call multiple times.
 func (s *Server) Stop() {
+	s.srvMu.Lock()
+	s.stopped = true
+	srv := s.srv
+	s.srvMu.Unlock()
+	if srv == nil {
+		return
+	}
+
 	s.closer.Do(func() {
-		if err := s.srv.Close(); err != nil {
-			s.logger.Error("HTTP server close error", "err", err)
+		timeout := s.cfg.Load().HTTP.ShutdownTimeout
+		if timeout == 0 {
+			timeout = defaultShutdownTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		s.logger.Info("HTTP server draining connections", "active_conns", s.ActiveConns())
+		drained := make(chan struct{})
+		defer close(drained)
+		go s.logDrain(drained)
+
+		if err := srv.Shutdown(ctx); err != nil {
+			s.logger.Error("HTTP server graceful shutdown error, closing", "err", err)
+			if err = srv.Close(); err != nil {
+				s.logger.Error("HTTP server close error", "err", err)
+			}
 		}
 	})
 }
+
+// logDrain logs the number of connections left periodically until drained is closed.
+func (s *Server) logDrain(drained <-chan struct{}) {
+	ticker := time.NewTicker(drainLogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.logger.Info("HTTP server draining connections", "active_conns", s.ActiveConns())
+		case <-drained:
+			return
+		}
+	}
+}
@@ -0,0 +1,523 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer creates a server with the given config and handlers.
+func newTestServer(cfg *Config, handlers ...*Handler) *Server {
+	svr := New(cfg, log.NewBlankLogger(io.Discard))
+	for _, h := range handlers {
+		if err := svr.RegisterHandler(h); err != nil {
+			panic(err)
+		}
+	}
+	return svr
+}
+
+// startTestServer starts the server on an ephemeral local port, returning its address.
+func startTestServer(t *testing.T, svr *Server) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go svr.StartWithListener(ctx, l)
+
+	return l.Addr().String()
+}
+
+func TestStopBeforeStart(t *testing.T) {
+	svr := newTestServer(&Config{})
+	svr.Stop()
+
+	// The server doesn't start once stopped, releasing its listener.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	started := make(chan error, 1)
+	go func() { started <- svr.StartWithListener(context.Background(), l) }()
+	select {
+	case err = <-started:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "server started after being stopped")
+	}
+	_, err = net.Dial("tcp", l.Addr().String())
+	require.Error(t, err)
+}
+
+func TestStopWaitsForInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	slow := &Handler{Path: "/slow", Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			_, _ = w.Write([]byte("done"))
+		},
+	)}
+	svr := newTestServer(&Config{}, slow)
+	url := "http://" + startTestServer(t, svr)
+
+	type result struct {
+		body string
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow") //nolint:noctx // test request.
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		results <- result{body: string(body), err: err}
+	}()
+
+	// Stop while the slow request is in flight; it still completes.
+	<-started
+	svr.Stop()
+	res := <-results
+	require.NoError(t, res.err)
+	require.Equal(t, "done", res.body)
+
+	// Stopping again is a no-op.
+	svr.Stop()
+}
+
+func TestActiveConns(t *testing.T) {
+	ok := &Handler{Path: "/ok", Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("ok")) },
+	)}
+	svr := newTestServer(&Config{}, ok)
+	url := "http://" + startTestServer(t, svr) + "/ok"
+	require.Zero(t, svr.ActiveConns())
+
+	// Each client keeps its connection alive (idle) after its request.
+	clients := []*http.Client{
+		{Transport: &http.Transport{}}, {Transport: &http.Transport{}},
+	}
+	for _, client := range clients {
+		resp, err := client.Get(url) //nolint:noctx // test request.
+		require.NoError(t, err)
+		_, err = io.Copy(io.Discard, resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	require.Equal(t, 2, svr.ActiveConns())
+
+	// Closing a connection or stopping the server drains the connections.
+	clients[0].CloseIdleConnections()
+	require.Eventually(t, func() bool { return svr.ActiveConns() == 1 },
+		time.Second, 10*time.Millisecond)
+	svr.Stop()
+	require.Eventually(t, func() bool { return svr.ActiveConns() == 0 },
+		time.Second, 10*time.Millisecond)
+}
+
+func TestStopClosesAfterShutdownTimeout(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	defer close(release)
+	stuck := &Handler{Path: "/stuck", Handler: http.HandlerFunc(
+		func(http.ResponseWriter, *http.Request) {
+			close(started)
+			<-release
+		},
+	)}
+	svr := newTestServer(&Config{HTTP: HTTP{ShutdownTimeout: 10 * time.Millisecond}}, stuck)
+	url := "http://" + startTestServer(t, svr)
+
+	errs := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url + "/stuck") //nolint:noctx // test request.
+		if err == nil {
+			resp.Body.Close()
+		}
+		errs <- err
+	}()
+
+	// The stuck request can't finish, so its connection is closed once the timeout elapses.
+	<-started
+	start := time.Now()
+	svr.Stop()
+	require.Less(t, time.Since(start), time.Second)
+	require.Error(t, <-errs)
+}
+
+func TestWriteTimeout(t *testing.T) {
+	handler := func(d time.Duration) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(d)
+			_, _ = w.Write([]byte("done"))
+		}
+	}
+	svr := newTestServer(
+		&Config{HTTP: HTTP{WriteTimeout: 50 * time.Millisecond}},
+		&Handler{Path: "/fast", Handler: handler(0)},
+		&Handler{Path: "/slow", Handler: handler(100 * time.Millisecond)},
+	)
+	url := "http://" + startTestServer(t, svr)
+
+	resp, err := http.Get(url + "/fast") //nolint:noctx // test request.
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	// The slow handler's response is cut off by the write timeout.
+	resp, err = http.Get(url + "/slow") //nolint:noctx // test request.
+	if err == nil {
+		_, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	require.Error(t, err)
+}
+
+func TestMaxHeaderBytes(t *testing.T) {
+	svr := newTestServer(&Config{HTTP: HTTP{MaxHeaderBytes: 1024}}, &Handler{
+		Path: "/", Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+	})
+	url := "http://" + startTestServer(t, svr)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil) //nolint:noctx // test request.
+	require.NoError(t, err)
+	req.Header.Set("X-Large", strings.Repeat("a", 8192))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+}
+
+func TestRegisterHandlerDuplicatePath(t *testing.T) {
+	svr := newTestServer(&Config{})
+	h := &Handler{Path: "/a", Handler: http.NotFoundHandler()}
+
+	require.NoError(t, svr.RegisterHandler(h))
+	require.ErrorIs(t, svr.RegisterHandler(h), ErrDuplicatePath)
+	require.NoError(t, svr.RegisterHandler(&Handler{Path: "/a/", Handler: h.Handler}))
+}
+
+func TestRegisterHandlerWhileRunning(t *testing.T) {
+	svr := newTestServer(&Config{})
+	url := "http://" + startTestServer(t, svr)
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(url + path) //nolint:noctx // test request.
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+	respond := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(body))
+		})
+	}
+
+	// Register routes while requests are being served.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			status, _ := get("/")
+			require.Equal(t, http.StatusNotFound, status)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		require.NoError(t, svr.RegisterHandler(&Handler{
+			Path: fmt.Sprintf("/route%d", i), Handler: respond("v1"),
+		}))
+	}
+	wg.Wait()
+
+	status, body := get("/route0")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "v1", body)
+
+	// A route can be replaced.
+	svr.ReplaceHandler(&Handler{Path: "/route0", Handler: respond("v2")})
+	_, body = get("/route0")
+	require.Equal(t, "v2", body)
+}
+
+func TestUnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "server")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "http.sock")
+
+	// Leave a stale socket file behind, as a crashed process would.
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, l.Close())
+
+	cfg := &Config{HTTP: HTTP{UnixSocket: socket}}
+	require.True(t, cfg.HTTP.Enabled())
+	svr := newTestServer(cfg, &Handler{Path: "/", Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("unix")) },
+	)})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svr.Start(ctx)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	var body []byte
+	require.Eventually(t, func() bool {
+		resp, getErr := client.Get("http://unix/") //nolint:noctx // test request.
+		if getErr != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		body, getErr = io.ReadAll(resp.Body)
+		return getErr == nil
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, "unix", string(body))
+
+	// The socket file is removed once the server stops.
+	svr.Stop()
+	_, err = os.Stat(socket)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestStartWithListener(t *testing.T) {
+	svr := newTestServer(&Config{HTTP: HTTP{Port: 1}}, &Handler{
+		Path: "/", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Host))
+		}),
+	})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svr.StartWithListener(ctx, l)
+
+	// The server is reachable as soon as the listener is, on the listener's address rather than
+	// the configured port.
+	resp, err := http.Get("http://" + l.Addr().String()) //nolint:noctx // test request.
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, l.Addr().String(), string(body))
+}
+
+func TestStartPortInUse(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	port := l.Addr().(*net.TCPAddr).Port
+
+	svr := newTestServer(&Config{HTTP: HTTP{Host: "127.0.0.1", Port: uint64(port)}})
+	err = svr.Start(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "address already in use")
+}
+
+func TestBasePath(t *testing.T) {
+	svr := newTestServer(&Config{HTTP: HTTP{BasePath: "/api/"}}, &Handler{
+		Path: "/foo", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.URL.Path))
+		}),
+	})
+	require.NoError(t, svr.RegisterHealthz("/healthz"))
+	url := "http://" + startTestServer(t, svr)
+
+	// Handlers are served under the base path, but see their original paths.
+	resp, err := http.Get(url + "/api/foo") //nolint:noctx // test request.
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "/foo", string(body))
+
+	resp, err = http.Get(url + "/api/healthz") //nolint:noctx // test request.
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Handlers aren't served outside of the base path.
+	resp, err = http.Get(url + "/foo") //nolint:noctx // test request.
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, event)
+	}
+	numbered := func(n int) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				record(fmt.Sprintf("before %d", n))
+				next.ServeHTTP(w, r)
+				record(fmt.Sprintf("after %d", n))
+			})
+		}
+	}
+	respond := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { record("handler") })
+
+	svr := New(&Config{}, log.NewBlankLogger(io.Discard), numbered(3))
+	require.NoError(t, svr.RegisterHandler(&Handler{Path: "/before", Handler: respond}))
+	svr.RegisterMiddleware(numbered(4))
+	svr.PrependMiddleware(numbered(2))
+	svr.PrependMiddleware(numbered(1))
+	svr.RegisterMiddleware(numbered(5))
+	require.NoError(t, svr.RegisterHandler(&Handler{Path: "/after", Handler: respond}))
+	url := "http://" + startTestServer(t, svr)
+
+	// Handlers registered before and after the middlewares run through all of them, in order.
+	want := []string{
+		"before 1", "before 2", "before 3", "before 4", "before 5",
+		"handler",
+		"after 5", "after 4", "after 3", "after 2", "after 1",
+	}
+	for _, path := range []string{"/before", "/after"} {
+		mu.Lock()
+		order = nil
+		mu.Unlock()
+
+		resp, err := http.Get(url + path) //nolint:noctx // test request.
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		mu.Lock()
+		require.Equal(t, want, order, path)
+		mu.Unlock()
+	}
+}
+
+func TestRegisterHandlerMiddlewares(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, event)
+	}
+	named := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				record(name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	respond := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { record("handler") })
+
+	svr := New(&Config{}, log.NewBlankLogger(io.Discard), named("global"))
+	require.NoError(t, svr.RegisterHandler(
+		&Handler{Path: "/business", Handler: respond}, named("auth"), named("audit"),
+	))
+	require.NoError(t, svr.RegisterHandler(&Handler{Path: "/metrics", Handler: respond}))
+	url := "http://" + startTestServer(t, svr)
+
+	// The route's middlewares run after the global ones, and only for its path.
+	for path, want := range map[string][]string{
+		"/business": {"global", "auth", "audit", "handler"},
+		"/metrics":  {"global", "handler"},
+	} {
+		mu.Lock()
+		order = nil
+		mu.Unlock()
+
+		resp, err := http.Get(url + path) //nolint:noctx // test request.
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		mu.Lock()
+		require.Equal(t, want, order, path)
+		mu.Unlock()
+	}
+}
+
+func TestReload(t *testing.T) {
+	respond := func(d time.Duration) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(d)
+			_, _ = w.Write([]byte(r.URL.Path))
+		}
+	}
+	svr := newTestServer(&Config{},
+		&Handler{Path: "/old", Handler: respond(0)},
+		&Handler{Path: "/slow", Handler: respond(100 * time.Millisecond)},
+	)
+	url := "http://" + startTestServer(t, svr)
+
+	get := func(path string) (*http.Response, string, error) {
+		resp, err := http.Get(url + path) //nolint:noctx // test request.
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return resp, string(body), err
+	}
+
+	resp, body, err := get("/old")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "/old", body)
+	_, _, err = get("/slow")
+	require.NoError(t, err)
+
+	// Reload with a new route and middleware, under a base path and with a write timeout.
+	require.NoError(t, svr.RegisterHandler(&Handler{Path: "/new", Handler: respond(0)}))
+	svr.RegisterMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Reloaded", "true")
+			next.ServeHTTP(w, r)
+		})
+	})
+	svr.Reload(&Config{HTTP: HTTP{BasePath: "/v2", WriteTimeout: 50 * time.Millisecond}})
+
+	// Both the old and new routes are served under the base path, behind the new middleware.
+	for _, path := range []string{"/old", "/new"} {
+		resp, body, err = get("/v2" + path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, path, body)
+		require.Equal(t, "true", resp.Header.Get("X-Reloaded"))
+
+		resp, _, err = get(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	// The slow handler's response is now cut off by the new write timeout.
+	_, _, err = get("/v2/slow")
+	require.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// 15 seconds keeps idle streams alive through most proxies.
+const defaultKeepAliveInterval = 15 * time.Second
+
+// Event is a server-sent event.
+type Event struct {
+	ID    string // optional, sent as the event's id (must be a single line)
+	Event string // optional, the event type (must be a single line), "message" if empty
+	Data  []byte // sent as one data line per line
+}
+
+// EventSource returns the channel of events to stream to a client, which stops the stream once
+// closed. It's called for each client, with the request's context, which is cancelled once the
+// client disconnects; the application should stop sending on the channel then.
+type EventSource func(ctx context.Context, r *http.Request) <-chan Event
+
+// SSEOptions configures a server-sent events endpoint.
+type SSEOptions struct {
+	// Interval between keep-alive comments sent to the client while idle; if 0, defaults to 15s.
+	KeepAliveInterval time.Duration
+}
+
+// RegisterSSE registers a server-sent events endpoint at the given path, which streams the events
+// of the source to each client as they arrive. Requests whose response writer can't be flushed
+// (e.g. behind a buffering middleware) are responded to with 500 Internal Server Error.
+func (s *Server) RegisterSSE(path string, source EventSource, opts SSEOptions) error {
+	if opts.KeepAliveInterval == 0 {
+		opts.KeepAliveInterval = defaultKeepAliveInterval
+	}
+
+	return s.RegisterHandler(&Handler{Path: path, Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if !canFlush(w) {
+				s.logger.Error("SSE streaming is not supported by the response writer", "path", path)
+				http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+				return
+			}
+			rc := http.NewResponseController(w)
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.WriteHeader(http.StatusOK)
+			if err := rc.Flush(); err != nil {
+				return
+			}
+
+			ctx := r.Context()
+			events := source(ctx, r)
+			ticker := time.NewTicker(opts.KeepAliveInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					writeEvent(w, event)
+				case <-ticker.C:
+					_, _ = io.WriteString(w, ": keep-alive\n\n")
+				case <-ctx.Done():
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			}
+		},
+	)})
+}
+
+// writeEvent writes the event in the event stream format.
+func writeEvent(w io.Writer, event Event) {
+	var buf bytes.Buffer
+	if event.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", event.ID)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event.Event)
+	}
+	for _, line := range bytes.Split(event.Data, []byte("\n")) {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteByte('\n')
+	_, _ = w.Write(buf.Bytes())
+}
+
+// canFlush returns whether the response writer, or one it wraps, is a http.Flusher.
+func canFlush(w http.ResponseWriter) bool {
+	for {
+		switch rw := w.(type) {
+		case http.Flusher:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSE(t *testing.T) {
+	disconnected := make(chan struct{})
+	svr := newTestServer(&Config{})
+	source := func(ctx context.Context, _ *http.Request) <-chan Event {
+		events := make(chan Event)
+		go func() {
+			defer close(disconnected)
+			events <- Event{ID: "1", Event: "status", Data: []byte(`{"state":"sent"}`)}
+			events <- Event{Data: []byte("multi\nline")}
+			<-ctx.Done()
+		}()
+		return events
+	}
+	require.NoError(t, svr.RegisterSSE(
+		"/events", source, SSEOptions{KeepAliveInterval: 20 * time.Millisecond},
+	))
+	addr := startTestServer(t, svr)
+
+	resp, err := http.Get("http://" + addr + "/events") //nolint:noctx // test request.
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	// Read the events, then a keep-alive comment while the stream is idle.
+	reader := bufio.NewReader(resp.Body)
+	for _, expected := range []string{
+		"id: 1\n", "event: status\n", `data: {"state":"sent"}` + "\n", "\n",
+		"data: multi\n", "data: line\n", "\n",
+		": keep-alive\n", "\n",
+	} {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, expected, line)
+	}
+
+	// Disconnecting cancels the source's context.
+	require.NoError(t, resp.Body.Close())
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("source was not cancelled after the client disconnected")
+	}
+}
+
+func TestSSEFlushUnsupported(t *testing.T) {
+	// The timeout middleware buffers responses, so they can't be streamed.
+	svr := newTestServer(&Config{})
+	svr.RegisterMiddleware(TimeoutMiddleware(time.Second))
+	require.NoError(t, svr.RegisterSSE("/events", func(context.Context, *http.Request) <-chan Event {
+		t.Fatal("source called without streaming support")
+		return nil
+	}, SSEOptions{}))
+	addr := startTestServer(t, svr)
+
+	resp, err := http.Get("http://" + addr + "/events") //nolint:noctx // test request.
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TimeoutMiddleware returns a middleware that bounds the time handlers take to process a request,
+// e.g. per route (see RegisterHandler). The request's context is cancelled after the timeout, so
+// handlers can observe it and stop. If the handler hasn't returned by then, the request is
+// responded to with 504 Gateway Timeout, and the handler's later writes fail with
+// http.ErrHandlerTimeout.
+//
+// Unlike the server's WriteTimeout, which cuts the connection and leaves the client with a
+// truncated response, the client always gets a complete response, so the timeout should be
+// shorter than the WriteTimeout. Since responses are buffered until the handler returns, the
+// middleware doesn't suit streaming handlers. If the timeout is 0 (or negative), requests are
+// unbounded.
+func TimeoutMiddleware(timeout time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{ctx: ctx, header: make(http.Header)}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if rec := recover(); rec != nil {
+						panicked <- rec
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case rec := <-panicked:
+				// Re-panic in the serving goroutine, e.g. for RecoveryMiddleware.
+				panic(rec)
+			case <-done:
+				if tw.flushTo(w) {
+					return
+				}
+			case <-ctx.Done():
+				tw.mu.Lock()
+				tw.timeOut()
+				tw.mu.Unlock()
+			}
+			http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+		})
+	}
+}
+
+// timeoutWriter is a http.ResponseWriter that buffers the response of a handler until it returns,
+// unless it times out first (i.e. its context is done).
+type timeoutWriter struct {
+	ctx context.Context
+
+	mu       sync.Mutex
+	header   http.Header
+	status   int
+	body     bytes.Buffer
+	timedOut bool
+}
+
+// Header returns the header of the buffered response.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// WriteHeader buffers the status code.
+func (tw *timeoutWriter) WriteHeader(statusCode int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.status == 0 && !tw.checkTimedOut() {
+		tw.status = statusCode
+	}
+}
+
+// Write buffers the data, or fails with http.ErrHandlerTimeout if the handler timed out.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.checkTimedOut() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.body.Write(b)
+}
+
+// checkTimedOut marks the handler as timed out once its context is done, and returns whether it
+// did. Requires tw.mu to be held.
+func (tw *timeoutWriter) checkTimedOut() bool {
+	if !tw.timedOut && tw.ctx.Err() != nil {
+		tw.timeOut()
+	}
+	return tw.timedOut
+}
+
+// timeOut discards the buffered response, so the handler's writes no longer matter. Requires
+// tw.mu to be held.
+func (tw *timeoutWriter) timeOut() {
+	tw.timedOut = true
+	tw.body.Reset()
+}
+
+// flushTo writes the buffered response of the handler, which has returned, unless it timed out.
+// Returns whether it was written.
+func (tw *timeoutWriter) flushTo(w http.ResponseWriter) bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.checkTimedOut() {
+		return false
+	}
+	for key, values := range tw.header {
+		w.Header()[key] = values
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	w.WriteHeader(tw.status)
+	_, _ = w.Write(tw.body.Bytes())
+	return true
+}
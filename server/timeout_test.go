@@ -0,0 +1,62 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	handlerErr := make(chan error, 1)
+	slow := &Handler{Path: "/slow", Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			<-r.Context().Done()
+			_, err := w.Write([]byte("rest"))
+			handlerErr <- err
+		},
+	)}
+	fast := &Handler{Path: "/fast", Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Test", "fast")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("done"))
+		},
+	)}
+	svr := newTestServer(&Config{HTTP: HTTP{WriteTimeout: time.Second}})
+	require.NoError(t, svr.RegisterHandler(slow, TimeoutMiddleware(50*time.Millisecond)))
+	require.NoError(t, svr.RegisterHandler(fast, TimeoutMiddleware(time.Second)))
+	addr := startTestServer(t, svr)
+
+	// The slow handler times out, so the client gets a complete 504 instead of a partial body.
+	resp, err := http.Get("http://" + addr + "/slow") //nolint:noctx // test request.
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	require.Equal(t, http.StatusText(http.StatusGatewayTimeout)+"\n", string(body))
+	require.ErrorIs(t, <-handlerErr, http.ErrHandlerTimeout)
+
+	// A handler within its timeout responds as usual.
+	resp, err = http.Get("http://" + addr + "/fast") //nolint:noctx // test request.
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, "fast", resp.Header.Get("X-Test"))
+	require.Equal(t, "done", string(body))
+}
+
+func TestTimeoutMiddlewarePanic(t *testing.T) {
+	handler := TimeoutMiddleware(time.Second)(http.HandlerFunc(
+		func(http.ResponseWriter, *http.Request) { panic("boom") },
+	))
+	require.PanicsWithValue(t, "boom", func() {
+		handler.ServeHTTP(nil, &http.Request{})
+	})
+}
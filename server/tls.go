@@ -0,0 +1,81 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsConfig returns the TLS config to serve with, or nil if TLS is not enabled. If cert and key
+// files are configured, the certificate is reloaded whenever either file changes.
+func (t TLS) tlsConfig() (*tls.Config, error) {
+	if !t.Enabled() {
+		return nil, nil //nolint:nilnil // nil means TLS is disabled.
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t.Config != nil {
+		cfg = t.Config.Clone()
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, errors.New("both a TLS cert file and key file are required")
+		}
+		reloader := &certReloader{certFile: t.CertFile, keyFile: t.KeyFile}
+		if _, err := reloader.GetCertificate(nil); err != nil {
+			return nil, err
+		}
+		cfg.GetCertificate = reloader.GetCertificate
+	}
+
+	return cfg, nil
+}
+
+// certReloader loads a TLS certificate from files, reloading it when either file is modified.
+type certReloader struct {
+	certFile, keyFile string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modTimes [2]time.Time
+}
+
+// GetCertificate returns the current certificate, for use as tls.Config.GetCertificate. If the
+// files were modified but can't be loaded (e.g. mid-rotation), the previous certificate is kept.
+func (cr *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	modTimes, err := cr.modTimesOf()
+	if err == nil && modTimes == cr.modTimes {
+		return cr.cert, nil
+	}
+	if err == nil {
+		var cert tls.Certificate
+		if cert, err = tls.LoadX509KeyPair(cr.certFile, cr.keyFile); err == nil {
+			cr.cert, cr.modTimes = &cert, modTimes
+			return cr.cert, nil
+		}
+	}
+
+	if cr.cert == nil {
+		return nil, err
+	}
+	return cr.cert, nil
+}
+
+// modTimesOf returns the modification times of the cert and key files.
+func (cr *certReloader) modTimesOf() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+	for i, file := range []string{cr.certFile, cr.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newSelfSignedCert returns a PEM encoded self-signed certificate for 127.0.0.1 and its key.
+func newSelfSignedCert(t *testing.T, serial int64) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "offchain-sdk test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// handshake performs a TLS handshake with the server, trusting any certificate, and returns the
+// serial number of the server's certificate.
+func handshake(t *testing.T, addr string) int64 {
+	t.Helper()
+
+	//nolint:gosec // the test certificates are self-signed.
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestTLSInjectedConfig(t *testing.T) {
+	certPEM, keyPEM := newSelfSignedCert(t, 1)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	svr := newTestServer(&Config{HTTP: HTTP{TLS: TLS{
+		Config: &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+	}}})
+	addr := startTestServer(t, svr)
+
+	// The handshake verifies the server's self-signed certificate.
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(certPEM))
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestTLSCertFilesReload(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+	)
+	writeCert := func(serial int64, modTime time.Time) {
+		certPEM, keyPEM := newSelfSignedCert(t, serial)
+		require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+		require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+		require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+		require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	}
+	writeCert(1, time.Now().Add(-time.Minute))
+
+	svr := newTestServer(&Config{HTTP: HTTP{TLS: TLS{CertFile: certFile, KeyFile: keyFile}}})
+	addr := startTestServer(t, svr)
+	require.Equal(t, int64(1), handshake(t, addr))
+
+	// A rotated certificate is served without restarting the server.
+	writeCert(2, time.Now())
+	require.Equal(t, int64(2), handshake(t, addr))
+
+	// A broken certificate keeps the previous one.
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	require.Equal(t, int64(2), handshake(t, addr))
+}
+
+func TestTLSConfigRequiresCertAndKey(t *testing.T) {
+	_, err := TLS{CertFile: "cert.pem"}.tlsConfig()
+	require.Error(t, err)
+
+	cfg, err := TLS{}.tlsConfig()
+	require.NoError(t, err)
+	require.Nil(t, cfg)
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// 30 seconds keeps connections alive through most proxies.
+	defaultPingInterval = 30 * time.Second
+	// 10 seconds is a stable default.
+	defaultWriteTimeout = 10 * time.Second
+	// 16 messages gives the application some slack before sends block.
+	defaultSendBufferSize = 16
+)
+
+// ErrWebSocketClosed is returned when sending on a closed WebSocket connection.
+var ErrWebSocketClosed = errors.New("websocket connection is closed")
+
+// WebSocketHandler handles a WebSocket connection. The connection is closed (dropping any unsent
+// messages) once the handler returns. The context is cancelled once the connection is closed.
+type WebSocketHandler func(ctx context.Context, conn *WebSocketConn)
+
+// WebSocketOptions configures a WebSocket endpoint.
+type WebSocketOptions struct {
+	// Interval between pings sent to the client; if 0, defaults to 30s. The connection is closed
+	// if the client doesn't respond with a pong (or any message) within 2 intervals.
+	PingInterval time.Duration
+	// Timeout for writing a message to the client; if 0, defaults to 10s.
+	WriteTimeout time.Duration
+	// Number of messages that may be buffered for sending; if 0, defaults to 16.
+	SendBufferSize int
+	// Returns true if the request's origin is allowed; if nil, only same origin requests are
+	// allowed.
+	CheckOrigin func(*http.Request) bool
+}
+
+// RegisterWebSocket registers a WebSocket endpoint at the given path, which upgrades requests to
+// WebSocket connections and passes them to the handler. Since the endpoint is a regular handler,
+// the middlewares (e.g. for auth) are applied before the upgrade.
+func (s *Server) RegisterWebSocket(
+	path string, handler WebSocketHandler, opts WebSocketOptions,
+) error {
+	if opts.PingInterval == 0 {
+		opts.PingInterval = defaultPingInterval
+	}
+	if opts.WriteTimeout == 0 {
+		opts.WriteTimeout = defaultWriteTimeout
+	}
+	if opts.SendBufferSize == 0 {
+		opts.SendBufferSize = defaultSendBufferSize
+	}
+	upgrader := &websocket.Upgrader{CheckOrigin: opts.CheckOrigin}
+
+	return s.RegisterHandler(&Handler{Path: path, Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			// On failure, the upgrader responds with the error.
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				s.logger.Debug("WebSocket upgrade failed", "path", path, "err", err)
+				return
+			}
+
+			wsc := newWebSocketConn(conn, opts)
+			defer wsc.Close()
+			go wsc.readLoop()
+			go wsc.writeLoop()
+
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			go func() {
+				select {
+				case <-wsc.done:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			handler(ctx, wsc)
+		},
+	)})
+}
+
+// WebSocketConn is a WebSocket connection, which sends and receives text messages.
+type WebSocketConn struct {
+	conn *websocket.Conn
+	opts WebSocketOptions
+
+	send      chan []byte
+	receive   chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newWebSocketConn(conn *websocket.Conn, opts WebSocketOptions) *WebSocketConn {
+	return &WebSocketConn{
+		conn:    conn,
+		opts:    opts,
+		send:    make(chan []byte, opts.SendBufferSize),
+		receive: make(chan []byte),
+		done:    make(chan struct{}),
+	}
+}
+
+// Send queues the message to be sent to the client, blocking while the send buffer is full.
+// Returns ErrWebSocketClosed if the connection is closed.
+func (c *WebSocketConn) Send(msg []byte) error {
+	select {
+	case <-c.done:
+		return ErrWebSocketClosed
+	default:
+	}
+
+	select {
+	case c.send <- msg:
+		return nil
+	case <-c.done:
+		return ErrWebSocketClosed
+	}
+}
+
+// Receive returns the channel of messages received from the client, which is closed once the
+// connection is closed.
+func (c *WebSocketConn) Receive() <-chan []byte {
+	return c.receive
+}
+
+// Done returns a channel that is closed once the connection is closed.
+func (c *WebSocketConn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close closes the connection, notifying the client if possible. It is safe to call multiple
+// times.
+func (c *WebSocketConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(c.opts.WriteTimeout),
+		)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// readLoop reads messages from the client until the connection is closed or the client stops
+// responding to pings.
+func (c *WebSocketConn) readLoop() {
+	defer close(c.receive)
+	defer c.Close()
+
+	pongWait := 2 * c.opts.PingInterval //nolint:gomnd // allows a missed pong.
+	extendDeadline := func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	}
+	_ = extendDeadline("")
+	c.conn.SetPongHandler(extendDeadline)
+
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = extendDeadline("")
+
+		select {
+		case c.receive <- msg:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// writeLoop writes the queued messages and periodic pings to the client until the connection is
+// closed.
+func (c *WebSocketConn) writeLoop() {
+	defer c.Close()
+
+	ticker := time.NewTicker(c.opts.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(c.opts.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			deadline := time.Now().Add(c.opts.WriteTimeout)
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocket(t *testing.T) {
+	closed := make(chan struct{})
+	svr := newTestServer(&Config{})
+	svr.RegisterMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "secret" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	require.NoError(t, svr.RegisterWebSocket("/echo", func(ctx context.Context, conn *WebSocketConn) {
+		defer close(closed)
+		for msg := range conn.Receive() {
+			require.NoError(t, conn.Send(append([]byte("echo: "), msg...)))
+		}
+		<-ctx.Done()
+		require.ErrorIs(t, conn.Send([]byte("late")), ErrWebSocketClosed)
+	}, WebSocketOptions{PingInterval: 20 * time.Millisecond}))
+	url := "ws://" + startTestServer(t, svr) + "/echo"
+
+	// The middlewares apply before the upgrade.
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	conn, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"secret"}})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The client's default ping handler responds to the pings with pongs while the client
+	// reads, which keeps the connection alive while idle.
+	var pings int
+	conn.SetPingHandler(func(data string) error {
+		pings++
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, _, err = conn.ReadMessage()
+	require.Error(t, err) // the read deadline, the server didn't close the connection
+	require.Greater(t, pings, 1)
+
+	// A connection that hit a read deadline can't be used again, so reconnect to echo.
+	conn.Close()
+	<-closed
+	closed = make(chan struct{})
+	conn, resp, err = websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"secret"}})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hi")))
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, "echo: hi", string(msg))
+
+	// Closing the client ends the server's handler.
+	require.NoError(t, conn.Close())
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("handler did not return after the client closed")
+	}
+}
+
+func TestWebSocketPongTimeout(t *testing.T) {
+	closed := make(chan struct{})
+	svr := newTestServer(&Config{})
+	require.NoError(t, svr.RegisterWebSocket("/ws", func(_ context.Context, conn *WebSocketConn) {
+		<-conn.Done()
+		close(closed)
+	}, WebSocketOptions{PingInterval: 10 * time.Millisecond}))
+	url := "ws://" + startTestServer(t, svr) + "/ws"
+
+	// The client never reads, so never responds to pings, and the server closes the connection.
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	defer conn.Close()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("connection was not closed after missing pongs")
+	}
+}
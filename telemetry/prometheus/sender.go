@@ -0,0 +1,103 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/berachain/offchain-sdk/core/transactor/sender"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// labelName is the name of the Prometheus label of each series with the label of the sends (see
+// sender.WithLabel), which is empty for unlabeled sends.
+const labelName = "label"
+
+// DefaultSendDurationBuckets are the default buckets (in seconds) of the send duration histogram,
+// which covers sends that are retried for up to a minute.
+var DefaultSendDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+var _ sender.LabeledMetrics = (*SenderMetrics)(nil)
+
+// SenderMetrics implements the transactor Sender's Metrics hooks with Prometheus counters and
+// histograms, which are partitioned by the label of the sends.
+type SenderMetrics struct {
+	sends        *prometheus.CounterVec
+	retries      *prometheus.CounterVec
+	replacements *prometheus.CounterVec
+	sendDuration *prometheus.HistogramVec
+}
+
+// NewSenderMetrics creates the Sender's metrics with the given (optional) namespace, using the
+// default send duration buckets, and registers them on the registerer.
+func NewSenderMetrics(reg prometheus.Registerer, namespace string) (*SenderMetrics, error) {
+	sm := &SenderMetrics{
+		sends: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sends_total",
+			Help:      "Number of txs sent (including their retries), successfully or not.",
+		}, []string{labelName}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Number of retries of txs, by the reason for retrying.",
+		}, []string{labelName, "reason"}),
+		replacements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "replacements_total",
+			Help:      "Number of txs replaced with a different gas price or nonce.",
+		}, []string{labelName}),
+		sendDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "send_duration_seconds",
+			Help:      "Total time taken to send a tx, including its retries.",
+			Buckets:   DefaultSendDurationBuckets,
+		}, []string{labelName}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		sm.sends, sm.retries, sm.replacements, sm.sendDuration,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return sm, nil
+}
+
+// IncRetry counts a retry of an unlabeled tx with the given reason.
+func (sm *SenderMetrics) IncRetry(reason string) {
+	sm.ForLabel("").IncRetry(reason)
+}
+
+// IncReplacement counts a replacement of an unlabeled tx.
+func (sm *SenderMetrics) IncReplacement() {
+	sm.ForLabel("").IncReplacement()
+}
+
+// ObserveSendLatency counts a send of an unlabeled tx and observes its total duration.
+func (sm *SenderMetrics) ObserveSendLatency(d time.Duration) {
+	sm.ForLabel("").ObserveSendLatency(d)
+}
+
+// ForLabel returns the metrics of the sends with the given label.
+func (sm *SenderMetrics) ForLabel(label string) sender.Metrics {
+	return &labeledSenderMetrics{sm: sm, label: label}
+}
+
+// labeledSenderMetrics reports to the series of the SenderMetrics with a label.
+type labeledSenderMetrics struct {
+	sm    *SenderMetrics
+	label string
+}
+
+func (lm *labeledSenderMetrics) IncRetry(reason string) {
+	lm.sm.retries.WithLabelValues(lm.label, reason).Inc()
+}
+
+func (lm *labeledSenderMetrics) IncReplacement() {
+	lm.sm.replacements.WithLabelValues(lm.label).Inc()
+}
+
+func (lm *labeledSenderMetrics) ObserveSendLatency(d time.Duration) {
+	lm.sm.sends.WithLabelValues(lm.label).Inc()
+	lm.sm.sendDuration.WithLabelValues(lm.label).Observe(d.Seconds())
+}
@@ -0,0 +1,109 @@
+package prometheus_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/berachain/offchain-sdk/client/eth/ethmock"
+	"github.com/berachain/offchain-sdk/core/transactor/sender"
+	"github.com/berachain/offchain-sdk/log"
+	"github.com/berachain/offchain-sdk/telemetry/prometheus"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+var _ sender.Metrics = (*prometheus.SenderMetrics)(nil)
+
+// factory rebuilds txs directly from the call msg, without signing.
+type factory struct{}
+
+func (factory) RebuildTransactionFromRequest(
+	_ context.Context, msg *ethereum.CallMsg, nonce uint64,
+) (*coretypes.Transaction, error) {
+	return coretypes.NewTx(&coretypes.DynamicFeeTx{
+		ChainID: big.NewInt(1), Nonce: nonce, GasTipCap: msg.GasTipCap,
+		GasFeeCap: msg.GasFeeCap, Gas: msg.Gas, To: msg.To, Value: msg.Value, Data: msg.Data,
+	}), nil
+}
+
+func (factory) SignTransaction(
+	_ context.Context, _ common.Address, tx *coretypes.Transaction,
+) (*coretypes.Transaction, error) {
+	return tx, nil
+}
+
+func (factory) EstimateGas(context.Context, *ethereum.CallMsg) (uint64, error) {
+	return 0, nil
+}
+
+// noncer never acquires a fresh nonce.
+type noncer struct{}
+
+func (noncer) Acquire(common.Address) (uint64, error) { return 0, nil }
+
+func TestSenderMetrics(t *testing.T) {
+	reg := promclient.NewRegistry()
+	metrics, err := prometheus.NewSenderMetrics(reg, "txr")
+	require.NoError(t, err)
+
+	// Send a tx whose first attempt is underpriced, so it is replaced and retried.
+	s, err := sender.NewFromConfig(factory{}, noncer{}, sender.Config{
+		RetryPolicy: sender.RetryPolicyLinear, BaseBackoff: time.Millisecond,
+	}, sender.WithMetrics(metrics))
+	require.NoError(t, err)
+	client := ethmock.New()
+	client.QueueError(ethmock.MethodSendTransaction, txpool.ErrReplaceUnderpriced)
+	s.Setup(client, log.Noop())
+
+	tx := coretypes.NewTx(&coretypes.DynamicFeeTx{
+		ChainID: big.NewInt(1), GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Gas: 21000,
+	})
+	_, err = s.SendTransaction(context.Background(), tx, []string{"msg"})
+	require.NoError(t, err)
+
+	// Send another tx with a label, whose series are separate.
+	tx = coretypes.NewTx(&coretypes.DynamicFeeTx{
+		ChainID: big.NewInt(1), Nonce: 1, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9),
+		Gas: 21000,
+	})
+	_, err = s.SendTransaction(sender.WithLabel(context.Background(), "job"), tx, nil)
+	require.NoError(t, err)
+
+	// Scrape the registry and check the series.
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	series := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			name := family.GetName()
+			for _, label := range metric.GetLabel() {
+				name += "{" + label.GetName() + "=" + label.GetValue() + "}"
+			}
+			switch {
+			case metric.GetCounter() != nil:
+				series[name] = metric.GetCounter().GetValue()
+			case metric.GetHistogram() != nil:
+				series[name] = float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	require.Equal(t, map[string]float64{
+		"txr_sends_total{label=}":                               1,
+		"txr_retries_total{label=}{reason=replace_underpriced}": 1,
+		"txr_replacements_total{label=}":                        1,
+		"txr_send_duration_seconds{label=}":                     1,
+		"txr_sends_total{label=job}":                            1,
+		"txr_send_duration_seconds{label=job}":                  1,
+	}, series)
+
+	// The metrics can only be registered once on a registry.
+	_, err = prometheus.NewSenderMetrics(reg, "txr")
+	require.Error(t, err)
+}
@@ -0,0 +1,11 @@
+package local
+
+import "errors"
+
+var (
+	// ErrKeyNotSet is returned when the environment variable of a private key is not set.
+	ErrKeyNotSet = errors.New("private key environment variable is not set")
+	// ErrAddressMismatch is returned when a loaded private key does not derive the expected
+	// address.
+	ErrAddressMismatch = errors.New("private key does not match the expected address")
+)
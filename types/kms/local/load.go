@@ -0,0 +1,99 @@
+package local
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The loaders below verify that the loaded key derives the expected address, unless it is the
+// zero address. The returned Signer can be used by the transactor's Factory through
+// factory.NewTxSigner.
+
+// NewSignerFromKeystore creates a new signer with the private key of the keystore (V3) JSON file
+// at the given path, decrypted with the passphrase.
+func NewSignerFromKeystore(path, passphrase string, expected common.Address) (*Signer, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerFromEncryptedJSON(keyJSON, passphrase, expected)
+}
+
+// NewSignerFromEncryptedJSON creates a new signer with the private key of the keystore (V3) JSON,
+// decrypted with the passphrase. The key must also derive the address recorded in the JSON, if
+// any.
+func NewSignerFromEncryptedJSON(
+	keyJSON []byte, passphrase string, expected common.Address,
+) (*Signer, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded struct {
+		Address string `json:"address"`
+	}
+	if err = json.Unmarshal(keyJSON, &recorded); err != nil {
+		return nil, err
+	}
+	if recorded.Address != "" {
+		if err = checkAddress(key.Address, common.HexToAddress(recorded.Address)); err != nil {
+			return nil, err
+		}
+	}
+
+	signer := NewSigner(key.PrivateKey)
+	if err = checkAddress(signer.Address(), expected); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// NewSignerFromEnv creates a new signer with the hex-encoded (optionally 0x-prefixed) private key
+// in the given environment variable.
+func NewSignerFromEnv(name string, expected common.Address) (*Signer, error) {
+	hexKey, ok := os.LookupEnv(name)
+	if !ok || hexKey == "" {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotSet, name)
+	}
+
+	// Decode the key into a buffer that can be wiped (unlike the string).
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	defer wipe(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key in %s: %w", name, err)
+	}
+	key, err := crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key in %s: %w", name, err)
+	}
+
+	signer := NewSigner(key)
+	if err = checkAddress(signer.Address(), expected); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// checkAddress returns ErrAddressMismatch if the address is not the expected one (unless it is
+// the zero address).
+func checkAddress(address, expected common.Address) error {
+	if expected == (common.Address{}) || address == expected {
+		return nil
+	}
+	return fmt.Errorf("%w: got %s, expected %s", ErrAddressMismatch, address, expected)
+}
+
+// wipe zeroes the key material in the buffer.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
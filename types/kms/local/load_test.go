@@ -0,0 +1,63 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// testHexKey is the private key of the keystore fixture, which is encrypted with testPassphrase.
+	testHexKey     = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testPassphrase = "testpassword"
+	testKeystore   = "testdata/keystore.json"
+)
+
+var testAddress = common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23")
+
+func TestNewSignerFromKeystore(t *testing.T) {
+	signer, err := NewSignerFromKeystore(testKeystore, testPassphrase, testAddress)
+	require.NoError(t, err)
+	require.Equal(t, testAddress, signer.Address())
+
+	// The expected address is optional.
+	signer, err = NewSignerFromKeystore(testKeystore, testPassphrase, common.Address{})
+	require.NoError(t, err)
+	require.Equal(t, testAddress, signer.Address())
+
+	_, err = NewSignerFromKeystore(testKeystore, "wrong", testAddress)
+	require.Error(t, err)
+	_, err = NewSignerFromKeystore(testKeystore, testPassphrase, common.HexToAddress("0x1"))
+	require.ErrorIs(t, err, ErrAddressMismatch)
+	_, err = NewSignerFromKeystore("testdata/missing.json", testPassphrase, testAddress)
+	require.Error(t, err)
+}
+
+func TestNewSignerFromEnv(t *testing.T) {
+	const name = "TEST_SIGNER_KEY"
+
+	_, err := NewSignerFromEnv(name, testAddress)
+	require.ErrorIs(t, err, ErrKeyNotSet)
+
+	var signer *Signer
+	for _, hexKey := range []string{testHexKey, "0x" + testHexKey, " 0x" + testHexKey + "\n"} {
+		t.Setenv(name, hexKey)
+		signer, err = NewSignerFromEnv(name, testAddress)
+		require.NoError(t, err)
+		require.Equal(t, testAddress, signer.Address())
+	}
+
+	_, err = NewSignerFromEnv(name, common.HexToAddress("0x1"))
+	require.ErrorIs(t, err, ErrAddressMismatch)
+	t.Setenv(name, "0xnothex")
+	_, err = NewSignerFromEnv(name, testAddress)
+	require.Error(t, err)
+}
+
+func TestWipe(t *testing.T) {
+	b := []byte{1, 2, 3}
+	wipe(b)
+	require.Equal(t, []byte{0, 0, 0}, b)
+}
@@ -0,0 +1,48 @@
+package local
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	kmstypes "github.com/berachain/offchain-sdk/types/kms/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Ensure `Signer` implements the Signer interface.
+var _ kmstypes.Signer = (*Signer)(nil)
+
+// Signer signs transactions with a private key held in memory.
+type Signer struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewSigner creates a new signer with the given private key.
+func NewSigner(key *ecdsa.PrivateKey) *Signer {
+	return &Signer{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// NewSignerFromHex creates a new signer with the given hex-encoded private key.
+func NewSignerFromHex(hexKey string) (*Signer, error) {
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigner(key), nil
+}
+
+// Address returns the Ethereum address of the signer's private key.
+func (s *Signer) Address() common.Address {
+	return s.address
+}
+
+// SignTx signs the hash of the transaction for the chain ID with the private key.
+func (s *Signer) SignTx(
+	_ context.Context, tx *types.Transaction, chainID *big.Int,
+) ([]byte, error) {
+	return crypto.Sign(types.LatestSignerForChainID(chainID).Hash(tx).Bytes(), s.key)
+}
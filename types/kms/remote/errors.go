@@ -0,0 +1,7 @@
+package remote
+
+import "errors"
+
+// ErrSignatureMismatch is returned when the signature returned by the remote signer does not
+// recover to the signer's address.
+var ErrSignatureMismatch = errors.New("remote signature does not match the signer address")
@@ -0,0 +1,163 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+
+	kmstypes "github.com/berachain/offchain-sdk/types/kms/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// maxResponseBytes caps the size of a response read from the remote signer.
+const maxResponseBytes = 1 << 20
+
+// Ensure `Signer` implements the Signer interface.
+var _ kmstypes.Signer = (*Signer)(nil)
+
+// SignRequest is the JSON body POSTed to the remote signer for each transaction.
+type SignRequest struct {
+	// Address is the address of the key to sign with.
+	Address common.Address `json:"address"`
+	// ChainID is the chain ID the transaction is signed for.
+	ChainID *hexutil.Big `json:"chainId"`
+	// Hash is the hash of the transaction to sign, i.e. the signing hash for the chain ID.
+	Hash common.Hash `json:"hash"`
+	// Transaction is the binary encoding of the unsigned transaction, so that the remote signer
+	// can apply its own policies before signing.
+	Transaction hexutil.Bytes `json:"transaction"`
+}
+
+// SignResponse is the JSON body that the remote signer responds with.
+type SignResponse struct {
+	// Signature is the 65-byte [R || S || V] signature of the hash, with V as 0/1 or 27/28.
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Signer signs transactions through an external signing service (e.g. fronting a KMS or HSM)
+// over HTTP, so that the private key never leaves the service.
+type Signer struct {
+	url     string
+	address common.Address
+	client  *http.Client
+	headers http.Header
+}
+
+// Option is a functional option for configuring a Signer.
+type Option func(*Signer)
+
+// WithHTTPClient sets the HTTP client used to call the remote signer.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Signer) {
+		s.client = client
+	}
+}
+
+// WithHeader sets a header sent with each request to the remote signer, e.g. for auth.
+func WithHeader(key, value string) Option {
+	return func(s *Signer) {
+		s.headers.Set(key, value)
+	}
+}
+
+// NewSigner creates a new signer that signs with the key of the given address through the remote
+// signer at the given URL.
+func NewSigner(url string, address common.Address, opts ...Option) *Signer {
+	s := &Signer{
+		url: url, address: address, client: http.DefaultClient, headers: make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Address returns the Ethereum address of the remote key.
+func (s *Signer) Address() common.Address {
+	return s.address
+}
+
+// SignTx requests the signature of the transaction for the chain ID from the remote signer. The
+// signature is verified to recover to the signer's address.
+func (s *Signer) SignTx(
+	ctx context.Context, tx *types.Transaction, chainID *big.Int,
+) ([]byte, error) {
+	txBytes, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	hash := types.LatestSignerForChainID(chainID).Hash(tx)
+	body, err := json.Marshal(&SignRequest{
+		Address: s.address, ChainID: (*hexutil.Big)(chainID), Hash: hash, Transaction: txBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return s.verify(hash, resp.Signature)
+}
+
+// post sends the request body to the remote signer and decodes its response.
+func (s *Signer) post(ctx context.Context, body []byte) (*SignResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = s.headers.Clone()
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"remote signer responded with status %d: %s", httpResp.StatusCode, respBody,
+		)
+	}
+
+	resp := new(SignResponse)
+	if err = json.Unmarshal(respBody, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// verify normalizes the signature's V to 0 or 1 and checks that it recovers to the signer's
+// address.
+func (s *Signer) verify(hash common.Hash, signature []byte) ([]byte, error) {
+	if len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid remote signature length %d", len(signature))
+	}
+	signature = bytes.Clone(signature)
+	if v := &signature[crypto.RecoveryIDOffset]; *v >= 27 { //nolint:gomnd // legacy V offset.
+		*v -= 27
+	}
+
+	pubkey, err := crypto.SigToPub(hash.Bytes(), signature)
+	if err != nil {
+		return nil, err
+	}
+	if crypto.PubkeyToAddress(*pubkey) != s.address {
+		return nil, ErrSignatureMismatch
+	}
+	return signature, nil
+}
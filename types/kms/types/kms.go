@@ -6,6 +6,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
 )
 
 // KeyManagementSystem is an interface that combines the KeyGenerator and KeyViewer interfaces.
@@ -32,3 +33,14 @@ type TxSigner interface {
 	// SignerFunc returns a function that can be used to sign a transaction generated from a bind.
 	SignerFunc(context.Context, *big.Int) (bind.SignerFn, error)
 }
+
+// Signer is an interface that defines the signing step of a transaction, e.g. with a local key or
+// through an external KMS/HSM. Unlike TxSigner, a Signer only produces the signature, which is
+// applied to the transaction by the caller.
+type Signer interface {
+	// Address returns the Ethereum address associated with the signer.
+	Address() common.Address
+	// SignTx returns the 65-byte [R || S || V] signature, with V as 0 or 1, of the given unsigned
+	// transaction for the given chain ID.
+	SignTx(ctx context.Context, tx *coretypes.Transaction, chainID *big.Int) ([]byte, error)
+}